@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/audit"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/auth"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/config"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/userimport"
+)
+
+// runImport implements "keycloak-mcp import-users [flags] FILE": it imports
+// the users in FILE ("-" for stdin) with the server's Keycloak settings and
+// prints the report as JSON. Like the import_users tool it is refused in
+// read-only mode, and every user it creates or fails to create is recorded in
+// the audit log. It returns the process exit code: 0 if every row was created
+// or skipped, 1 otherwise, 2 for usage errors and in read-only mode.
+func runImport(cfg *config.Config, args []string) int {
+	fs := flag.NewFlagSet("import-users", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: keycloak-mcp import-users [flags] FILE")
+		fs.PrintDefaults()
+	}
+	realm := fs.String("realm", "", "realm to import into (default KEYCLOAK_DEFAULT_REALM)")
+	format := fs.String("format", "", "csv or json (default from the file extension)")
+	dryRun := fs.Bool("dry-run", false, "only validate the rows")
+	concurrency := fs.Int("concurrency", userimport.DefaultConcurrency, "rows imported at once")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	if cfg.ReadOnly {
+		fmt.Fprintln(os.Stderr, "import-users: KEYCLOAK_READ_ONLY is set; refusing to modify Keycloak")
+		return 2
+	}
+	auditLog, err := audit.New(cfg.AuditLog)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open audit log %s: %v\n", cfg.AuditLog, err)
+		return 1
+	}
+
+	path := fs.Arg(0)
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		defer f.Close()
+		in = f
+	}
+	if *format == "" {
+		*format = userimport.FormatOf(path)
+	}
+	rows, err := userimport.Parse(in, *format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 2
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	kc := keycloak.NewClient(cfg, auth.NewTokenManager(cfg))
+	report, err := userimport.Import(ctx, kc, rows, userimport.Options{
+		Realm:       *realm,
+		DryRun:      *dryRun,
+		Concurrency: *concurrency,
+		Progress: func(done, total int) {
+			fmt.Fprintf(os.Stderr, "\r%d/%d", done, total)
+		},
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import failed: %v\n", err)
+		return 1
+	}
+	auditImport(auditLog, rows, report)
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(report)
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
+
+// auditImport records one audit entry per user the import tried to create.
+// Skipped, invalid and cancelled rows changed nothing and are not recorded.
+func auditImport(auditLog *audit.Logger, rows []userimport.Row, report *userimport.Report) {
+	for _, r := range report.Rows {
+		if r.Status != userimport.StatusCreated && r.Status != userimport.StatusFailed {
+			continue
+		}
+		var args map[string]any
+		b, _ := json.Marshal(rows[r.Row-1])
+		_ = json.Unmarshal(b, &args)
+		args["row"] = r.Row
+		auditLog.Record(audit.Entry{Tool: "import-users", Realm: report.Realm, Arguments: args, Error: r.Error})
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloaktest"
+)
+
+// quiet discards what runImport prints for the duration of the test.
+func quiet(t *testing.T) {
+	t.Helper()
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = devNull, devNull
+	t.Cleanup(func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		devNull.Close()
+	})
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImportUsersAudited(t *testing.T) {
+	quiet(t)
+	fake := keycloaktest.NewServer()
+	defer fake.Close()
+	fake.AddUser("acme", "alice", "alice@example.com")
+	cfg := fake.Config()
+	cfg.AuditLog = filepath.Join(t.TempDir(), "audit.log")
+
+	csv := writeFile(t, "users.csv", "username,email,temporary_password\nalice,alice@example.com,\nbob,bob@example.com,Secret-123\n")
+	if code := runImport(cfg, []string{csv}); code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+
+	f, err := os.Open(cfg.AuditLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []map[string]any
+	for sc := bufio.NewScanner(f); sc.Scan(); {
+		var e map[string]any
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+	// alice already exists and is skipped, so only bob is a write.
+	if len(entries) != 1 {
+		t.Fatalf("audit entries = %v", entries)
+	}
+	e := entries[0]
+	args, _ := e["arguments"].(map[string]any)
+	if e["tool"] != "import-users" || e["realm"] != "acme" || e["outcome"] != "success" ||
+		args["username"] != "bob" || args["temporary_password"] != "[REDACTED]" {
+		t.Fatalf("audit entry = %v", e)
+	}
+}
+
+func TestImportUsersReadOnly(t *testing.T) {
+	quiet(t)
+	fake := keycloaktest.NewServer()
+	defer fake.Close()
+	cfg := fake.Config()
+	cfg.ReadOnly = true
+
+	csv := writeFile(t, "users.csv", "username\nbob\n")
+	if code := runImport(cfg, []string{csv}); code != 2 {
+		t.Fatalf("exit code = %d, want 2", code)
+	}
+	if code := runImport(cfg, []string{"-dry-run", csv}); code != 2 {
+		t.Fatalf("dry run exit code = %d, want 2", code)
+	}
+}
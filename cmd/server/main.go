@@ -3,18 +3,22 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/audit"
 	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/auth"
 	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/config"
 	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/mcplog"
 	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/tools"
 )
 
@@ -22,7 +26,11 @@ var version = "dev"
 
 func main() {
 	cfg := config.Load()
-	initLogger(cfg)
+	stderr := initLogger(cfg)
+
+	if len(os.Args) > 1 && os.Args[1] == "import-users" {
+		os.Exit(runImport(cfg, os.Args[2:]))
+	}
 
 	log.Info().
 		Str("transport", cfg.Transport).
@@ -37,10 +45,29 @@ func main() {
 	// MCP server
 	s := mcp.NewServer(
 		&mcp.Implementation{Name: "keycloak-mcp", Version: version},
-		nil,
+		&mcp.ServerOptions{CompletionHandler: tools.CompletionHandler(kc)},
 	)
 
-	tools.RegisterAll(s, kc)
+	detectCtx, cancelDetect := context.WithTimeout(context.Background(), 10*time.Second)
+	if v, err := kc.DetectVersion(detectCtx); err != nil {
+		log.Warn().Err(err).Msg("could not detect Keycloak version; registering all tools")
+	} else {
+		log.Info().Str("keycloak_version", v.String()).Msg("detected Keycloak version")
+	}
+	cancelDetect()
+
+	auditLog, err := audit.New(cfg.AuditLog)
+	if err != nil {
+		log.Fatal().Err(err).Str("audit_log", cfg.AuditLog).Msg("failed to open audit log")
+	}
+	if cfg.ReadOnly {
+		log.Info().Msg("read-only mode: tools that modify Keycloak are disabled")
+	}
+
+	tools.RegisterAll(s, kc, cfg, auditLog)
+	// Added last so that every other middleware and handler sees the
+	// session's logger.
+	s.AddReceivingMiddleware(mcplog.New(stderr).Middleware())
 
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
@@ -89,14 +116,20 @@ func runHTTP(ctx context.Context, cfg *config.Config, s *mcp.Server) {
 	}
 }
 
-func initLogger(cfg *config.Config) {
+// initLogger logs to stderr at LOG_LEVEL and returns that output.
+func initLogger(cfg *config.Config) zerolog.LevelWriter {
 	level, err := zerolog.ParseLevel(cfg.LogLevel)
 	if err != nil {
 		level = zerolog.InfoLevel
 	}
-	zerolog.SetGlobalLevel(level)
-
+	var out io.Writer = os.Stderr
 	if cfg.LogFormat == "console" {
-		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+		out = zerolog.ConsoleWriter{Out: os.Stderr}
 	}
+	stderr := zerolog.LevelWriterAdapter{Writer: out}
+	log.Logger = log.Output(stderr)
+	zerolog.SetGlobalLevel(level)
+	// Outside a request, log.Ctx falls back to the global logger.
+	zerolog.DefaultContextLogger = &log.Logger
+	return stderr
 }
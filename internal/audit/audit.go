@@ -0,0 +1,101 @@
+// Package audit records every Keycloak-modifying operation performed through
+// the server as a structured log entry.
+package audit
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// sensitiveKey matches argument names whose values must never be logged.
+var sensitiveKey = regexp.MustCompile(`(?i)(password|secret|credential|token)`)
+
+// Logger writes audit entries. A nil *Logger discards them.
+type Logger struct {
+	zl zerolog.Logger
+}
+
+// Entry is one audited operation.
+type Entry struct {
+	Tool      string
+	Realm     string
+	Reason    string // why the caller made the change, when the tool asks for one
+	Arguments map[string]any
+	Error     string
+}
+
+// New returns an audit logger for dest: "stderr" logs through the main
+// logger, "off" or "" disables auditing and anything else is a file path
+// opened for appending.
+func New(dest string) (*Logger, error) {
+	switch strings.ToLower(dest) {
+	case "", "off", "none", "false":
+		return nil, nil
+	case "stderr":
+		return &Logger{zl: log.Logger.With().Str("component", "audit").Logger()}, nil
+	}
+	f, err := os.OpenFile(dest, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &Logger{zl: zerolog.New(f).With().Timestamp().Logger()}, nil
+}
+
+// Record writes e to the audit log with sensitive arguments redacted.
+func (l *Logger) Record(e Entry) {
+	if l == nil {
+		return
+	}
+	ev := l.zl.Log().
+		Bool("audit", true).
+		Str("tool", e.Tool).
+		Interface("arguments", Redact(e.Arguments))
+	if e.Realm != "" {
+		ev = ev.Str("realm", e.Realm)
+	}
+	if e.Reason != "" {
+		ev = ev.Str("reason", e.Reason)
+	}
+	if e.Error != "" {
+		ev = ev.Str("outcome", "error").Str("error", e.Error)
+	} else {
+		ev = ev.Str("outcome", "success")
+	}
+	ev.Msg("keycloak change")
+}
+
+// Redact returns a copy of args with the values of sensitive keys replaced,
+// recursing into nested objects and arrays.
+func Redact(args map[string]any) map[string]any {
+	if args == nil {
+		return nil
+	}
+	out := make(map[string]any, len(args))
+	for k, v := range args {
+		if sensitiveKey.MatchString(k) {
+			out[k] = "[REDACTED]"
+			continue
+		}
+		out[k] = redactValue(v)
+	}
+	return out
+}
+
+func redactValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		return Redact(t)
+	case []any:
+		out := make([]any, len(t))
+		for i, item := range t {
+			out[i] = redactValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
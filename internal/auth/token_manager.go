@@ -23,6 +23,10 @@ type TokenManager struct {
 
 func NewTokenManager(cfg *config.Config) *TokenManager {
 	gc := gocloak.NewClient(cfg.KeycloakURL)
+	// Every request carries a bearer token. Without a cookie jar, the session
+	// cookies Keycloak sets, such as those of impersonate_user, are never
+	// replayed on later requests.
+	gc.RestyClient().SetCookieJar(nil)
 	return &TokenManager{
 		gc:  gc,
 		cfg: cfg,
@@ -49,16 +53,25 @@ func (tm *TokenManager) Token(ctx context.Context) (string, error) {
 
 	jwt, err := tm.authenticate(ctx)
 	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("auth_mode", tm.cfg.AuthMode).Msg("failed to obtain admin token")
 		return "", fmt.Errorf("token acquisition failed: %w", err)
 	}
 
 	tm.token = jwt
 	tm.expiry = time.Now().Add(time.Duration(jwt.ExpiresIn)*time.Second - tm.cfg.TokenRefreshBuffer)
-	log.Debug().Time("expiry", tm.expiry).Msg("token acquired")
+	log.Ctx(ctx).Debug().Time("expiry", tm.expiry).Msg("token acquired")
 
 	return jwt.AccessToken, nil
 }
 
+// Invalidate drops the current token so that the next call authenticates
+// again, for when Keycloak has ended the session it belongs to.
+func (tm *TokenManager) Invalidate() {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.token = nil
+}
+
 func (tm *TokenManager) authenticate(ctx context.Context) (*gocloak.JWT, error) {
 	switch tm.cfg.AuthMode {
 	case "client_credentials":
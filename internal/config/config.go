@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -17,6 +19,14 @@ type Config struct {
 	ClientSecret       string
 	DefaultRealm       string
 	TokenRefreshBuffer time.Duration
+	CacheTTL           time.Duration // 0 disables the lookup cache
+	MaxResults         int           // hard cap on items returned by paginated list tools
+	ReadOnly           bool          // disable every tool that modifies Keycloak
+	AuditLog           string        // "stderr", a file path, or "off"
+	AdminAPIAllowlist  []string      // "METHOD /path" patterns allowed for admin_api_request
+	RevealSecrets      bool          // let get_client_secret return client secrets
+	AllowImpersonation bool          // let impersonate_user open sessions as other users
+	Confirm            string        // "auto", "always" or "off": when to ask the user to confirm destructive calls
 	LogLevel           string
 	LogFormat          string
 }
@@ -34,6 +44,14 @@ func Load() *Config {
 		ClientSecret:       os.Getenv("KEYCLOAK_CLIENT_SECRET"),
 		DefaultRealm:       os.Getenv("KEYCLOAK_DEFAULT_REALM"),
 		TokenRefreshBuffer: parseDuration(envOr("KEYCLOAK_TOKEN_REFRESH_BUFFER", "30s")),
+		CacheTTL:           parseDurationOr(envOr("KEYCLOAK_CACHE_TTL", "0s"), 0),
+		MaxResults:         parseIntOr(envOr("KEYCLOAK_MAX_RESULTS", "1000"), 1000),
+		ReadOnly:           parseBool(os.Getenv("KEYCLOAK_READ_ONLY")),
+		AuditLog:           envOr("AUDIT_LOG", "stderr"),
+		AdminAPIAllowlist:  parseList(envOr("KEYCLOAK_ADMIN_API_ALLOWLIST", "GET /**")),
+		RevealSecrets:      parseBool(os.Getenv("KEYCLOAK_REVEAL_SECRETS")),
+		AllowImpersonation: parseBool(os.Getenv("KEYCLOAK_ALLOW_IMPERSONATION")),
+		Confirm:            envOr("KEYCLOAK_CONFIRM", "auto"),
 		LogLevel:           envOr("LOG_LEVEL", "info"),
 		LogFormat:          envOr("LOG_FORMAT", "json"),
 	}
@@ -48,9 +66,36 @@ func envOr(key, fallback string) string {
 }
 
 func parseDuration(s string) time.Duration {
+	return parseDurationOr(s, 30*time.Second)
+}
+
+func parseDurationOr(s string, fallback time.Duration) time.Duration {
 	d, err := time.ParseDuration(s)
 	if err != nil {
-		return 30 * time.Second
+		return fallback
 	}
 	return d
 }
+
+func parseIntOr(s string, fallback int) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return fallback
+	}
+	return n
+}
+
+func parseBool(s string) bool {
+	b, _ := strconv.ParseBool(s)
+	return b
+}
+
+func parseList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
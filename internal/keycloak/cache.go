@@ -0,0 +1,201 @@
+package keycloak
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
+// Cached resource names. Mutating tools invalidate by these names.
+const (
+	ResourceRealms       = "realms"
+	ResourceRealm        = "realm"
+	ResourceClients      = "clients"
+	ResourceRealmRoles   = "realm_roles"
+	ResourceClientScopes = "client_scopes"
+)
+
+// cache is a TTL read-through cache for Keycloak lookups, keyed by
+// instance, realm and resource.
+type cache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+}
+
+type cacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+// CacheStats reports cache usage counters.
+type CacheStats struct {
+	Enabled bool   `json:"enabled"`
+	TTL     string `json:"ttl"`
+	Entries int    `json:"entries"`
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+}
+
+func newCache(ttl time.Duration) *cache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &cache{ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+func (c *cache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		delete(c.entries, key)
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return e.value, true
+}
+
+func (c *cache) set(key string, v any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cacheEntry{value: v, expires: time.Now().Add(c.ttl)}
+}
+
+// deleteMatching drops the entry for key and any parameterised variants of it.
+// A key ending in "|" drops everything under that prefix.
+func (c *cache) deleteMatching(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	all := strings.HasSuffix(key, "|")
+	for k := range c.entries {
+		if k == key || strings.HasPrefix(k, key+"?") || (all && strings.HasPrefix(k, key)) {
+			delete(c.entries, k)
+		}
+	}
+}
+
+type noCacheKey struct{}
+
+// WithNoCache returns a context that bypasses the lookup cache when noCache
+// is true. Fresh results are still stored for later callers.
+func WithNoCache(ctx context.Context, noCache bool) context.Context {
+	if !noCache {
+		return ctx
+	}
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+func bypassCache(ctx context.Context) bool {
+	v, _ := ctx.Value(noCacheKey{}).(bool)
+	return v
+}
+
+func (c *Client) cacheKey(realm, resource string) string {
+	return c.instance + "|" + realm + "|" + resource
+}
+
+// cached returns the value stored for realm/resource or calls fetch and
+// stores its result.
+func cached[T any](ctx context.Context, c *Client, realm, resource string, fetch func() (T, error)) (T, error) {
+	if c.cache == nil {
+		return fetch()
+	}
+	key := c.cacheKey(realm, resource)
+	if !bypassCache(ctx) {
+		if v, ok := c.cache.get(key); ok {
+			return v.(T), nil
+		}
+	}
+	v, err := fetch()
+	if err != nil {
+		return v, err
+	}
+	c.cache.set(key, v)
+	return v, nil
+}
+
+// withParams appends a stable encoding of query params to a resource name.
+func withParams(resource string, params any) string {
+	b, err := json.Marshal(params)
+	if err != nil || string(b) == "{}" {
+		return resource
+	}
+	return resource + "?" + string(b)
+}
+
+// Invalidate drops cached entries for the given resources in a realm. With no
+// resources, every entry for the realm is dropped.
+func (c *Client) Invalidate(realm string, resources ...string) {
+	if c.cache == nil {
+		return
+	}
+	if len(resources) == 0 {
+		c.cache.deleteMatching(c.cacheKey(realm, ""))
+		return
+	}
+	for _, r := range resources {
+		c.cache.deleteMatching(c.cacheKey(realm, r))
+	}
+}
+
+// CacheStats returns the current cache counters.
+func (c *Client) CacheStats() CacheStats {
+	if c.cache == nil {
+		return CacheStats{}
+	}
+	c.cache.mu.Lock()
+	n := len(c.cache.entries)
+	c.cache.mu.Unlock()
+	return CacheStats{
+		Enabled: true,
+		TTL:     c.cache.ttl.String(),
+		Entries: n,
+		Hits:    c.cache.hits.Load(),
+		Misses:  c.cache.misses.Load(),
+	}
+}
+
+// GetRealms lists realms through the cache.
+func (c *Client) GetRealms(ctx context.Context, token string) ([]*gocloak.RealmRepresentation, error) {
+	return cached(ctx, c, "", ResourceRealms, func() ([]*gocloak.RealmRepresentation, error) {
+		return c.GC.GetRealms(ctx, token)
+	})
+}
+
+// GetRealm fetches a realm representation through the cache. Callers must not
+// mutate the result; use GC.GetRealm for read-modify-write.
+func (c *Client) GetRealm(ctx context.Context, token, realm string) (*gocloak.RealmRepresentation, error) {
+	return cached(ctx, c, realm, ResourceRealm, func() (*gocloak.RealmRepresentation, error) {
+		return c.GC.GetRealm(ctx, token, realm)
+	})
+}
+
+// GetClients lists clients through the cache.
+func (c *Client) GetClients(ctx context.Context, token, realm string, params gocloak.GetClientsParams) ([]*gocloak.Client, error) {
+	return cached(ctx, c, realm, withParams(ResourceClients, params), func() ([]*gocloak.Client, error) {
+		return c.GC.GetClients(ctx, token, realm, params)
+	})
+}
+
+// GetRealmRoles lists realm roles through the cache.
+func (c *Client) GetRealmRoles(ctx context.Context, token, realm string, params gocloak.GetRoleParams) ([]*gocloak.Role, error) {
+	return cached(ctx, c, realm, withParams(ResourceRealmRoles, params), func() ([]*gocloak.Role, error) {
+		return c.GC.GetRealmRoles(ctx, token, realm, params)
+	})
+}
+
+// GetClientScopes lists client scopes through the cache.
+func (c *Client) GetClientScopes(ctx context.Context, token, realm string) ([]*gocloak.ClientScope, error) {
+	return cached(ctx, c, realm, ResourceClientScopes, func() ([]*gocloak.ClientScope, error) {
+		return c.GC.GetClientScopes(ctx, token, realm)
+	})
+}
@@ -0,0 +1,106 @@
+package keycloak
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func newCachedClient(ttl time.Duration) *Client {
+	return &Client{instance: "https://kc.example.com", cache: newCache(ttl)}
+}
+
+// counter is a fetch function that returns how often it was called.
+type counter int
+
+func (n *counter) fetch() (int, error) {
+	*n++
+	return int(*n), nil
+}
+
+func TestCacheHitsAndMisses(t *testing.T) {
+	c := newCachedClient(time.Minute)
+	ctx := context.Background()
+	var n counter
+
+	for i := 0; i < 3; i++ {
+		if v, _ := cached(ctx, c, "acme", ResourceClients, n.fetch); v != 1 {
+			t.Fatalf("call %d = %d, want the first fetch", i, v)
+		}
+	}
+	if v, _ := cached(ctx, c, "beta", ResourceClients, n.fetch); v != 2 {
+		t.Fatalf("other realm = %d, want a fresh fetch", v)
+	}
+	stats := c.CacheStats()
+	if !stats.Enabled || stats.Hits != 2 || stats.Misses != 2 || stats.Entries != 2 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestCacheNoCache(t *testing.T) {
+	c := newCachedClient(time.Minute)
+	ctx := context.Background()
+	var n counter
+
+	cached(ctx, c, "acme", ResourceRealm, n.fetch)
+	if v, _ := cached(WithNoCache(ctx, true), c, "acme", ResourceRealm, n.fetch); v != 2 {
+		t.Fatalf("no_cache = %d, want a fresh fetch", v)
+	}
+	// The fresh result replaces the stored one.
+	if v, _ := cached(WithNoCache(ctx, false), c, "acme", ResourceRealm, n.fetch); v != 2 {
+		t.Fatalf("after no_cache = %d, want the refreshed value", v)
+	}
+}
+
+func TestCacheExpires(t *testing.T) {
+	c := newCachedClient(10 * time.Millisecond)
+	ctx := context.Background()
+	var n counter
+
+	cached(ctx, c, "acme", ResourceRealmRoles, n.fetch)
+	time.Sleep(20 * time.Millisecond)
+	if v, _ := cached(ctx, c, "acme", ResourceRealmRoles, n.fetch); v != 2 {
+		t.Fatalf("after TTL = %d, want a fresh fetch", v)
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	c := newCachedClient(time.Minute)
+	ctx := context.Background()
+	var n counter
+	fill := func() {
+		cached(ctx, c, "", ResourceRealms, n.fetch)
+		cached(ctx, c, "acme", ResourceRealm, n.fetch)
+		cached(ctx, c, "acme", withParams(ResourceClients, map[string]string{"clientId": "portal"}), n.fetch)
+		cached(ctx, c, "acme", ResourceClientScopes, n.fetch)
+		cached(ctx, c, "beta", ResourceClientScopes, n.fetch)
+	}
+
+	fill()
+	c.Invalidate("acme", ResourceClients)
+	if got := c.CacheStats().Entries; got != 4 {
+		t.Fatalf("entries after invalidating clients = %d, want 4", got)
+	}
+
+	c.Invalidate("acme")
+	if got := c.CacheStats().Entries; got != 2 {
+		t.Fatalf("entries after invalidating the realm = %d, want 2", got)
+	}
+	before := n
+	cached(ctx, c, "beta", ResourceClientScopes, n.fetch)
+	cached(ctx, c, "", ResourceRealms, n.fetch)
+	if n != before {
+		t.Fatalf("other realms were invalidated")
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	c := newCachedClient(0)
+	var n counter
+	cached(context.Background(), c, "acme", ResourceRealm, n.fetch)
+	cached(context.Background(), c, "acme", ResourceRealm, n.fetch)
+	if n != 2 || c.CacheStats().Enabled {
+		t.Fatalf("disabled cache fetched %d times, stats %+v", n, c.CacheStats())
+	}
+	c.Invalidate("acme")
+}
@@ -0,0 +1,251 @@
+package keycloak
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
+// Capability names an Admin API feature that is not available on every
+// Keycloak version.
+type Capability string
+
+const (
+	CapClientPolicies      Capability = "client_policies"
+	CapUserProfile         Capability = "user_profile"
+	CapUnmanagedAttributes Capability = "unmanaged_attributes"
+	CapOrganizations       Capability = "organizations"
+)
+
+// requirement describes when a capability is available: the server must be
+// at least MinVersion and, if Feature is set, that feature must be enabled.
+type requirement struct {
+	MinVersion Version
+	Feature    string
+}
+
+// capabilityMatrix maps each capability to the servers that support it.
+var capabilityMatrix = map[Capability]requirement{
+	CapClientPolicies:      {MinVersion: Version{Major: 14}},
+	CapUserProfile:         {MinVersion: Version{Major: 24}},
+	CapUnmanagedAttributes: {MinVersion: Version{Major: 24}},
+	CapOrganizations:       {MinVersion: Version{Major: 25}, Feature: "organization"},
+}
+
+// Version is a parsed Keycloak server version.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Less reports whether v is older than o.
+func (v Version) Less(o Version) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor < o.Minor
+	}
+	return v.Patch < o.Patch
+}
+
+// ParseVersion parses versions such as "26.0.5" or "21.1.2.redhat-00001".
+func ParseVersion(s string) (Version, error) {
+	parts := strings.SplitN(s, ".", 4)
+	nums := make([]int, 3)
+	for i := 0; i < 3 && i < len(parts); i++ {
+		digits := parts[i]
+		if j := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }); j >= 0 {
+			digits = digits[:j]
+		}
+		n, err := strconv.Atoi(digits)
+		if err != nil {
+			if i == 0 {
+				return Version{}, fmt.Errorf("invalid Keycloak version %q", s)
+			}
+			break
+		}
+		nums[i] = n
+	}
+	return Version{Major: nums[0], Minor: nums[1], Patch: nums[2]}, nil
+}
+
+// UnsupportedError is returned when the connected server lacks a capability.
+type UnsupportedError struct {
+	Capability Capability
+	Required   Version
+	Feature    string
+	Server     Version
+}
+
+func (e *UnsupportedError) Error() string {
+	if !e.Server.Less(e.Required) && e.Feature != "" {
+		return fmt.Sprintf("%s requires the %q feature, which is disabled on this Keycloak %s server", e.Capability, e.Feature, e.Server)
+	}
+	return fmt.Sprintf("%s requires Keycloak %s or newer; the connected server is %s", e.Capability, e.Required, e.Server)
+}
+
+// ServerCapabilities describes the connected server as detected at startup.
+type ServerCapabilities struct {
+	Version      string          `json:"version"`
+	Capabilities map[string]bool `json:"capabilities"`
+}
+
+type serverInfo struct {
+	SystemInfo struct {
+		Version string `json:"version"`
+	} `json:"systemInfo"`
+	ProfileInfo struct {
+		DisabledFeatures []string `json:"disabledFeatures"`
+	} `json:"profileInfo"`
+	Features []struct {
+		Name    string `json:"name"`
+		Enabled bool   `json:"enabled"`
+	} `json:"features"`
+}
+
+// detected holds the result of version detection.
+type detected struct {
+	mu       sync.Mutex
+	known    bool
+	version  Version
+	features map[string]bool // nil when the server does not report features
+	disabled map[string]bool
+}
+
+// DetectVersion reads the server version and enabled features from the admin
+// serverinfo endpoint. Only the fields needed here are decoded so that
+// representation changes on newer servers do not break detection.
+func (c *Client) DetectVersion(ctx context.Context) (Version, error) {
+	token, err := c.Token(ctx)
+	if err != nil {
+		return Version{}, err
+	}
+	var info serverInfo
+	if err := c.getServerInfo(ctx, token, &info); err != nil {
+		return Version{}, err
+	}
+	v, err := ParseVersion(info.SystemInfo.Version)
+	if err != nil {
+		return Version{}, err
+	}
+
+	c.detected.mu.Lock()
+	defer c.detected.mu.Unlock()
+	c.detected.known = true
+	c.detected.version = v
+	c.detected.disabled = map[string]bool{}
+	for _, f := range info.ProfileInfo.DisabledFeatures {
+		c.detected.disabled[normalizeFeature(f)] = true
+	}
+	c.detected.features = nil
+	if len(info.Features) > 0 {
+		c.detected.features = map[string]bool{}
+		for _, f := range info.Features {
+			c.detected.features[normalizeFeature(f.Name)] = f.Enabled
+		}
+	}
+	return v, nil
+}
+
+// ServerInfo returns the admin serverinfo document keyed by top-level section.
+// Unlike gocloak's representation it keeps every section the server reports,
+// such as providers and features.
+func (c *Client) ServerInfo(ctx context.Context, token string) (map[string]json.RawMessage, error) {
+	var info map[string]json.RawMessage
+	if err := c.getServerInfo(ctx, token, &info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (c *Client) getServerInfo(ctx context.Context, token string, result any) error {
+	resp, err := c.GC.GetRequestWithBearerAuth(ctx, token).
+		SetResult(result).
+		Get(strings.TrimSuffix(c.instance, "/") + "/admin/serverinfo")
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		return &gocloak.APIError{Code: resp.StatusCode(), Message: resp.Status()}
+	}
+	return nil
+}
+
+func normalizeFeature(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+// Supports reports whether the connected server has capability cap. When the
+// version has not been detected every capability is assumed to be available.
+func (c *Client) Supports(cap Capability) bool {
+	return c.checkCapability(cap) == nil
+}
+
+// Require returns an *UnsupportedError if the connected server lacks cap. If
+// detection failed at startup it is retried once per call.
+func (c *Client) Require(ctx context.Context, cap Capability) error {
+	c.detected.mu.Lock()
+	known := c.detected.known
+	c.detected.mu.Unlock()
+	if !known {
+		// Best effort: an undetectable version must not block the call.
+		_, _ = c.DetectVersion(ctx)
+	}
+	return c.checkCapability(cap)
+}
+
+func (c *Client) checkCapability(cap Capability) error {
+	req, ok := capabilityMatrix[cap]
+	if !ok {
+		return nil
+	}
+	c.detected.mu.Lock()
+	defer c.detected.mu.Unlock()
+	if !c.detected.known {
+		return nil
+	}
+	unsupported := &UnsupportedError{Capability: cap, Required: req.MinVersion, Feature: req.Feature, Server: c.detected.version}
+	if c.detected.version.Less(req.MinVersion) {
+		return unsupported
+	}
+	if req.Feature == "" {
+		return nil
+	}
+	if c.detected.disabled[req.Feature] {
+		return unsupported
+	}
+	if c.detected.features != nil {
+		if enabled, listed := c.detected.features[req.Feature]; listed && !enabled {
+			return unsupported
+		}
+	}
+	return nil
+}
+
+// Capabilities returns the detected version and the capability matrix
+// evaluated against it.
+func (c *Client) Capabilities() ServerCapabilities {
+	c.detected.mu.Lock()
+	version := "unknown"
+	if c.detected.known {
+		version = c.detected.version.String()
+	}
+	c.detected.mu.Unlock()
+
+	caps := make(map[string]bool, len(capabilityMatrix))
+	for cap := range capabilityMatrix {
+		caps[string(cap)] = c.Supports(cap)
+	}
+	return ServerCapabilities{Version: version, Capabilities: caps}
+}
@@ -2,6 +2,8 @@ package keycloak
 
 import (
 	"context"
+	"net/url"
+	"strings"
 
 	"github.com/Nerzal/gocloak/v13"
 
@@ -14,6 +16,10 @@ type Client struct {
 	GC           *gocloak.GoCloak
 	tokenManager *auth.TokenManager
 	defaultRealm string
+	instance     string
+	cache        *cache
+	maxResults   int
+	detected     detected
 }
 
 func NewClient(cfg *config.Config, tm *auth.TokenManager) *Client {
@@ -21,6 +27,9 @@ func NewClient(cfg *config.Config, tm *auth.TokenManager) *Client {
 		GC:           tm.GoCloak(),
 		tokenManager: tm,
 		defaultRealm: cfg.DefaultRealm,
+		instance:     cfg.KeycloakURL,
+		cache:        newCache(cfg.CacheTTL),
+		maxResults:   cfg.MaxResults,
 	}
 }
 
@@ -29,6 +38,11 @@ func (c *Client) Token(ctx context.Context) (string, error) {
 	return c.tokenManager.Token(ctx)
 }
 
+// InvalidateToken makes the next Token call authenticate again.
+func (c *Client) InvalidateToken() {
+	c.tokenManager.Invalidate()
+}
+
 // ResolveRealm returns the provided realm or falls back to the configured default.
 func (c *Client) ResolveRealm(realm string) string {
 	if realm != "" {
@@ -39,3 +53,17 @@ func (c *Client) ResolveRealm(realm string) string {
 	}
 	return "master"
 }
+
+// AdminURL returns the absolute Admin REST API URL for a realm-relative path
+// such as "/attack-detection/brute-force/users/{id}".
+func (c *Client) AdminURL(realm, path string) string {
+	return strings.TrimSuffix(c.instance, "/") + "/admin/realms/" + url.PathEscape(realm) + path
+}
+
+// MaxResults returns the hard cap on items a paginated list tool may return.
+func (c *Client) MaxResults() int {
+	if c.maxResults > 0 {
+		return c.maxResults
+	}
+	return 1000
+}
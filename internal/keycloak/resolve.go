@@ -0,0 +1,171 @@
+package keycloak
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Nerzal/gocloak/v13"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// IsUUID reports whether s looks like a Keycloak internal ID.
+func IsUUID(s string) bool {
+	return uuidPattern.MatchString(s)
+}
+
+// Candidate is one possible match for an ambiguous identifier.
+type Candidate struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// AmbiguousError is returned when a human-friendly identifier matches more
+// than one object.
+type AmbiguousError struct {
+	Kind       string
+	Ref        string
+	Candidates []Candidate
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("%s %q is ambiguous: %d matches", e.Kind, e.Ref, len(e.Candidates))
+}
+
+// notFound builds a 404 error shaped like the ones gocloak returns.
+func notFound(kind, ref string) error {
+	return &gocloak.APIError{
+		Code:    http.StatusNotFound,
+		Message: fmt.Sprintf("404 Not Found: no %s matches %q", kind, ref),
+	}
+}
+
+// ResolveClientID returns the internal UUID for a client given either its
+// UUID or its clientId.
+func (c *Client) ResolveClientID(ctx context.Context, token, realm, ref string) (string, error) {
+	if ref == "" || IsUUID(ref) {
+		return ref, nil
+	}
+	clients, err := c.GetClients(ctx, token, realm, gocloak.GetClientsParams{ClientID: gocloak.StringP(ref)})
+	if err != nil {
+		return "", err
+	}
+	var matches []Candidate
+	for _, cl := range clients {
+		if gocloak.PString(cl.ClientID) == ref {
+			matches = append(matches, Candidate{ID: gocloak.PString(cl.ID), Name: ref})
+		}
+	}
+	return pick("client", ref, matches)
+}
+
+// ResolveUserID returns the internal UUID for a user given either its UUID,
+// its username or its email address.
+func (c *Client) ResolveUserID(ctx context.Context, token, realm, ref string) (string, error) {
+	if ref == "" || IsUUID(ref) {
+		return ref, nil
+	}
+	seen := map[string]bool{}
+	var matches []Candidate
+	collect := func(params gocloak.GetUsersParams) error {
+		params.Exact = gocloak.BoolP(true)
+		users, err := c.GC.GetUsers(ctx, token, realm, params)
+		if err != nil {
+			return err
+		}
+		for _, u := range users {
+			id := gocloak.PString(u.ID)
+			if seen[id] {
+				continue
+			}
+			if !strings.EqualFold(gocloak.PString(u.Username), ref) && !strings.EqualFold(gocloak.PString(u.Email), ref) {
+				continue
+			}
+			seen[id] = true
+			name := gocloak.PString(u.Username)
+			if email := gocloak.PString(u.Email); email != "" {
+				name += " <" + email + ">"
+			}
+			matches = append(matches, Candidate{ID: id, Name: name})
+		}
+		return nil
+	}
+	if err := collect(gocloak.GetUsersParams{Username: gocloak.StringP(ref)}); err != nil {
+		return "", err
+	}
+	if strings.Contains(ref, "@") {
+		if err := collect(gocloak.GetUsersParams{Email: gocloak.StringP(ref)}); err != nil {
+			return "", err
+		}
+	}
+	return pick("user", ref, matches)
+}
+
+// ResolveGroupID returns the internal UUID for a group given either its
+// UUID, its full path (e.g. "/eng/platform") or its name. A bare name must be
+// unique across the whole group tree.
+func (c *Client) ResolveGroupID(ctx context.Context, token, realm, ref string) (string, error) {
+	if ref == "" || IsUUID(ref) {
+		return ref, nil
+	}
+	if strings.HasPrefix(ref, "/") {
+		group, err := c.GC.GetGroupByPath(ctx, token, realm, strings.TrimPrefix(ref, "/"))
+		if err != nil {
+			return "", err
+		}
+		return gocloak.PString(group.ID), nil
+	}
+
+	groups, err := c.GC.GetGroups(ctx, token, realm, gocloak.GetGroupsParams{Search: gocloak.StringP(ref)})
+	if err != nil {
+		return "", err
+	}
+	var matches []Candidate
+	var walk func(gs []gocloak.Group)
+	walk = func(gs []gocloak.Group) {
+		for _, g := range gs {
+			if gocloak.PString(g.Name) == ref {
+				matches = append(matches, Candidate{ID: gocloak.PString(g.ID), Name: gocloak.PString(g.Path)})
+			}
+			if g.SubGroups != nil {
+				walk(*g.SubGroups)
+			}
+		}
+	}
+	for _, g := range groups {
+		walk([]gocloak.Group{*g})
+	}
+	return pick("group", ref, matches)
+}
+
+// ResolveRealmRole returns a realm role given either its name or its ID.
+func (c *Client) ResolveRealmRole(ctx context.Context, token, realm, ref string) (*gocloak.Role, error) {
+	if IsUUID(ref) {
+		// roles-by-id serves realm and client roles alike.
+		return c.GC.GetClientRoleByID(ctx, token, realm, ref)
+	}
+	return c.GC.GetRealmRole(ctx, token, realm, ref)
+}
+
+// ResolveClientRole returns a client role given either its name or its ID.
+// idOfClient must already be resolved to the client's internal UUID.
+func (c *Client) ResolveClientRole(ctx context.Context, token, realm, idOfClient, ref string) (*gocloak.Role, error) {
+	if IsUUID(ref) {
+		return c.GC.GetClientRoleByID(ctx, token, realm, ref)
+	}
+	return c.GC.GetClientRole(ctx, token, realm, idOfClient, ref)
+}
+
+func pick(kind, ref string, matches []Candidate) (string, error) {
+	switch len(matches) {
+	case 0:
+		return "", notFound(kind, ref)
+	case 1:
+		return matches[0].ID, nil
+	default:
+		return "", &AmbiguousError{Kind: kind, Ref: ref, Candidates: matches}
+	}
+}
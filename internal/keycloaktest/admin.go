@@ -0,0 +1,1207 @@
+package keycloaktest
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// realm holds the in-memory state of one realm.
+type realm struct {
+	rep         object
+	users       map[string]object
+	credentials map[string][]object // user ID -> credentials
+	groups      map[string]object   // group ID -> group without subGroups
+	parent      map[string]string   // group ID -> parent group ID
+	clients     map[string]object
+	secrets     map[string]string
+	scopes      map[string]map[string][]object // client ID -> "default"/"optional" -> scopes
+	roles       map[string]object              // realm roles by name
+	clientRoles map[string]map[string]object   // client ID -> role name -> role
+	composites  map[string]map[string]bool     // role ID -> composite role IDs
+	members     map[string]map[string]bool     // group ID -> user IDs
+	roleMap     map[string]map[string]bool     // user or group ID -> role IDs
+	flows       map[string]object              // authentication flows by ID, read-only
+	idps        map[string]object              // identity providers by alias
+	profile     object                         // declarative user profile
+	consents    map[string]map[string]object   // user ID -> client ID -> consent
+	offline     map[string]map[string]object   // user ID -> client ID -> offline session
+	sessions    map[string]object              // user sessions by ID
+}
+
+func newRealm(rep object) *realm {
+	return &realm{
+		rep:         rep,
+		users:       map[string]object{},
+		credentials: map[string][]object{},
+		groups:      map[string]object{},
+		parent:      map[string]string{},
+		clients:     map[string]object{},
+		secrets:     map[string]string{},
+		scopes:      map[string]map[string][]object{},
+		roles:       map[string]object{},
+		clientRoles: map[string]map[string]object{},
+		composites:  map[string]map[string]bool{},
+		members:     map[string]map[string]bool{},
+		roleMap:     map[string]map[string]bool{},
+		flows:       builtinFlows(),
+		idps:        map[string]object{},
+		profile:     builtinUserProfile(),
+		consents:    map[string]map[string]object{},
+		offline:     map[string]map[string]object{},
+		sessions:    map[string]object{},
+	}
+}
+
+// builtinFlows returns the top-level flows Keycloak creates with every realm.
+func builtinFlows() map[string]object {
+	flows := map[string]object{}
+	for _, alias := range []string{"browser", "direct grant", "registration", "reset credentials", "clients", "first broker login"} {
+		id := newID()
+		flows[id] = object{
+			"id": id, "alias": alias, "providerId": "basic-flow",
+			"topLevel": true, "builtIn": true, "authenticationExecutions": []any{},
+		}
+	}
+	return flows
+}
+
+// builtinRequiredActions are the required actions Keycloak registers with
+// every realm, as listed by authentication/required-actions.
+var builtinRequiredActions = func() []object {
+	var out []object
+	for i, alias := range []string{"CONFIGURE_TOTP", "TERMS_AND_CONDITIONS", "UPDATE_PASSWORD", "UPDATE_PROFILE", "VERIFY_EMAIL"} {
+		out = append(out, object{
+			"alias": alias, "name": alias, "providerId": alias,
+			"enabled": alias != "TERMS_AND_CONDITIONS", "defaultAction": false, "priority": (i + 1) * 10,
+		})
+	}
+	return out
+}()
+
+// builtinUserProfile returns the user profile Keycloak gives new realms,
+// reduced to the four default attributes and the user metadata group. Unlike
+// Keycloak's default it enables unmanaged attributes, so that tests can set
+// any attribute; see Server.SetUnmanagedAttributePolicy.
+func builtinUserProfile() object {
+	attr := func(name string, validations object) object {
+		return object{
+			"name": name, "displayName": "${" + name + "}", "validations": validations,
+			"permissions": object{"view": []any{"admin", "user"}, "edit": []any{"admin", "user"}},
+			"multivalued": false,
+		}
+	}
+	length := func(min, max int) object { return object{"min": min, "max": max} }
+	return object{
+		"attributes": []any{
+			attr("username", object{"length": length(3, 255), "username-prohibited-characters": object{}, "up-username-not-idn-homograph": object{}}),
+			attr("email", object{"email": object{}, "length": object{"max": 255}}),
+			attr("firstName", object{"length": object{"max": 255}, "person-name-prohibited-characters": object{}}),
+			attr("lastName", object{"length": object{"max": 255}, "person-name-prohibited-characters": object{}}),
+		},
+		"groups": []any{object{
+			"name": "user-metadata", "displayHeader": "User metadata",
+			"displayDescription": "Attributes, which refer to user metadata",
+		}},
+		"unmanagedAttributePolicy": "ENABLED",
+	}
+}
+
+func add(m map[string]map[string]bool, key, v string) {
+	if m[key] == nil {
+		m[key] = map[string]bool{}
+	}
+	m[key][v] = true
+}
+
+// ---------------------------------------------------------------------------
+// Realms
+// ---------------------------------------------------------------------------
+
+func (s *Server) realmsRoot(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		reps := map[string]object{}
+		for name, rl := range s.realms {
+			reps[name] = rl.rep
+		}
+		writeJSON(w, http.StatusOK, sorted(reps, "realm"))
+	case http.MethodPost:
+		var rep object
+		if !decode(r, &rep) || str(rep, "realm") == "" {
+			writeError(w, http.StatusBadRequest, "Realm name is required")
+			return
+		}
+		name := str(rep, "realm")
+		if _, ok := s.realms[name]; ok {
+			writeError(w, http.StatusConflict, "Conflict detected. See logs for details")
+			return
+		}
+		if str(rep, "id") == "" {
+			rep["id"] = newID()
+		}
+		s.realms[name] = newRealm(rep)
+		created(w, r, name)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "HTTP 405 Method Not Allowed")
+	}
+}
+
+func (s *Server) admin(w http.ResponseWriter, r *http.Request, segs []string) {
+	rl, ok := s.realms[segs[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Realm not found.")
+		return
+	}
+	rest := segs[1:]
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			// Like Keycloak, the representation embeds the identity providers.
+			rep := object{}
+			for k, v := range rl.rep {
+				rep[k] = v
+			}
+			rep["identityProviders"] = sorted(rl.idps, "alias")
+			writeJSON(w, http.StatusOK, rep)
+		case http.MethodPut:
+			var patch object
+			if !decode(r, &patch) {
+				writeError(w, http.StatusBadRequest, "invalid realm representation")
+				return
+			}
+			merge(rl.rep, patch)
+			rl.rep["realm"] = segs[0]
+			noContent(w)
+		case http.MethodDelete:
+			delete(s.realms, segs[0])
+			noContent(w)
+		}
+		return
+	}
+
+	switch rest[0] {
+	case "clear-realm-cache", "clear-user-cache", "clear-keys-cache":
+		noContent(w)
+	case "users":
+		rl.handleUsers(w, r, rest[1:])
+	case "groups":
+		rl.handleGroups(w, r, rest[1:])
+	case "group-by-path":
+		id := rl.groupByPath("/" + strings.Join(rest[1:], "/"))
+		if id == "" {
+			writeError(w, http.StatusNotFound, "Group path does not exist")
+			return
+		}
+		writeJSON(w, http.StatusOK, rl.groupRep(id, true))
+	case "clients":
+		rl.handleClients(w, r, rest[1:])
+	case "roles":
+		rl.handleRealmRoles(w, r, rest[1:])
+	case "authentication":
+		rl.handleAuthentication(w, r, rest[1:])
+	case "identity-provider":
+		rl.handleIdentityProviders(w, r, rest[1:])
+	case "sessions":
+		if len(rest) != 2 || r.Method != http.MethodDelete {
+			writeError(w, http.StatusNotFound, "HTTP 404 Not Found")
+			return
+		}
+		if _, ok := rl.sessions[rest[1]]; !ok {
+			writeError(w, http.StatusNotFound, "Session not found")
+			return
+		}
+		delete(rl.sessions, rest[1])
+		noContent(w)
+	case "roles-by-id":
+		if len(rest) != 2 || r.Method != http.MethodGet {
+			writeError(w, http.StatusNotFound, "HTTP 404 Not Found")
+			return
+		}
+		role, _ := rl.roleByID(rest[1])
+		if role == nil {
+			writeError(w, http.StatusNotFound, "Could not find role with id")
+			return
+		}
+		writeJSON(w, http.StatusOK, role)
+	default:
+		writeError(w, http.StatusNotFound, "HTTP 404 Not Found")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Authentication flows and required actions
+// ---------------------------------------------------------------------------
+
+func (rl *realm) handleAuthentication(w http.ResponseWriter, r *http.Request, rest []string) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "HTTP 405 Method Not Allowed")
+		return
+	}
+	switch {
+	case match(rest, "flows"):
+		writeJSON(w, http.StatusOK, sorted(rl.flows, "alias"))
+	case match(rest, "flows", "*"):
+		flow, ok := rl.flows[rest[1]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Could not find flow with id")
+			return
+		}
+		writeJSON(w, http.StatusOK, flow)
+	case match(rest, "required-actions"):
+		writeJSON(w, http.StatusOK, builtinRequiredActions)
+	default:
+		writeError(w, http.StatusNotFound, "HTTP 404 Not Found")
+	}
+}
+
+// addSession opens a user session for userID and returns its ID.
+func (rl *realm) addSession(userID string) string {
+	id := newID()
+	now := time.Now().UnixMilli()
+	rl.sessions[id] = object{
+		"id": id, "userId": userID, "username": str(rl.users[userID], "username"), "ipAddress": "127.0.0.1",
+		"start": now, "lastAccess": now, "clients": object{},
+	}
+	return id
+}
+
+// ---------------------------------------------------------------------------
+// Identity providers
+// ---------------------------------------------------------------------------
+
+func (rl *realm) handleIdentityProviders(w http.ResponseWriter, r *http.Request, rest []string) {
+	switch {
+	case match(rest, "instances") && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, sorted(rl.idps, "alias"))
+	case match(rest, "instances") && r.Method == http.MethodPost:
+		var idp object
+		if !decode(r, &idp) || str(idp, "alias") == "" {
+			writeError(w, http.StatusBadRequest, "invalid identity provider representation")
+			return
+		}
+		alias := str(idp, "alias")
+		if _, ok := rl.idps[alias]; ok {
+			writeError(w, http.StatusConflict, "Identity Provider "+alias+" already exists")
+			return
+		}
+		idp["internalId"] = newID()
+		rl.idps[alias] = idp
+		created(w, r, alias)
+	case match(rest, "instances", "*"):
+		idp, ok := rl.idps[rest[1]]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Could not find identity provider")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, idp)
+		case http.MethodPut:
+			var patch object
+			if !decode(r, &patch) {
+				writeError(w, http.StatusBadRequest, "invalid identity provider representation")
+				return
+			}
+			merge(idp, patch)
+			idp["alias"] = rest[1]
+			noContent(w)
+		case http.MethodDelete:
+			delete(rl.idps, rest[1])
+			noContent(w)
+		}
+	default:
+		writeError(w, http.StatusNotFound, "HTTP 404 Not Found")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Users
+// ---------------------------------------------------------------------------
+
+func (rl *realm) filterUsers(r *http.Request) []object {
+	q := r.URL.Query()
+	exact := q.Get("exact") == "true"
+	field := func(u object, key, want string) bool {
+		if want == "" {
+			return true
+		}
+		if exact {
+			return strings.EqualFold(str(u, key), want)
+		}
+		return contains(str(u, key), want)
+	}
+	var out []object
+	for _, u := range sorted(rl.users, "username") {
+		if s := q.Get("search"); s != "" && !contains(str(u, "username"), s) && !contains(str(u, "email"), s) &&
+			!contains(str(u, "firstName"), s) && !contains(str(u, "lastName"), s) {
+			continue
+		}
+		if !field(u, "username", q.Get("username")) || !field(u, "email", q.Get("email")) ||
+			!field(u, "firstName", q.Get("firstName")) || !field(u, "lastName", q.Get("lastName")) {
+			continue
+		}
+		if e := q.Get("enabled"); e != "" && strconv.FormatBool(u["enabled"] == true) != e {
+			continue
+		}
+		if !matchAttributes(u, q.Get("q")) {
+			continue
+		}
+		out = append(out, u)
+	}
+	return out
+}
+
+// matchAttributes implements the q=key:value[ key:value] attribute filter.
+func matchAttributes(u object, query string) bool {
+	if query == "" {
+		return true
+	}
+	attrs, _ := u["attributes"].(map[string]any)
+	for _, term := range strings.Fields(query) {
+		k, v, _ := strings.Cut(term, ":")
+		vals, _ := attrs[k].([]any)
+		found := false
+		for _, val := range vals {
+			if val == v {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (rl *realm) usernameTaken(username, exceptID string) bool {
+	for id, u := range rl.users {
+		if id != exceptID && strings.EqualFold(str(u, "username"), username) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleUserProfile serves users/profile. Like Keycloak, it refuses a profile
+// without the username and email attributes.
+func (rl *realm) handleUserProfile(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, rl.profile)
+	case http.MethodPut:
+		var profile object
+		if !decode(r, &profile) {
+			writeError(w, http.StatusBadRequest, "invalid user profile configuration")
+			return
+		}
+		if names := declaredAttributes(profile); !names["username"] || !names["email"] {
+			writeError(w, http.StatusBadRequest, "The attributes 'username' and 'email' are required")
+			return
+		}
+		rl.profile = profile
+		writeJSON(w, http.StatusOK, profile)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "HTTP 405 Method Not Allowed")
+	}
+}
+
+// declaredAttributes returns the names of a user profile's attributes.
+func declaredAttributes(profile object) map[string]bool {
+	names := map[string]bool{}
+	attrs, _ := profile["attributes"].([]any)
+	for _, a := range attrs {
+		if a, ok := a.(map[string]any); ok {
+			names[str(a, "name")] = true
+		}
+	}
+	return names
+}
+
+// userConsents lists the clients a user consented to or holds offline tokens
+// for, in the shape of Keycloak's users/{id}/consents.
+func (rl *realm) userConsents(userID string) []object {
+	out := []object{}
+	for _, c := range sorted(rl.clients, "clientId") {
+		cid := str(c, "id")
+		consent, consented := rl.consents[userID][cid]
+		_, offline := rl.offline[userID][cid]
+		if !consented && !offline {
+			continue
+		}
+		rep := object{"clientId": str(c, "clientId"), "grantedClientScopes": []any{}, "additionalGrants": []any{}}
+		if consented {
+			merge(rep, consent)
+		}
+		if offline {
+			rep["additionalGrants"] = []any{object{"client": cid, "key": "Offline Token"}}
+		}
+		out = append(out, rep)
+	}
+	return out
+}
+
+func (rl *realm) emailTaken(email, exceptID string) bool {
+	for id, u := range rl.users {
+		if email != "" && id != exceptID && strings.EqualFold(str(u, "email"), email) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rl *realm) handleUsers(w http.ResponseWriter, r *http.Request, rest []string) {
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, page(r, rl.filterUsers(r)))
+		return
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		var u object
+		if !decode(r, &u) || str(u, "username") == "" {
+			writeError(w, http.StatusBadRequest, "User name is missing")
+			return
+		}
+		u["username"] = strings.ToLower(str(u, "username"))
+		if rl.usernameTaken(str(u, "username"), "") {
+			writeError(w, http.StatusConflict, "User exists with same username")
+			return
+		}
+		if rl.emailTaken(str(u, "email"), "") {
+			writeError(w, http.StatusConflict, "User exists with same email")
+			return
+		}
+		delete(u, "credentials")
+		id := newID()
+		u["id"] = id
+		u["createdTimestamp"] = time.Now().UnixMilli()
+		rl.users[id] = u
+		created(w, r, id)
+		return
+	case match(rest, "count"):
+		writeJSON(w, http.StatusOK, len(rl.filterUsers(r)))
+		return
+	case match(rest, "profile"):
+		rl.handleUserProfile(w, r)
+		return
+	}
+
+	id := rest[0]
+	u, ok := rl.users[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "User not found")
+		return
+	}
+	switch {
+	case len(rest) == 1:
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, u)
+		case http.MethodPut:
+			var patch object
+			if !decode(r, &patch) {
+				writeError(w, http.StatusBadRequest, "invalid user representation")
+				return
+			}
+			if rl.emailTaken(str(patch, "email"), id) {
+				writeError(w, http.StatusConflict, "User exists with same email")
+				return
+			}
+			delete(patch, "username")
+			if attrs, ok := patch["attributes"].(map[string]any); ok && str(rl.profile, "unmanagedAttributePolicy") == "" {
+				// Keycloak ignores undeclared attributes without an error.
+				declared := declaredAttributes(rl.profile)
+				for name := range attrs {
+					if !declared[name] {
+						delete(attrs, name)
+					}
+				}
+			}
+			merge(u, patch)
+			noContent(w)
+		case http.MethodDelete:
+			delete(rl.users, id)
+			delete(rl.credentials, id)
+			delete(rl.roleMap, id)
+			for _, m := range rl.members {
+				delete(m, id)
+			}
+			noContent(w)
+		}
+	case match(rest, "*", "reset-password"):
+		var cred object
+		if !decode(r, &cred) || str(cred, "value") == "" {
+			writeError(w, http.StatusBadRequest, "Password is required")
+			return
+		}
+		creds := slices.DeleteFunc(rl.credentials[id], func(c object) bool { return str(c, "type") == "password" })
+		rl.credentials[id] = append(creds, object{"id": newID(), "type": "password", "createdDate": time.Now().UnixMilli(), "temporary": cred["temporary"]})
+		noContent(w)
+	case match(rest, "*", "credentials"):
+		creds := rl.credentials[id]
+		if creds == nil {
+			creds = []object{}
+		}
+		writeJSON(w, http.StatusOK, creds)
+	case match(rest, "*", "credentials", "*"):
+		creds := rl.credentials[id]
+		for i, c := range creds {
+			if str(c, "id") == rest[2] {
+				rl.credentials[id] = append(creds[:i], creds[i+1:]...)
+				noContent(w)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "Credential not found")
+	case match(rest, "*", "groups"):
+		var out []object
+		for gid := range rl.groups {
+			if rl.members[gid][id] {
+				out = append(out, rl.groupRep(gid, false))
+			}
+		}
+		writeJSON(w, http.StatusOK, page(r, sortObjects(out, "name")))
+	case match(rest, "*", "groups", "*"):
+		gid := rest[2]
+		if _, ok := rl.groups[gid]; !ok {
+			writeError(w, http.StatusNotFound, "Group not found")
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(rl.members[gid], id)
+		} else {
+			add(rl.members, gid, id)
+		}
+		noContent(w)
+	case match(rest, "*", "impersonation") && r.Method == http.MethodPost:
+		// Keycloak opens a session as the user and sets its cookies on the
+		// response. The admin account lives in master, so impersonating a
+		// master user happens in the admin's own realm.
+		rl.addSession(id)
+		for _, name := range []string{"KEYCLOAK_IDENTITY", "KEYCLOAK_SESSION"} {
+			http.SetCookie(w, &http.Cookie{Name: name, Value: newID(), Path: "/realms/" + str(rl.rep, "realm") + "/"})
+		}
+		writeJSON(w, http.StatusOK, object{"sameRealm": str(rl.rep, "realm") == "master", "redirect": "http://" + r.Host + "/realms/" + str(rl.rep, "realm") + "/account"})
+	case match(rest, "*", "consents"):
+		writeJSON(w, http.StatusOK, rl.userConsents(id))
+	case match(rest, "*", "consents", "*"):
+		// Revoking consent also revokes the client's offline tokens.
+		for cid, c := range rl.clients {
+			if str(c, "clientId") == rest[2] {
+				delete(rl.consents[id], cid)
+				delete(rl.offline[id], cid)
+			}
+		}
+		noContent(w)
+	case match(rest, "*", "offline-sessions", "*"):
+		sessions := []object{}
+		if sess, ok := rl.offline[id][rest[2]]; ok {
+			sessions = append(sessions, sess)
+		}
+		writeJSON(w, http.StatusOK, sessions)
+	case match(rest, "*", "sessions"):
+		sessions := map[string]object{}
+		for sid, sess := range rl.sessions {
+			if str(sess, "userId") == id {
+				sessions[sid] = sess
+			}
+		}
+		writeJSON(w, http.StatusOK, sorted(sessions, "id"))
+	case match(rest, "*", "logout"):
+		for sid, sess := range rl.sessions {
+			if str(sess, "userId") == id {
+				delete(rl.sessions, sid)
+			}
+		}
+		noContent(w)
+	case match(rest, "*", "federated-identity"):
+		writeJSON(w, http.StatusOK, []object{})
+	case match(rest, "*", "federated-identity", "*"), match(rest, "*", "send-verify-email"),
+		match(rest, "*", "execute-actions-email"):
+		noContent(w)
+	case match(rest, "*", "role-mappings", "realm"):
+		rl.roleMappings(w, r, id, "")
+	case match(rest, "*", "role-mappings", "clients", "*"):
+		rl.roleMappings(w, r, id, rest[3])
+	default:
+		writeError(w, http.StatusNotFound, "HTTP 404 Not Found")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Groups
+// ---------------------------------------------------------------------------
+
+func (rl *realm) addGroup(parentID string, g object) string {
+	id := newID()
+	g["id"] = id
+	delete(g, "subGroups")
+	rl.groups[id] = g
+	if parentID != "" {
+		rl.parent[id] = parentID
+	}
+	return id
+}
+
+func (rl *realm) groupPath(id string) string {
+	p := "/" + str(rl.groups[id], "name")
+	if parent, ok := rl.parent[id]; ok {
+		return rl.groupPath(parent) + p
+	}
+	return p
+}
+
+func (rl *realm) children(parentID string) []string {
+	var ids []string
+	for id := range rl.groups {
+		if rl.parent[id] == parentID {
+			ids = append(ids, id)
+		}
+	}
+	objs := make([]object, len(ids))
+	for i, id := range ids {
+		objs[i] = rl.groups[id]
+	}
+	ids = ids[:0]
+	for _, o := range sortObjects(objs, "name") {
+		ids = append(ids, str(o, "id"))
+	}
+	return ids
+}
+
+func (rl *realm) groupRep(id string, withSubGroups bool) object {
+	g := clone(rl.groups[id])
+	g["path"] = rl.groupPath(id)
+	kids := rl.children(id)
+	g["subGroupCount"] = len(kids)
+	if withSubGroups {
+		subs := []object{}
+		for _, kid := range kids {
+			subs = append(subs, rl.groupRep(kid, true))
+		}
+		g["subGroups"] = subs
+	}
+	return g
+}
+
+func (rl *realm) groupByPath(path string) string {
+	for id := range rl.groups {
+		if rl.groupPath(id) == path {
+			return id
+		}
+	}
+	return ""
+}
+
+func (rl *realm) subtreeMatches(id, search string) bool {
+	if contains(str(rl.groups[id], "name"), search) {
+		return true
+	}
+	for _, kid := range rl.children(id) {
+		if rl.subtreeMatches(kid, search) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rl *realm) topGroups(r *http.Request) []object {
+	search := r.URL.Query().Get("search")
+	var out []object
+	for _, id := range rl.children("") {
+		if search == "" || rl.subtreeMatches(id, search) {
+			out = append(out, rl.groupRep(id, true))
+		}
+	}
+	return out
+}
+
+func (rl *realm) siblingNameTaken(parentID, name, exceptID string) bool {
+	for _, id := range rl.children(parentID) {
+		if id != exceptID && str(rl.groups[id], "name") == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (rl *realm) createGroup(w http.ResponseWriter, r *http.Request, parentID string) {
+	var g object
+	if !decode(r, &g) || str(g, "name") == "" {
+		writeError(w, http.StatusBadRequest, "Group name is missing")
+		return
+	}
+	if rl.siblingNameTaken(parentID, str(g, "name"), "") {
+		writeError(w, http.StatusConflict, "Top level group named '"+str(g, "name")+"' already exists.")
+		return
+	}
+	created(w, r, rl.addGroup(parentID, g))
+}
+
+func (rl *realm) deleteGroup(id string) {
+	for _, kid := range rl.children(id) {
+		rl.deleteGroup(kid)
+	}
+	delete(rl.groups, id)
+	delete(rl.parent, id)
+	delete(rl.members, id)
+	delete(rl.roleMap, id)
+}
+
+func (rl *realm) handleGroups(w http.ResponseWriter, r *http.Request, rest []string) {
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, page(r, rl.topGroups(r)))
+		return
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		rl.createGroup(w, r, "")
+		return
+	case match(rest, "count"):
+		writeJSON(w, http.StatusOK, object{"count": len(rl.topGroups(r))})
+		return
+	}
+
+	id := rest[0]
+	if _, ok := rl.groups[id]; !ok {
+		writeError(w, http.StatusNotFound, "Could not find group by id")
+		return
+	}
+	switch {
+	case len(rest) == 1:
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, rl.groupRep(id, true))
+		case http.MethodPut:
+			var patch object
+			if !decode(r, &patch) {
+				writeError(w, http.StatusBadRequest, "invalid group representation")
+				return
+			}
+			if name := str(patch, "name"); name != "" && rl.siblingNameTaken(rl.parent[id], name, id) {
+				writeError(w, http.StatusConflict, "Sibling group named '"+name+"' already exists.")
+				return
+			}
+			delete(patch, "subGroups")
+			delete(patch, "path")
+			merge(rl.groups[id], patch)
+			noContent(w)
+		case http.MethodDelete:
+			rl.deleteGroup(id)
+			noContent(w)
+		}
+	case match(rest, "*", "children"):
+		if r.Method == http.MethodPost {
+			rl.createGroup(w, r, id)
+			return
+		}
+		var out []object
+		for _, kid := range rl.children(id) {
+			out = append(out, rl.groupRep(kid, false))
+		}
+		writeJSON(w, http.StatusOK, page(r, out))
+	case match(rest, "*", "members"):
+		var out []object
+		for _, u := range sorted(rl.users, "username") {
+			if rl.members[id][str(u, "id")] {
+				out = append(out, u)
+			}
+		}
+		writeJSON(w, http.StatusOK, page(r, out))
+	case match(rest, "*", "role-mappings", "realm"):
+		rl.roleMappings(w, r, id, "")
+	case match(rest, "*", "role-mappings", "clients", "*"):
+		rl.roleMappings(w, r, id, rest[3])
+	default:
+		writeError(w, http.StatusNotFound, "HTTP 404 Not Found")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Clients
+// ---------------------------------------------------------------------------
+
+func (rl *realm) addClient(c object) string {
+	id := newID()
+	c["id"] = id
+	if _, ok := c["enabled"]; !ok {
+		c["enabled"] = true
+	}
+	if str(c, "protocol") == "" {
+		c["protocol"] = "openid-connect"
+	}
+	rl.clients[id] = c
+	rl.secrets[id] = newID()
+	rl.clientRoles[id] = map[string]object{}
+	rl.scopes[id] = map[string][]object{"default": {}, "optional": {}}
+	return id
+}
+
+func (rl *realm) handleClients(w http.ResponseWriter, r *http.Request, rest []string) {
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		q := r.URL.Query()
+		var out []object
+		for _, c := range sorted(rl.clients, "clientId") {
+			if want := q.Get("clientId"); want != "" {
+				if q.Get("search") == "true" && !contains(str(c, "clientId"), want) ||
+					q.Get("search") != "true" && str(c, "clientId") != want {
+					continue
+				}
+			}
+			out = append(out, c)
+		}
+		writeJSON(w, http.StatusOK, page(r, out))
+		return
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		var c object
+		if !decode(r, &c) || str(c, "clientId") == "" {
+			writeError(w, http.StatusBadRequest, "Client id is missing")
+			return
+		}
+		for _, existing := range rl.clients {
+			if str(existing, "clientId") == str(c, "clientId") {
+				writeError(w, http.StatusConflict, "Client "+str(c, "clientId")+" already exists")
+				return
+			}
+		}
+		created(w, r, rl.addClient(c))
+		return
+	}
+
+	id := rest[0]
+	c, ok := rl.clients[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Could not find client")
+		return
+	}
+	switch {
+	case len(rest) == 1:
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, c)
+		case http.MethodPut:
+			var patch object
+			if !decode(r, &patch) {
+				writeError(w, http.StatusBadRequest, "invalid client representation")
+				return
+			}
+			merge(c, patch)
+			noContent(w)
+		case http.MethodDelete:
+			delete(rl.clients, id)
+			delete(rl.secrets, id)
+			delete(rl.clientRoles, id)
+			delete(rl.scopes, id)
+			noContent(w)
+		}
+	case match(rest, "*", "client-secret"):
+		if r.Method == http.MethodPost {
+			rl.secrets[id] = newID()
+		}
+		writeJSON(w, http.StatusOK, object{"type": "secret", "value": rl.secrets[id]})
+	case match(rest, "*", "service-account-user"):
+		if c["serviceAccountsEnabled"] != true {
+			writeError(w, http.StatusBadRequest, "Service account not enabled for the client '"+str(c, "clientId")+"'")
+			return
+		}
+		username := "service-account-" + strings.ToLower(str(c, "clientId"))
+		for _, u := range rl.users {
+			if str(u, "username") == username {
+				writeJSON(w, http.StatusOK, u)
+				return
+			}
+		}
+		uid := newID()
+		rl.users[uid] = object{"id": uid, "username": username, "enabled": true, "serviceAccountClientId": str(c, "clientId")}
+		writeJSON(w, http.StatusOK, rl.users[uid])
+	case match(rest, "*", "user-sessions"):
+		writeJSON(w, http.StatusOK, []object{})
+	case match(rest, "*", "offline-sessions"):
+		sessions := []object{}
+		for _, u := range sorted(rl.users, "username") {
+			if sess, ok := rl.offline[str(u, "id")][id]; ok {
+				sessions = append(sessions, sess)
+			}
+		}
+		writeJSON(w, http.StatusOK, page(r, sessions))
+	case match(rest, "*", "default-client-scopes"), match(rest, "*", "optional-client-scopes"):
+		writeJSON(w, http.StatusOK, rl.scopes[id][scopeKind(rest[1])])
+	case match(rest, "*", "default-client-scopes", "*"), match(rest, "*", "optional-client-scopes", "*"):
+		kind := scopeKind(rest[1])
+		list := rl.scopes[id][kind][:0:0]
+		for _, sc := range rl.scopes[id][kind] {
+			if str(sc, "id") != rest[2] {
+				list = append(list, sc)
+			}
+		}
+		if r.Method == http.MethodPut {
+			list = append(list, object{"id": rest[2], "name": rest[2]})
+		}
+		rl.scopes[id][kind] = list
+		noContent(w)
+	case match(rest, "*", "protocol-mappers", "models"):
+		var m object
+		if !decode(r, &m) || str(m, "name") == "" {
+			writeError(w, http.StatusBadRequest, "Protocol mapper name is missing")
+			return
+		}
+		mid := newID()
+		m["id"] = mid
+		mappers, _ := c["protocolMappers"].([]any)
+		c["protocolMappers"] = append(mappers, m)
+		created(w, r, mid)
+	case match(rest, "*", "protocol-mappers", "models", "*"):
+		mappers, _ := c["protocolMappers"].([]any)
+		for i, m := range mappers {
+			mo, _ := m.(object)
+			if str(mo, "id") != rest[3] {
+				continue
+			}
+			if r.Method == http.MethodDelete {
+				c["protocolMappers"] = append(mappers[:i], mappers[i+1:]...)
+			} else {
+				var patch object
+				if !decode(r, &patch) {
+					writeError(w, http.StatusBadRequest, "invalid protocol mapper representation")
+					return
+				}
+				merge(mo, patch)
+			}
+			noContent(w)
+			return
+		}
+		writeError(w, http.StatusNotFound, "Model not found")
+	case len(rest) >= 2 && rest[1] == "roles":
+		rl.handleRoles(w, r, rest[2:], rl.clientRoles[id], id)
+	default:
+		writeError(w, http.StatusNotFound, "HTTP 404 Not Found")
+	}
+}
+
+func scopeKind(seg string) string {
+	if seg == "optional-client-scopes" {
+		return "optional"
+	}
+	return "default"
+}
+
+// ---------------------------------------------------------------------------
+// Roles
+// ---------------------------------------------------------------------------
+
+func (rl *realm) handleRealmRoles(w http.ResponseWriter, r *http.Request, rest []string) {
+	rl.handleRoles(w, r, rest, rl.roles, "")
+}
+
+// handleRoles serves realm roles (clientID == "") and client roles alike.
+func (rl *realm) handleRoles(w http.ResponseWriter, r *http.Request, rest []string, roles map[string]object, clientID string) {
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		search := r.URL.Query().Get("search")
+		var out []object
+		for _, role := range sorted(roles, "name") {
+			if search == "" || contains(str(role, "name"), search) {
+				out = append(out, role)
+			}
+		}
+		writeJSON(w, http.StatusOK, page(r, out))
+		return
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		var role object
+		if !decode(r, &role) || str(role, "name") == "" {
+			writeError(w, http.StatusBadRequest, "Role name is missing")
+			return
+		}
+		name := str(role, "name")
+		if _, ok := roles[name]; ok {
+			writeError(w, http.StatusConflict, "Role with name "+name+" already exists")
+			return
+		}
+		role["id"] = newID()
+		role["composite"] = false
+		role["clientRole"] = clientID != ""
+		if clientID != "" {
+			role["containerId"] = clientID
+		} else {
+			role["containerId"] = str(rl.rep, "id")
+		}
+		roles[name] = role
+		created(w, r, name)
+		return
+	}
+
+	role, ok := roles[rest[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Could not find role")
+		return
+	}
+	roleID := str(role, "id")
+	switch {
+	case len(rest) == 1:
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, role)
+		case http.MethodPut:
+			var patch object
+			if !decode(r, &patch) {
+				writeError(w, http.StatusBadRequest, "invalid role representation")
+				return
+			}
+			delete(patch, "composite")
+			delete(patch, "clientRole")
+			delete(patch, "containerId")
+			merge(role, patch)
+			if name := str(role, "name"); name != rest[0] {
+				delete(roles, rest[0])
+				roles[name] = role
+			}
+			noContent(w)
+		case http.MethodDelete:
+			delete(roles, rest[0])
+			delete(rl.composites, roleID)
+			for _, set := range rl.roleMap {
+				delete(set, roleID)
+			}
+			for _, set := range rl.composites {
+				delete(set, roleID)
+			}
+			noContent(w)
+		}
+	case match(rest, "*", "composites"):
+		switch r.Method {
+		case http.MethodGet:
+			var out []object
+			for cid := range rl.composites[roleID] {
+				if c, _ := rl.roleByID(cid); c != nil {
+					out = append(out, c)
+				}
+			}
+			writeJSON(w, http.StatusOK, sortObjects(out, "name"))
+		case http.MethodPost, http.MethodDelete:
+			ids, ok := rl.decodeRoles(w, r, "")
+			if !ok {
+				return
+			}
+			for _, cid := range ids {
+				if r.Method == http.MethodPost {
+					add(rl.composites, roleID, cid)
+				} else {
+					delete(rl.composites[roleID], cid)
+				}
+			}
+			role["composite"] = len(rl.composites[roleID]) > 0
+			noContent(w)
+		}
+	case match(rest, "*", "users"):
+		var out []object
+		for _, u := range sorted(rl.users, "username") {
+			if rl.roleMap[str(u, "id")][roleID] {
+				out = append(out, u)
+			}
+		}
+		writeJSON(w, http.StatusOK, page(r, out))
+	case match(rest, "*", "groups"):
+		var out []object
+		for gid := range rl.groups {
+			if rl.roleMap[gid][roleID] {
+				out = append(out, rl.groupRep(gid, false))
+			}
+		}
+		writeJSON(w, http.StatusOK, page(r, sortObjects(out, "name")))
+	default:
+		writeError(w, http.StatusNotFound, "HTTP 404 Not Found")
+	}
+}
+
+// roleByID finds a realm or client role by ID and returns it with the
+// internal ID of its client ("" for realm roles).
+func (rl *realm) roleByID(id string) (object, string) {
+	for _, role := range rl.roles {
+		if str(role, "id") == id {
+			return role, ""
+		}
+	}
+	for clientID, roles := range rl.clientRoles {
+		for _, role := range roles {
+			if str(role, "id") == id {
+				return role, clientID
+			}
+		}
+	}
+	return nil, ""
+}
+
+// decodeRoles reads a role representation array and resolves each entry to
+// a role ID, by ID or else by name within clientID ("" for realm roles).
+func (rl *realm) decodeRoles(w http.ResponseWriter, r *http.Request, clientID string) ([]string, bool) {
+	var reps []object
+	if !decode(r, &reps) {
+		writeError(w, http.StatusBadRequest, "invalid role representation")
+		return nil, false
+	}
+	var ids []string
+	for _, rep := range reps {
+		if role, _ := rl.roleByID(str(rep, "id")); role != nil {
+			ids = append(ids, str(role, "id"))
+			continue
+		}
+		roles := rl.roles
+		if clientID != "" {
+			roles = rl.clientRoles[clientID]
+		}
+		role, ok := roles[str(rep, "name")]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Role not found")
+			return nil, false
+		}
+		ids = append(ids, str(role, "id"))
+	}
+	return ids, true
+}
+
+// roleMappings serves role-mappings/realm and role-mappings/clients/{id} for a
+// user or group.
+func (rl *realm) roleMappings(w http.ResponseWriter, r *http.Request, principal, clientID string) {
+	if clientID != "" {
+		if _, ok := rl.clients[clientID]; !ok {
+			writeError(w, http.StatusNotFound, "Client not found")
+			return
+		}
+	}
+	switch r.Method {
+	case http.MethodGet:
+		out := []object{}
+		for rid := range rl.roleMap[principal] {
+			if role, cid := rl.roleByID(rid); role != nil && cid == clientID {
+				out = append(out, role)
+			}
+		}
+		writeJSON(w, http.StatusOK, sortObjects(out, "name"))
+	case http.MethodPost, http.MethodDelete:
+		ids, ok := rl.decodeRoles(w, r, clientID)
+		if !ok {
+			return
+		}
+		for _, rid := range ids {
+			if r.Method == http.MethodPost {
+				add(rl.roleMap, principal, rid)
+			} else {
+				delete(rl.roleMap[principal], rid)
+			}
+		}
+		noContent(w)
+	}
+}
+
+func sortObjects(objs []object, key string) []object {
+	m := make(map[string]object, len(objs))
+	for _, o := range objs {
+		m[str(o, "id")] = o
+	}
+	out := sorted(m, key)
+	if out == nil {
+		return []object{}
+	}
+	return out
+}
@@ -0,0 +1,295 @@
+package keycloaktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Mode selects whether a Recorder talks to a live server or to a cassette.
+type Mode int
+
+const (
+	// Replay serves responses from a cassette file without touching the network.
+	Replay Mode = iota
+	// Record forwards requests to the server and saves the traffic.
+	Record
+)
+
+// Redacted replaces scrubbed values in cassettes.
+const Redacted = "[REDACTED]"
+
+// Cassette is the on-disk form of recorded traffic.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one request and the response Keycloak gave to it.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest identifies a request. Headers are not kept: they carry the
+// bearer token and nothing the tools vary on.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+	Body   any    `json:"body,omitempty"`
+}
+
+// RecordedResponse is a scrubbed response. Location is kept relative to the
+// server so that cassettes replay against any base URL.
+type RecordedResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Body        any    `json:"body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper that records traffic to, or replays it
+// from, a cassette file. Install it on the gocloak resty client with
+// GoCloak.RestyClient().SetTransport.
+type Recorder struct {
+	path string
+	mode Mode
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+	misses   []string
+}
+
+// NewRecorder opens the cassette at path. In Replay mode the file must exist;
+// in Record mode it is written by Save.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode, next: http.DefaultTransport}
+	if mode == Record {
+		return r, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading cassette: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("decoding cassette %s: %w", path, err)
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	rr := RecordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.Query().Encode(),
+		Body:   scrubBody(req.Header.Get("Content-Type"), body),
+	}
+	if r.mode == Record {
+		return r.record(req, rr)
+	}
+	return r.replay(req, rr)
+}
+
+func (r *Recorder) record(req *http.Request, rr RecordedRequest) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	location := resp.Header.Get("Location")
+	if u, err := url.Parse(location); err == nil && location != "" {
+		location = u.RequestURI()
+	}
+	ct := resp.Header.Get("Content-Type")
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: rr,
+		Response: RecordedResponse{
+			Status:      resp.StatusCode,
+			ContentType: ct,
+			Location:    location,
+			Body:        scrubBody(ct, body),
+		},
+	})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// replay answers with the first unused interaction that matches the request.
+// Interactions are consumed in order so that repeated requests (a GET before
+// and after an update) see their own responses.
+func (r *Recorder) replay(req *http.Request, rr RecordedRequest) (*http.Response, error) {
+	want := requestKey(rr)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || requestKey(in.Request) != want {
+			continue
+		}
+		r.used[i] = true
+		return in.Response.httpResponse(req)
+	}
+	miss := rr.Method + " " + rr.Path
+	if rr.Query != "" {
+		miss += "?" + rr.Query
+	}
+	r.misses = append(r.misses, miss)
+	return nil, fmt.Errorf("keycloaktest: no recorded interaction for %s", miss)
+}
+
+func (rr RecordedResponse) httpResponse(req *http.Request) (*http.Response, error) {
+	var body []byte
+	switch b := rr.Body.(type) {
+	case nil:
+	case string:
+		body = []byte(b)
+	default:
+		var err error
+		if body, err = json.Marshal(b); err != nil {
+			return nil, err
+		}
+	}
+	header := http.Header{}
+	if rr.ContentType != "" {
+		header.Set("Content-Type", rr.ContentType)
+	}
+	if rr.Location != "" {
+		header.Set("Location", req.URL.Scheme+"://"+req.URL.Host+rr.Location)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rr.Status, http.StatusText(rr.Status)),
+		StatusCode:    rr.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Save writes the recorded cassette. It is a no-op in Replay mode.
+func (r *Recorder) Save() error {
+	if r.mode != Record {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
+
+// Misses returns the requests that had no recorded interaction during replay.
+func (r *Recorder) Misses() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.misses...)
+}
+
+// requestKey is the canonical form requests are matched on. Bodies are
+// compared after scrubbing and re-encoding so that key order and credentials
+// do not matter.
+func requestKey(rr RecordedRequest) string {
+	body, _ := json.Marshal(rr.Body)
+	return rr.Method + " " + rr.Path + "?" + rr.Query + " " + string(body)
+}
+
+// ---------------------------------------------------------------------------
+// Scrubbing
+// ---------------------------------------------------------------------------
+
+// sensitiveKeys are JSON object keys and form fields whose values never reach
+// a cassette. Matching is case-insensitive on the whole key.
+var sensitiveKeys = map[string]bool{
+	"password":       true,
+	"secret":         true,
+	"clientsecret":   true,
+	"client_secret":  true,
+	"access_token":   true,
+	"refresh_token":  true,
+	"id_token":       true,
+	"credentialdata": true,
+	"secretdata":     true,
+}
+
+// scrubBody decodes body by content type and removes secrets. JSON bodies are
+// kept structured so that cassettes stay readable; anything else is kept as a
+// string.
+func scrubBody(contentType string, body []byte) any {
+	if len(body) == 0 {
+		return nil
+	}
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return string(body)
+		}
+		for k := range form {
+			if sensitiveKeys[strings.ToLower(k)] {
+				form[k] = []string{Redacted}
+			}
+		}
+		return form.Encode()
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+	return scrubJSON(v)
+}
+
+func scrubJSON(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		// Credential and client secret representations carry the secret in
+		// "value" next to a "type".
+		_, typed := t["type"]
+		for k, val := range t {
+			if sensitiveKeys[strings.ToLower(k)] || (typed && k == "value") {
+				if _, isString := val.(string); isString {
+					t[k] = Redacted
+					continue
+				}
+			}
+			t[k] = scrubJSON(val)
+		}
+	case []any:
+		for i, val := range t {
+			t[i] = scrubJSON(val)
+		}
+	}
+	return v
+}
@@ -0,0 +1,29 @@
+package keycloaktest
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestScrubBody(t *testing.T) {
+	form := scrubBody("application/x-www-form-urlencoded", []byte("grant_type=password&username=admin&password=hunter2&client_secret=abc"))
+	if s := form.(string); strings.Contains(s, "hunter2") || strings.Contains(s, "abc") || !strings.Contains(s, "username=admin") {
+		t.Fatalf("form not scrubbed: %s", s)
+	}
+
+	body := `{"access_token":"eyJ","expires_in":300,"accessTokenLifespan":300,
+		"credentials":[{"type":"password","value":"hunter2","temporary":false}],
+		"config":{"clientSecret":"abc","clientId":"idp"},"attributes":{"value":["kept"]}}`
+	out, _ := json.Marshal(scrubBody("application/json", []byte(body)))
+	for _, leaked := range []string{"eyJ", "hunter2", `"abc"`} {
+		if strings.Contains(string(out), leaked) {
+			t.Errorf("%s leaked: %s", leaked, out)
+		}
+	}
+	for _, kept := range []string{`"expires_in":300`, `"accessTokenLifespan":300`, `"clientId":"idp"`, `"kept"`} {
+		if !strings.Contains(string(out), kept) {
+			t.Errorf("%s lost: %s", kept, out)
+		}
+	}
+}
@@ -0,0 +1,350 @@
+// Package keycloaktest provides an in-process fake of the Keycloak token and
+// Admin REST API endpoints that the tools call through gocloak. State for
+// realms, users, groups, clients and roles is kept in memory, alongside the
+// read-only built-in authentication flows and required actions and each
+// realm's user profile; endpoints outside those domains answer 404 like an
+// unknown Keycloak route.
+//
+// Recorder complements the fake: it records traffic against a real Keycloak
+// into cassette files and replays it without a network.
+package keycloaktest
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/config"
+)
+
+const (
+	// AdminUser and AdminPassword are the credentials the fake accepts.
+	AdminUser     = "admin"
+	AdminPassword = "admin"
+	// DefaultRealm is seeded alongside master and used as the default realm.
+	DefaultRealm = "acme"
+
+	accessToken = "fake-access-token"
+)
+
+type object = map[string]any
+
+// Server is a fake Keycloak server.
+type Server struct {
+	*httptest.Server
+
+	// Version is reported by /admin/serverinfo.
+	Version string
+
+	mu     sync.Mutex
+	realms map[string]*realm
+}
+
+// NewServer starts a fake Keycloak with the master and DefaultRealm realms.
+// Callers must Close it.
+func NewServer() *Server {
+	s := &Server{Version: "26.0.5", realms: map[string]*realm{}}
+	s.realms["master"] = newRealm(object{"id": newID(), "realm": "master", "enabled": true})
+	s.realms[DefaultRealm] = newRealm(object{"id": newID(), "realm": DefaultRealm, "enabled": true})
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Config returns a server configuration pointed at the fake.
+func (s *Server) Config() *config.Config {
+	return &config.Config{
+		KeycloakURL:       s.URL,
+		KeycloakRealm:     "master",
+		AuthMode:          "password",
+		AdminUser:         AdminUser,
+		AdminPassword:     AdminPassword,
+		DefaultRealm:      DefaultRealm,
+		MaxResults:        1000,
+		AuditLog:          "off",
+		AdminAPIAllowlist: []string{"GET /**"},
+	}
+}
+
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// ---------------------------------------------------------------------------
+// HTTP plumbing
+// ---------------------------------------------------------------------------
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := strings.TrimSuffix(r.URL.EscapedPath(), "/")
+	switch {
+	case strings.HasPrefix(p, "/realms/") && strings.HasSuffix(p, "/protocol/openid-connect/token"):
+		s.token(w, r)
+		return
+	case r.Header.Get("Authorization") != "Bearer "+accessToken:
+		writeError(w, http.StatusUnauthorized, "HTTP 401 Unauthorized")
+		return
+	case p == "/admin/serverinfo":
+		writeJSON(w, http.StatusOK, object{
+			"systemInfo": object{"version": s.Version},
+			"memoryInfo": object{"total": 536870912, "used": 134217728},
+			"themes":     object{"login": []object{{"name": "keycloak"}}},
+			"providers":  object{"login-protocol": object{"providers": object{"openid-connect": object{"order": 0}}}},
+			"features":   []object{{"name": "ORGANIZATION", "enabled": true}},
+		})
+		return
+	case p == "/admin/realms":
+		s.realmsRoot(w, r)
+		return
+	case strings.HasPrefix(p, "/admin/realms/"):
+		var segs []string
+		for _, seg := range strings.Split(strings.TrimPrefix(p, "/admin/realms/"), "/") {
+			u, err := url.PathUnescape(seg)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid path")
+				return
+			}
+			segs = append(segs, u)
+		}
+		s.admin(w, r, segs)
+		return
+	}
+	writeError(w, http.StatusNotFound, "HTTP 404 Not Found")
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	ok := false
+	switch r.PostForm.Get("grant_type") {
+	case "password":
+		ok = r.PostForm.Get("username") == AdminUser && r.PostForm.Get("password") == AdminPassword
+	case "client_credentials":
+		_, secret, _ := r.BasicAuth()
+		ok = secret != "" || r.PostForm.Get("client_secret") != ""
+	}
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, object{"error": "invalid_grant", "error_description": "Invalid user credentials"})
+		return
+	}
+	writeJSON(w, http.StatusOK, object{
+		"access_token": accessToken,
+		"expires_in":   300,
+		"token_type":   "Bearer",
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, object{"errorMessage": msg})
+}
+
+func created(w http.ResponseWriter, r *http.Request, id string) {
+	w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/")+"/"+url.PathEscape(id))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func noContent(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decode(r *http.Request, v any) bool {
+	return json.NewDecoder(r.Body).Decode(v) == nil
+}
+
+// match reports whether segs has the same length as pattern and equals it
+// outside of "*" wildcards.
+func match(segs []string, pattern ...string) bool {
+	if len(segs) != len(pattern) {
+		return false
+	}
+	for i, p := range pattern {
+		if p != "*" && p != segs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// page applies Keycloak's first/max query parameters.
+func page[T any](r *http.Request, items []T) []T {
+	q := r.URL.Query()
+	first, max := 0, -1
+	fmt.Sscan(q.Get("first"), &first)
+	fmt.Sscan(q.Get("max"), &max)
+	if first > len(items) {
+		first = len(items)
+	}
+	items = items[first:]
+	if max >= 0 && max < len(items) {
+		items = items[:max]
+	}
+	return items
+}
+
+func str(o object, key string) string {
+	v, _ := o[key].(string)
+	return v
+}
+
+func sorted(m map[string]object, key string) []object {
+	out := make([]object, 0, len(m))
+	for _, o := range m {
+		out = append(out, o)
+	}
+	sort.Slice(out, func(i, j int) bool { return str(out[i], key) < str(out[j], key) })
+	return out
+}
+
+func clone(o object) object {
+	b, _ := json.Marshal(o)
+	var out object
+	_ = json.Unmarshal(b, &out)
+	return out
+}
+
+// merge overlays the fields of patch onto o, except for the ID.
+func merge(o, patch object) {
+	for k, v := range patch {
+		if k != "id" {
+			o[k] = v
+		}
+	}
+}
+
+func contains(haystack, needle string) bool {
+	return strings.Contains(strings.ToLower(haystack), strings.ToLower(needle))
+}
+
+// ---------------------------------------------------------------------------
+// Seeding
+// ---------------------------------------------------------------------------
+
+// AddUser creates a user in realm and returns its ID.
+func (s *Server) AddUser(realmName, username, email string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := newID()
+	s.realms[realmName].users[id] = object{"id": id, "username": username, "email": email, "enabled": true}
+	return id
+}
+
+// AddCredential gives the user a credential of credType, such as "otp", and
+// returns its ID.
+func (s *Server) AddCredential(realmName, userID, credType string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := newID()
+	rl := s.realms[realmName]
+	rl.credentials[userID] = append(rl.credentials[userID], object{"id": id, "type": credType, "createdDate": time.Now().UnixMilli()})
+	return id
+}
+
+// AddConsent records that the user granted the client with internal ID
+// idOfClient the given client scopes.
+func (s *Server) AddConsent(realmName, userID, idOfClient string, scopes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rl := s.realms[realmName]
+	granted := make([]any, len(scopes))
+	for i, sc := range scopes {
+		granted[i] = sc
+	}
+	now := time.Now().UnixMilli()
+	add := rl.consents[userID]
+	if add == nil {
+		add = map[string]object{}
+		rl.consents[userID] = add
+	}
+	add[idOfClient] = object{"grantedClientScopes": granted, "createdDate": now, "lastUpdatedDate": now}
+}
+
+// AddUserSession opens a session for the user and returns its ID.
+func (s *Server) AddUserSession(realmName, userID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.realms[realmName].addSession(userID)
+}
+
+// AddOfflineSession gives the user an offline session for the client with
+// internal ID idOfClient and returns the session ID.
+func (s *Server) AddOfflineSession(realmName, userID, idOfClient string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rl := s.realms[realmName]
+	id := newID()
+	now := time.Now().UnixMilli()
+	if rl.offline[userID] == nil {
+		rl.offline[userID] = map[string]object{}
+	}
+	rl.offline[userID][idOfClient] = object{
+		"id": id, "userId": userID, "username": str(rl.users[userID], "username"), "ipAddress": "127.0.0.1",
+		"start": now, "lastAccess": now, "clients": object{idOfClient: str(rl.clients[idOfClient], "clientId")},
+	}
+	return id
+}
+
+// SetUnmanagedAttributePolicy sets the realm's unmanagedAttributePolicy. An
+// empty policy disables unmanaged attributes, as on a new Keycloak realm:
+// attributes the user profile doesn't declare are then dropped on update.
+func (s *Server) SetUnmanagedAttributePolicy(realmName, policy string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	profile := s.realms[realmName].profile
+	if policy == "" {
+		delete(profile, "unmanagedAttributePolicy")
+		return
+	}
+	profile["unmanagedAttributePolicy"] = policy
+}
+
+// AddGroup creates a group under parentID (top-level when empty) and returns
+// its ID.
+func (s *Server) AddGroup(realmName, parentID, name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.realms[realmName].addGroup(parentID, object{"name": name})
+}
+
+// AddClient creates a client in realm and returns its internal ID.
+func (s *Server) AddClient(realmName, clientID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.realms[realmName].addClient(object{"clientId": clientID})
+}
+
+// AddRealmRole creates a realm role and returns its ID.
+func (s *Server) AddRealmRole(realmName, name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := newID()
+	s.realms[realmName].roles[name] = object{"id": id, "name": name, "composite": false, "clientRole": false}
+	return id
+}
+
+// EnableServiceAccount turns on service accounts for the client with the
+// given internal ID.
+func (s *Server) EnableServiceAccount(realmName, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.realms[realmName].clients[id]["serviceAccountsEnabled"] = true
+}
@@ -0,0 +1,177 @@
+// Package mcplog forwards server logs to MCP clients as notifications/message,
+// so that warnings reach the assistant and not only stderr.
+//
+// Only events logged through the request context's logger (log.Ctx(ctx)) are
+// forwarded, and only to the session whose request produced them: on the HTTP
+// transport one client never sees another client's logs. Events logged through
+// the global logger, such as startup messages and audit entries, stay local.
+package mcplog
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/audit"
+)
+
+// loggerName identifies the server's messages among those of other MCP servers.
+const loggerName = "keycloak-mcp"
+
+// sendTimeout bounds how long one notification may hold up a session's queue.
+const sendTimeout = time.Second
+
+// queueSize is how many notifications a session may have pending. Events
+// beyond it are dropped so that a slow client never slows down the server.
+const queueSize = 64
+
+// levels maps zerolog levels to MCP logging levels. Events without a level
+// are sent as info.
+var levels = map[zerolog.Level]mcp.LoggingLevel{
+	zerolog.TraceLevel: "debug",
+	zerolog.DebugLevel: "debug",
+	zerolog.InfoLevel:  "info",
+	zerolog.WarnLevel:  "warning",
+	zerolog.ErrorLevel: "error",
+	zerolog.FatalLevel: "critical",
+	zerolog.PanicLevel: "emergency",
+}
+
+// severity orders the MCP logging levels from least to most severe.
+var severity = []mcp.LoggingLevel{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"}
+
+// Forwarder routes request-scoped log events to the MCP session that made the
+// request, in addition to the server's own log output.
+type Forwarder struct {
+	out zerolog.LevelWriter
+
+	mu       sync.Mutex
+	sessions map[*mcp.ServerSession]*session
+}
+
+// New returns a Forwarder that also writes every event to out, which should
+// be the writer of the global logger.
+func New(out zerolog.LevelWriter) *Forwarder {
+	return &Forwarder{out: out, sessions: map[*mcp.ServerSession]*session{}}
+}
+
+// Middleware gives each request's context a logger (see log.Ctx) that writes
+// to the server's output and to the requesting session, and tracks the level
+// each session sets with logging/setLevel.
+func (f *Forwarder) Middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			ss, ok := req.GetSession().(*mcp.ServerSession)
+			if !ok {
+				return next(ctx, method, req)
+			}
+			sess := f.session(ss)
+			logger := log.Logger.Output(zerolog.MultiLevelWriter(f.out, sess))
+			res, err := next(logger.WithContext(ctx), method, req)
+			if p, ok := req.GetParams().(*mcp.SetLoggingLevelParams); ok && err == nil {
+				sess.setLevel(p.Level)
+			}
+			return res, err
+		}
+	}
+}
+
+// session returns the forwarding state of ss, starting its delivery loop on
+// first use. The state is dropped when the session ends.
+func (f *Forwarder) session(ss *mcp.ServerSession) *session {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok := f.sessions[ss]; ok {
+		return s
+	}
+	s := &session{ss: ss, queue: make(chan *mcp.LoggingMessageParams, queueSize), done: make(chan struct{})}
+	f.sessions[ss] = s
+	go func() {
+		_ = ss.Wait()
+		f.mu.Lock()
+		delete(f.sessions, ss)
+		f.mu.Unlock()
+		close(s.done)
+	}()
+	go s.deliver()
+	return s
+}
+
+// session is a zerolog.LevelWriter that queues events for one MCP session.
+// Nothing is queued before the client sets a level, and then only events at
+// or above it. Fields whose names look sensitive are redacted as in the audit
+// log.
+type session struct {
+	ss    *mcp.ServerSession
+	queue chan *mcp.LoggingMessageParams
+	done  chan struct{}
+
+	mu    sync.Mutex
+	level mcp.LoggingLevel
+}
+
+func (s *session) setLevel(level mcp.LoggingLevel) {
+	s.mu.Lock()
+	s.level = level
+	s.mu.Unlock()
+}
+
+// wants reports whether the client asked for events at level.
+func (s *session) wants(level mcp.LoggingLevel) bool {
+	s.mu.Lock()
+	floor := s.level
+	s.mu.Unlock()
+	return floor != "" && slices.Index(severity, level) >= slices.Index(severity, floor)
+}
+
+func (s *session) Write(p []byte) (int, error) {
+	return s.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel queues the event p without blocking. Events that are not JSON
+// objects, and events the queue has no room for, are dropped: forwarding
+// must never fail or hold up the caller.
+func (s *session) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	mcpLevel, ok := levels[level]
+	if !ok {
+		mcpLevel = "info"
+	}
+	if !s.wants(mcpLevel) {
+		return len(p), nil
+	}
+	var event map[string]any
+	if err := json.Unmarshal(p, &event); err != nil {
+		return len(p), nil
+	}
+	delete(event, zerolog.LevelFieldName)
+	params := &mcp.LoggingMessageParams{
+		Level:  mcpLevel,
+		Logger: loggerName,
+		Data:   audit.Redact(event),
+	}
+	select {
+	case s.queue <- params:
+	default:
+	}
+	return len(p), nil
+}
+
+// deliver sends queued events until the session ends.
+func (s *session) deliver() {
+	for {
+		select {
+		case params := <-s.queue:
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			_ = s.ss.Log(ctx, params)
+			cancel()
+		case <-s.done:
+			return
+		}
+	}
+}
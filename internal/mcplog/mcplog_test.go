@@ -0,0 +1,121 @@
+package mcplog
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+type noArgs struct{}
+
+// newServer returns a server whose "work" tool logs through the request
+// context, as tools do.
+func newServer(t *testing.T) *mcp.Server {
+	t.Helper()
+	s := mcp.NewServer(&mcp.Implementation{Name: "keycloak-mcp", Version: "test"}, nil)
+	mcp.AddTool(s, &mcp.Tool{Name: "work"}, func(ctx context.Context, req *mcp.CallToolRequest, _ noArgs) (*mcp.CallToolResult, any, error) {
+		log.Ctx(ctx).Info().Msg("below the client's level")
+		log.Ctx(ctx).Warn().Str("client_secret", "s3cret").Msg("failed to obtain admin token")
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil, nil
+	})
+	s.AddReceivingMiddleware(New(zerolog.LevelWriterAdapter{Writer: io.Discard}).Middleware())
+	return s
+}
+
+// connectClient connects a client to s and returns it with the channel its
+// log notifications arrive on.
+func connectClient(t *testing.T, s *mcp.Server) (*mcp.ClientSession, chan *mcp.LoggingMessageParams) {
+	t.Helper()
+	ctx := context.Background()
+	ct, st := mcp.NewInMemoryTransports()
+	if _, err := s.Connect(ctx, st, nil); err != nil {
+		t.Fatal(err)
+	}
+	got := make(chan *mcp.LoggingMessageParams, 10)
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) { got <- req.Params },
+	}).Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cs.Close() })
+	return cs, got
+}
+
+func callWork(t *testing.T, cs *mcp.ClientSession) {
+	t.Helper()
+	if _, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "work"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func expectNone(t *testing.T, who string, got chan *mcp.LoggingMessageParams) {
+	t.Helper()
+	select {
+	case p := <-got:
+		t.Fatalf("%s: unexpected message %+v", who, p)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestForwardLogs(t *testing.T) {
+	ctx := context.Background()
+	s := newServer(t)
+	alice, aliceGot := connectClient(t, s)
+	bob, bobGot := connectClient(t, s)
+
+	callWork(t, alice)
+	expectNone(t, "before setLevel", aliceGot)
+
+	for _, cs := range []*mcp.ClientSession{alice, bob} {
+		if err := cs.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "warning"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	callWork(t, alice)
+
+	select {
+	case p := <-aliceGot:
+		data, _ := p.Data.(map[string]any)
+		if p.Level != "warning" || p.Logger != loggerName || data["message"] != "failed to obtain admin token" {
+			t.Fatalf("message = %+v", p)
+		}
+		if data["client_secret"] != "[REDACTED]" {
+			t.Fatalf("client_secret = %v, want it redacted", data["client_secret"])
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no log notification received")
+	}
+	expectNone(t, "caller", aliceGot)
+	expectNone(t, "other session", bobGot)
+
+	// The global logger never reaches clients.
+	log.Warn().Msg("startup warning")
+	expectNone(t, "global logger", bobGot)
+}
+
+func TestWriteNeverBlocks(t *testing.T) {
+	s := &session{queue: make(chan *mcp.LoggingMessageParams, queueSize), level: "debug"}
+	logger := zerolog.New(s)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10*queueSize; i++ {
+			logger.Error().Int("i", i).Msg("nobody is draining the queue")
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("logging blocked on a full queue")
+	}
+	if len(s.queue) != queueSize {
+		t.Fatalf("queued %d events, want %d", len(s.queue), queueSize)
+	}
+}
@@ -0,0 +1,174 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/config"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
+)
+
+// ---------------------------------------------------------------------------
+// Arg structs
+// ---------------------------------------------------------------------------
+
+type adminAPIRequestArgs struct {
+	Realm  string            `json:"realm,omitempty"  jsonschema:"Realm name (uses default if omitted)"`
+	Method string            `json:"method"           jsonschema:"HTTP method: GET, POST, PUT, PATCH or DELETE"`
+	Path   string            `json:"path"             jsonschema:"Path relative to /admin/realms/{realm}, e.g. /client-policies/policies"`
+	Query  map[string]string `json:"query,omitempty"  jsonschema:"Query parameters"`
+	Body   any               `json:"body,omitempty"   jsonschema:"JSON request body"`
+}
+
+type adminAPIResponse struct {
+	Status   int    `json:"status"`
+	Location string `json:"location,omitempty"`
+	Body     any    `json:"body,omitempty"`
+}
+
+// ---------------------------------------------------------------------------
+// Allowlist
+// ---------------------------------------------------------------------------
+
+// allowRule is one "METHOD /path/pattern" allowlist entry. Each pattern
+// segment is matched with path.Match; a final "**" matches any remainder.
+type allowRule struct {
+	method  string
+	pattern []string
+}
+
+func parseAllowlist(entries []string) []allowRule {
+	var rules []allowRule
+	for _, e := range entries {
+		method, pattern, ok := strings.Cut(strings.TrimSpace(e), " ")
+		if !ok {
+			continue
+		}
+		rules = append(rules, allowRule{
+			method:  strings.ToUpper(method),
+			pattern: strings.Split(strings.Trim(strings.TrimSpace(pattern), "/"), "/"),
+		})
+	}
+	return rules
+}
+
+func (r allowRule) matches(method, p string) bool {
+	if r.method != "*" && r.method != method {
+		return false
+	}
+	segs := strings.Split(strings.Trim(p, "/"), "/")
+	for i, pat := range r.pattern {
+		if pat == "**" {
+			return true
+		}
+		if i >= len(segs) {
+			return false
+		}
+		if ok, _ := path.Match(pat, segs[i]); !ok {
+			return false
+		}
+	}
+	return len(segs) == len(r.pattern)
+}
+
+func allowed(rules []allowRule, method, p string) bool {
+	for _, r := range rules {
+		if r.matches(method, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathCapabilities maps the first segment of a realm-relative path to the
+// capability its endpoints need, so that a missing feature is reported as
+// such rather than as Keycloak's 404.
+var pathCapabilities = map[string]keycloak.Capability{
+	"client-policies": keycloak.CapClientPolicies,
+	"organizations":   keycloak.CapOrganizations,
+}
+
+// ---------------------------------------------------------------------------
+// Registration
+// ---------------------------------------------------------------------------
+
+func registerAdminAPITools(s *mcp.Server, kc *keycloak.Client, cfg *config.Config) {
+	rules := parseAllowlist(cfg.AdminAPIAllowlist)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:         "admin_api_request",
+		Description:  "Call any realm-scoped Keycloak Admin REST API endpoint not covered by a typed tool (e.g. client policies, organizations). Restricted by KEYCLOAK_ADMIN_API_ALLOWLIST",
+		OutputSchema: outputSchema[adminAPIResponse](),
+		Annotations:  destructive("Admin API request", false),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args adminAPIRequestArgs) (*mcp.CallToolResult, any, error) {
+		method := strings.ToUpper(args.Method)
+		switch method {
+		case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			return validationError(fmt.Sprintf("unsupported method %q", args.Method))
+		}
+		p := "/" + strings.Trim(args.Path, "/")
+		unescaped, err := url.PathUnescape(p)
+		if err != nil || strings.ContainsAny(p, "?#") || path.Clean(unescaped) != unescaped {
+			return validationError(fmt.Sprintf("invalid path %q: use a clean realm-relative path and pass query parameters via query", args.Path))
+		}
+		if cfg.ReadOnly && method != http.MethodGet {
+			return readOnlyError("admin_api_request " + method)
+		}
+		if !allowed(rules, method, p) {
+			return toolError(&toolErr{
+				Code:    codeForbidden,
+				Message: fmt.Sprintf("%s %s is not in the admin API allowlist", method, p),
+				Hint:    "Add a matching \"METHOD /path\" entry to KEYCLOAK_ADMIN_API_ALLOWLIST, or use a typed tool instead.",
+			})
+		}
+
+		first, _, _ := strings.Cut(strings.TrimPrefix(p, "/"), "/")
+		if cap, ok := pathCapabilities[first]; ok {
+			if err := kc.Require(ctx, cap); err != nil {
+				return kcError(fmt.Sprintf("%s %s is unavailable", method, p), err)
+			}
+		}
+
+		token, err := kc.Token(ctx)
+		if err != nil {
+			return tokenError(err)
+		}
+		realm := kc.ResolveRealm(args.Realm)
+
+		r := kc.GC.GetRequestWithBearerAuth(ctx, token).SetQueryParams(args.Query)
+		if args.Body != nil {
+			r = r.SetBody(args.Body)
+		}
+		resp, err := r.Execute(method, kc.AdminURL(realm, strings.TrimSuffix(p, "/")))
+		if err != nil {
+			return kcError(fmt.Sprintf("%s %s failed", method, p), err)
+		}
+		if method != http.MethodGet {
+			// The path could touch anything in the realm, so drop all of it.
+			kc.Invalidate(realm)
+			kc.Invalidate("", keycloak.ResourceRealms)
+		}
+		if resp.IsError() {
+			return kcError(fmt.Sprintf("%s %s failed", method, p), apiError(resp.StatusCode(), resp.Status(), resp.Body()))
+		}
+
+		out := adminAPIResponse{Status: resp.StatusCode(), Location: resp.Header().Get("Location")}
+		if body := resp.Body(); len(body) > 0 {
+			var v any
+			if err := json.Unmarshal(body, &v); err == nil {
+				out.Body = v
+			} else {
+				out.Body = string(body)
+			}
+		}
+		return toolResult(out)
+	})
+}
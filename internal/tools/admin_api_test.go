@@ -0,0 +1,38 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAdminAPIRequest(t *testing.T) {
+	h := newHarness(t)
+	h.fake.AddUser("acme", "ivan", "ivan@example.com")
+
+	var resp struct {
+		Status int              `json:"status"`
+		Body   []map[string]any `json:"body"`
+	}
+	h.okJSON("admin_api_request", map[string]any{"method": "GET", "path": "/users", "query": map[string]string{"username": "ivan"}}, &resp)
+	if resp.Status != 200 {
+		t.Fatalf("status = %d", resp.Status)
+	}
+	assertNames(t, "users", resp.Body, "username", "ivan")
+
+	h.fail("admin_api_request", map[string]any{"method": "POST", "path": "/users", "body": map[string]any{"username": "eve"}}, codeForbidden)
+	h.fail("admin_api_request", map[string]any{"method": "GET", "path": "/users/../../master"}, codeValidation)
+	h.fail("admin_api_request", map[string]any{"method": "TRACE", "path": "/users"}, codeValidation)
+	h.fail("admin_api_request", map[string]any{"method": "GET", "path": "/users/missing"}, codeNotFound)
+}
+
+func TestAdminAPIRequestChecksCapabilities(t *testing.T) {
+	h := newHarness(t)
+	h.fake.Version = "24.0.5"
+
+	e := h.fail("admin_api_request", map[string]any{"method": "GET", "path": "/organizations"}, codeUnsupported)
+	if !strings.Contains(e.Message, "organizations requires Keycloak 25.0.0") {
+		t.Fatalf("message = %q", e.Message)
+	}
+	// Client policies exist since Keycloak 14, so the request reaches Keycloak.
+	h.fail("admin_api_request", map[string]any{"method": "GET", "path": "/client-policies/policies"}, codeNotFound)
+}
@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Every tool sets one of the annotation presets below so that clients can
+// auto-approve safe calls and warn before dangerous ones. Tools only talk to
+// the configured Keycloak server, so they are closed-world unless they reach
+// people outside it (emails).
+
+// readOnly annotates a tool that only reads from Keycloak.
+func readOnly(title string) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		Title:           title,
+		ReadOnlyHint:    true,
+		DestructiveHint: gocloak.BoolP(false),
+		IdempotentHint:  true,
+		OpenWorldHint:   gocloak.BoolP(false),
+	}
+}
+
+// additive annotates a tool that creates or links objects without changing
+// or removing existing ones. Adding to a set is idempotent; creating an
+// object is not.
+func additive(title string, idempotent bool) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		Title:           title,
+		DestructiveHint: gocloak.BoolP(false),
+		IdempotentHint:  idempotent,
+		OpenWorldHint:   gocloak.BoolP(false),
+	}
+}
+
+// destructive annotates a tool that overwrites or removes existing state.
+func destructive(title string, idempotent bool) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		Title:           title,
+		DestructiveHint: gocloak.BoolP(true),
+		IdempotentHint:  idempotent,
+		OpenWorldHint:   gocloak.BoolP(false),
+	}
+}
+
+// emails annotates a tool that sends email to a user: every call reaches
+// outside Keycloak and sends another message.
+func emails(title string) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		Title:           title,
+		DestructiveHint: gocloak.BoolP(false),
+		OpenWorldHint:   gocloak.BoolP(true),
+	}
+}
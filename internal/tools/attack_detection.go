@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/Nerzal/gocloak/v13"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
@@ -15,12 +16,13 @@ import (
 
 type getBruteForceStatusArgs struct {
 	Realm  string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID string `json:"user_id"         jsonschema:"User ID"`
+	UserID string `json:"user_id"         jsonschema:"User ID, username or email"`
+	viewArgs
 }
 
 type clearBruteForceStatusArgs struct {
 	Realm  string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID string `json:"user_id"         jsonschema:"User ID"`
+	UserID string `json:"user_id"         jsonschema:"User ID, username or email"`
 }
 
 // ---------------------------------------------------------------------------
@@ -31,41 +33,53 @@ func registerAttackDetectionTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 1. get_brute_force_status
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_brute_force_status",
-		Description: "Get brute force detection status for a user",
+		Name:         "get_brute_force_status",
+		Description:  "Get brute force detection status for a user",
+		OutputSchema: outputSchema[gocloak.BruteForceStatus](),
+		Annotations:  readOnly("Get brute-force status"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getBruteForceStatusArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+		}
 
-		status, err := kc.GC.GetUserBruteForceDetectionStatus(ctx, token, realm, args.UserID)
+		status, err := kc.GC.GetUserBruteForceDetectionStatus(ctx, token, realm, userID)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get brute force status: %v", err))
+			return kcError("failed to get brute force status", err)
 		}
 		return toolResult(status)
 	})
 
 	// 2. clear_brute_force_status
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "clear_brute_force_status",
-		Description: "Clear brute force detection status for a user (re-enable login)",
+		Name:         "clear_brute_force_status",
+		Description:  "Clear brute force detection status for a user (re-enable login)",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Clear brute-force lockout", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args clearBruteForceStatusArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+		}
 
 		// gocloak doesn't expose a ClearBruteForce method, so use raw DELETE.
 		resp, err := kc.GC.GetRequestWithBearerAuth(ctx, token).
-			Delete(fmt.Sprintf("/admin/realms/%s/attack-detection/brute-force/users/%s", realm, args.UserID))
+			Delete(kc.AdminURL(realm, "/attack-detection/brute-force/users/"+userID))
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to clear brute force status: %v", err))
+			return kcError("failed to clear brute force status", err)
 		}
 		if resp.IsError() {
-			return toolError(fmt.Sprintf("failed to clear brute force status: %s", resp.Status()))
+			return kcError("failed to clear brute force status", apiError(resp.StatusCode(), resp.Status(), resp.Body()))
 		}
 		return toolSuccess("Brute force detection status cleared")
 	})
@@ -16,11 +16,13 @@ import (
 
 type listAuthFlowsArgs struct {
 	Realm string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
+	viewArgs
 }
 
 type getAuthFlowArgs struct {
 	Realm  string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
 	FlowID string `json:"flow_id"         jsonschema:"Authentication flow ID"`
+	viewArgs
 }
 
 type createAuthFlowArgs struct {
@@ -40,6 +42,7 @@ type deleteAuthFlowArgs struct {
 type getAuthFlowExecutionsArgs struct {
 	Realm     string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
 	FlowAlias string `json:"flow_alias"      jsonschema:"Alias of the authentication flow"`
+	viewArgs
 }
 
 type updateAuthFlowExecutionArgs struct {
@@ -51,11 +54,13 @@ type updateAuthFlowExecutionArgs struct {
 
 type listRequiredActionsArgs struct {
 	Realm string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
+	viewArgs
 }
 
 type getRequiredActionArgs struct {
 	Realm string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
 	Alias string `json:"alias"           jsonschema:"Alias of the required action"`
+	viewArgs
 }
 
 type updateRequiredActionArgs struct {
@@ -79,39 +84,43 @@ func registerAuthFlowTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 1. list_auth_flows
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "list_auth_flows",
-		Description: "List all authentication flows in a realm",
+		Name:         "list_auth_flows",
+		Description:  "List all authentication flows in a realm",
+		OutputSchema: listSchema[*gocloak.AuthenticationFlowRepresentation](),
+		Annotations:  readOnly("List authentication flows"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args listAuthFlowsArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
 
 		flows, err := kc.GC.GetAuthenticationFlows(ctx, token, realm)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to list authentication flows: %v", err))
+			return kcError("failed to list authentication flows", err)
 		}
 
-		return toolResult(flows)
+		return listResult(flows)
 	})
 
 	// 2. get_auth_flow
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_auth_flow",
-		Description: "Get an authentication flow by ID",
+		Name:         "get_auth_flow",
+		Description:  "Get an authentication flow by ID",
+		OutputSchema: outputSchema[gocloak.AuthenticationFlowRepresentation](),
+		Annotations:  readOnly("Get authentication flow"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getAuthFlowArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
 
 		flow, err := kc.GC.GetAuthenticationFlow(ctx, token, realm, args.FlowID)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get authentication flow %q: %v", args.FlowID, err))
+			return kcError(fmt.Sprintf("failed to get authentication flow %q", args.FlowID), err)
 		}
 
 		return toolResult(flow)
@@ -119,12 +128,14 @@ func registerAuthFlowTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 3. create_auth_flow
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_auth_flow",
-		Description: "Create a new authentication flow in a realm",
+		Name:         "create_auth_flow",
+		Description:  "Create a new authentication flow in a realm",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Create authentication flow", false),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args createAuthFlowArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
@@ -150,59 +161,67 @@ func registerAuthFlowTools(s *mcp.Server, kc *keycloak.Client) {
 
 		err = kc.GC.CreateAuthenticationFlow(ctx, token, realm, flowRep)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to create authentication flow %q: %v", args.Alias, err))
+			return kcError(fmt.Sprintf("failed to create authentication flow %q", args.Alias), err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceRealm)
 
 		return toolSuccess(fmt.Sprintf("Authentication flow %q created successfully", args.Alias))
 	})
 
 	// 4. delete_auth_flow
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_auth_flow",
-		Description: "Delete an authentication flow from a realm",
+		Name:         "delete_auth_flow",
+		Description:  "Delete an authentication flow from a realm",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete authentication flow", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args deleteAuthFlowArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
 
 		if err := kc.GC.DeleteAuthenticationFlow(ctx, token, realm, args.FlowID); err != nil {
-			return toolError(fmt.Sprintf("Error: failed to delete authentication flow %q: %v", args.FlowID, err))
+			return kcError(fmt.Sprintf("failed to delete authentication flow %q", args.FlowID), err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceRealm)
 
 		return toolSuccess(fmt.Sprintf("Authentication flow %q deleted successfully", args.FlowID))
 	})
 
 	// 5. get_auth_flow_executions
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_auth_flow_executions",
-		Description: "Get executions for an authentication flow",
+		Name:         "get_auth_flow_executions",
+		Description:  "Get executions for an authentication flow",
+		OutputSchema: listSchema[*gocloak.ModifyAuthenticationExecutionRepresentation](),
+		Annotations:  readOnly("Get authentication flow executions"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getAuthFlowExecutionsArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
 
 		executions, err := kc.GC.GetAuthenticationExecutions(ctx, token, realm, args.FlowAlias)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get executions for flow %q: %v", args.FlowAlias, err))
+			return kcError(fmt.Sprintf("failed to get executions for flow %q", args.FlowAlias), err)
 		}
 
-		return toolResult(executions)
+		return listResult(executions)
 	})
 
 	// 6. update_auth_flow_execution
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "update_auth_flow_execution",
-		Description: "Update an execution within an authentication flow",
+		Name:         "update_auth_flow_execution",
+		Description:  "Update an execution within an authentication flow",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Update authentication flow execution", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args updateAuthFlowExecutionArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
@@ -213,47 +232,52 @@ func registerAuthFlowTools(s *mcp.Server, kc *keycloak.Client) {
 		}
 
 		if err := kc.GC.UpdateAuthenticationExecution(ctx, token, realm, args.FlowAlias, execution); err != nil {
-			return toolError(fmt.Sprintf("Error: failed to update execution %q in flow %q: %v", args.ExecutionID, args.FlowAlias, err))
+			return kcError(fmt.Sprintf("failed to update execution %q in flow %q", args.ExecutionID, args.FlowAlias), err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceRealm)
 
 		return toolSuccess(fmt.Sprintf("Execution %q updated to %q in flow %q", args.ExecutionID, args.Requirement, args.FlowAlias))
 	})
 
 	// 7. list_required_actions
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "list_required_actions",
-		Description: "List all required actions in a realm",
+		Name:         "list_required_actions",
+		Description:  "List all required actions in a realm",
+		OutputSchema: listSchema[*gocloak.RequiredActionProviderRepresentation](),
+		Annotations:  readOnly("List required actions"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args listRequiredActionsArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
 
 		actions, err := kc.GC.GetRequiredActions(ctx, token, realm)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to list required actions: %v", err))
+			return kcError("failed to list required actions", err)
 		}
 
-		return toolResult(actions)
+		return listResult(actions)
 	})
 
 	// 8. get_required_action
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_required_action",
-		Description: "Get a required action by alias",
+		Name:         "get_required_action",
+		Description:  "Get a required action by alias",
+		OutputSchema: outputSchema[gocloak.RequiredActionProviderRepresentation](),
+		Annotations:  readOnly("Get required action"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getRequiredActionArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
 
 		action, err := kc.GC.GetRequiredAction(ctx, token, realm, args.Alias)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get required action %q: %v", args.Alias, err))
+			return kcError(fmt.Sprintf("failed to get required action %q", args.Alias), err)
 		}
 
 		return toolResult(action)
@@ -261,12 +285,14 @@ func registerAuthFlowTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 9. update_required_action
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "update_required_action",
-		Description: "Update a required action in a realm",
+		Name:         "update_required_action",
+		Description:  "Update a required action in a realm",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Update required action", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args updateRequiredActionArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
@@ -274,7 +300,7 @@ func registerAuthFlowTools(s *mcp.Server, kc *keycloak.Client) {
 		// Fetch the existing required action to apply partial updates.
 		action, err := kc.GC.GetRequiredAction(ctx, token, realm, args.Alias)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get required action %q for update: %v", args.Alias, err))
+			return kcError(fmt.Sprintf("failed to get required action %q for update", args.Alias), err)
 		}
 
 		if args.Name != nil {
@@ -288,27 +314,31 @@ func registerAuthFlowTools(s *mcp.Server, kc *keycloak.Client) {
 		}
 
 		if err := kc.GC.UpdateRequiredAction(ctx, token, realm, *action); err != nil {
-			return toolError(fmt.Sprintf("Error: failed to update required action %q: %v", args.Alias, err))
+			return kcError(fmt.Sprintf("failed to update required action %q", args.Alias), err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceRealm)
 
 		return toolSuccess(fmt.Sprintf("Required action %q updated successfully", args.Alias))
 	})
 
 	// 10. delete_required_action
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_required_action",
-		Description: "Delete a required action from a realm",
+		Name:         "delete_required_action",
+		Description:  "Delete a required action from a realm",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete required action", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args deleteRequiredActionArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
 
 		if err := kc.GC.DeleteRequiredAction(ctx, token, realm, args.Alias); err != nil {
-			return toolError(fmt.Sprintf("Error: failed to delete required action %q: %v", args.Alias, err))
+			return kcError(fmt.Sprintf("failed to delete required action %q", args.Alias), err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceRealm)
 
 		return toolSuccess(fmt.Sprintf("Required action %q deleted successfully", args.Alias))
 	})
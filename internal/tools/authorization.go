@@ -16,27 +16,30 @@ import (
 
 type getResourceServerArgs struct {
 	Realm    string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	ClientID string `json:"client_id"       jsonschema:"Internal client UUID"`
+	ClientID string `json:"client_id"       jsonschema:"Client UUID or clientId"`
+	viewArgs
 }
 
 type listResourcesArgs struct {
 	Realm    string `json:"realm,omitempty"     jsonschema:"Realm name (uses default if omitted)"`
-	ClientID string `json:"client_id"           jsonschema:"Internal client UUID"`
+	ClientID string `json:"client_id"           jsonschema:"Client UUID or clientId"`
 	Name     string `json:"name,omitempty"      jsonschema:"Filter by resource name"`
 	URI      string `json:"uri,omitempty"       jsonschema:"Filter by resource URI"`
 	First    *int   `json:"first,omitempty"     jsonschema:"Pagination offset"`
 	Max      *int   `json:"max,omitempty"       jsonschema:"Maximum number of results"`
+	viewArgs
 }
 
 type getResourceArgs struct {
 	Realm      string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	ClientID   string `json:"client_id"       jsonschema:"Internal client UUID"`
+	ClientID   string `json:"client_id"       jsonschema:"Client UUID or clientId"`
 	ResourceID string `json:"resource_id"     jsonschema:"Resource ID"`
+	viewArgs
 }
 
 type createResourceArgs struct {
 	Realm       string   `json:"realm,omitempty"        jsonschema:"Realm name (uses default if omitted)"`
-	ClientID    string   `json:"client_id"              jsonschema:"Internal client UUID"`
+	ClientID    string   `json:"client_id"              jsonschema:"Client UUID or clientId"`
 	Name        string   `json:"name"                   jsonschema:"Resource name"`
 	DisplayName string   `json:"display_name,omitempty" jsonschema:"Human-friendly display name"`
 	URIs        []string `json:"uris,omitempty"         jsonschema:"List of URIs protected by this resource"`
@@ -46,7 +49,7 @@ type createResourceArgs struct {
 
 type updateResourceArgs struct {
 	Realm       string   `json:"realm,omitempty"        jsonschema:"Realm name (uses default if omitted)"`
-	ClientID    string   `json:"client_id"              jsonschema:"Internal client UUID"`
+	ClientID    string   `json:"client_id"              jsonschema:"Client UUID or clientId"`
 	ResourceID  string   `json:"resource_id"            jsonschema:"Resource ID"`
 	Name        *string  `json:"name,omitempty"         jsonschema:"New resource name"`
 	DisplayName *string  `json:"display_name,omitempty" jsonschema:"New display name"`
@@ -55,48 +58,51 @@ type updateResourceArgs struct {
 
 type deleteResourceArgs struct {
 	Realm      string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	ClientID   string `json:"client_id"       jsonschema:"Internal client UUID"`
+	ClientID   string `json:"client_id"       jsonschema:"Client UUID or clientId"`
 	ResourceID string `json:"resource_id"     jsonschema:"Resource ID"`
 }
 
 type listAuthScopesArgs struct {
 	Realm    string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	ClientID string `json:"client_id"       jsonschema:"Internal client UUID"`
+	ClientID string `json:"client_id"       jsonschema:"Client UUID or clientId"`
 	Name     string `json:"name,omitempty"  jsonschema:"Filter by scope name"`
 	First    *int   `json:"first,omitempty" jsonschema:"Pagination offset"`
 	Max      *int   `json:"max,omitempty"   jsonschema:"Maximum number of results"`
+	viewArgs
 }
 
 type createAuthScopeArgs struct {
 	Realm       string `json:"realm,omitempty"        jsonschema:"Realm name (uses default if omitted)"`
-	ClientID    string `json:"client_id"              jsonschema:"Internal client UUID"`
+	ClientID    string `json:"client_id"              jsonschema:"Client UUID or clientId"`
 	Name        string `json:"name"                   jsonschema:"Scope name"`
 	DisplayName string `json:"display_name,omitempty" jsonschema:"Human-friendly display name"`
 }
 
 type deleteAuthScopeArgs struct {
 	Realm    string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	ClientID string `json:"client_id"       jsonschema:"Internal client UUID"`
+	ClientID string `json:"client_id"       jsonschema:"Client UUID or clientId"`
 	ScopeID  string `json:"scope_id"        jsonschema:"Scope ID"`
 }
 
 type listPoliciesArgs struct {
 	Realm    string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	ClientID string `json:"client_id"       jsonschema:"Internal client UUID"`
+	ClientID string `json:"client_id"       jsonschema:"Client UUID or clientId"`
 	Name     string `json:"name,omitempty"  jsonschema:"Filter by policy name"`
 	First    *int   `json:"first,omitempty" jsonschema:"Pagination offset"`
 	Max      *int   `json:"max,omitempty"   jsonschema:"Maximum number of results"`
+	viewArgs
 }
 
 type getPolicyArgs struct {
 	Realm    string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	ClientID string `json:"client_id"       jsonschema:"Internal client UUID"`
+	ClientID string `json:"client_id"       jsonschema:"Client UUID or clientId"`
 	PolicyID string `json:"policy_id"       jsonschema:"Policy ID"`
+	viewArgs
 }
 
 type createPolicyArgs struct {
 	Realm       string            `json:"realm,omitempty"       jsonschema:"Realm name (uses default if omitted)"`
-	ClientID    string            `json:"client_id"             jsonschema:"Internal client UUID"`
+	ClientID    string            `json:"client_id"             jsonschema:"Client UUID or clientId"`
 	Name        string            `json:"name"                  jsonschema:"Policy name"`
 	Type        string            `json:"type"                  jsonschema:"Policy type (e.g. role\\, user\\, client\\, js)"`
 	Logic       string            `json:"logic,omitempty"       jsonschema:"Policy logic: POSITIVE or NEGATIVE"`
@@ -106,21 +112,22 @@ type createPolicyArgs struct {
 
 type deletePolicyArgs struct {
 	Realm    string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	ClientID string `json:"client_id"       jsonschema:"Internal client UUID"`
+	ClientID string `json:"client_id"       jsonschema:"Client UUID or clientId"`
 	PolicyID string `json:"policy_id"       jsonschema:"Policy ID"`
 }
 
 type listPermissionsArgs struct {
 	Realm    string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	ClientID string `json:"client_id"       jsonschema:"Internal client UUID"`
+	ClientID string `json:"client_id"       jsonschema:"Client UUID or clientId"`
 	Name     string `json:"name,omitempty"  jsonschema:"Filter by permission name"`
 	First    *int   `json:"first,omitempty" jsonschema:"Pagination offset"`
 	Max      *int   `json:"max,omitempty"   jsonschema:"Maximum number of results"`
+	viewArgs
 }
 
 type createPermissionArgs struct {
 	Realm            string   `json:"realm,omitempty"             jsonschema:"Realm name (uses default if omitted)"`
-	ClientID         string   `json:"client_id"                   jsonschema:"Internal client UUID"`
+	ClientID         string   `json:"client_id"                   jsonschema:"Client UUID or clientId"`
 	Name             string   `json:"name"                        jsonschema:"Permission name"`
 	Type             string   `json:"type"                        jsonschema:"Permission type: resource or scope"`
 	Description      string   `json:"description,omitempty"       jsonschema:"Permission description"`
@@ -138,32 +145,44 @@ func registerAuthorizationTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 1. get_resource_server
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_resource_server",
-		Description: "Get the authorization resource server settings for a client",
+		Name:         "get_resource_server",
+		Description:  "Get the authorization resource server settings for a client",
+		OutputSchema: outputSchema[gocloak.ResourceServerRepresentation](),
+		Annotations:  readOnly("Get resource server"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getResourceServerArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
 
-		rs, err := kc.GC.GetResourceServer(ctx, token, realm, args.ClientID)
+		rs, err := kc.GC.GetResourceServer(ctx, token, realm, idOfClient)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get resource server: %v", err))
+			return kcError("failed to get resource server", err)
 		}
 		return toolResult(rs)
 	})
 
 	// 2. list_resources
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "list_resources",
-		Description: "List authorization resources for a client",
+		Name:         "list_resources",
+		Description:  "List authorization resources for a client",
+		OutputSchema: listSchema[*gocloak.ResourceRepresentation](),
+		Annotations:  readOnly("List resources"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args listResourcesArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
 
 		params := gocloak.GetResourceParams{
 			First: args.First,
@@ -176,41 +195,53 @@ func registerAuthorizationTools(s *mcp.Server, kc *keycloak.Client) {
 			params.URI = gocloak.StringP(args.URI)
 		}
 
-		resources, err := kc.GC.GetResources(ctx, token, realm, args.ClientID, params)
+		resources, err := kc.GC.GetResources(ctx, token, realm, idOfClient, params)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to list resources: %v", err))
+			return kcError("failed to list resources", err)
 		}
-		return toolResult(resources)
+		return listResult(resources)
 	})
 
 	// 3. get_resource
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_resource",
-		Description: "Get an authorization resource by ID",
+		Name:         "get_resource",
+		Description:  "Get an authorization resource by ID",
+		OutputSchema: outputSchema[gocloak.ResourceRepresentation](),
+		Annotations:  readOnly("Get resource"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getResourceArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
 
-		resource, err := kc.GC.GetResource(ctx, token, realm, args.ClientID, args.ResourceID)
+		resource, err := kc.GC.GetResource(ctx, token, realm, idOfClient, args.ResourceID)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get resource %q: %v", args.ResourceID, err))
+			return kcError(fmt.Sprintf("failed to get resource %q", args.ResourceID), err)
 		}
 		return toolResult(resource)
 	})
 
 	// 4. create_resource
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_resource",
-		Description: "Create an authorization resource for a client",
+		Name:         "create_resource",
+		Description:  "Create an authorization resource for a client",
+		OutputSchema: outputSchema[gocloak.ResourceRepresentation](),
+		Annotations:  additive("Create resource", false),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args createResourceArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
 
 		resource := gocloak.ResourceRepresentation{
 			Name: gocloak.StringP(args.Name),
@@ -234,27 +265,33 @@ func registerAuthorizationTools(s *mcp.Server, kc *keycloak.Client) {
 			resource.Scopes = &scopes
 		}
 
-		created, err := kc.GC.CreateResource(ctx, token, realm, args.ClientID, resource)
+		created, err := kc.GC.CreateResource(ctx, token, realm, idOfClient, resource)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to create resource: %v", err))
+			return kcError("failed to create resource", err)
 		}
 		return toolResult(created)
 	})
 
 	// 5. update_resource
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "update_resource",
-		Description: "Update an authorization resource",
+		Name:         "update_resource",
+		Description:  "Update an authorization resource",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Update resource", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args updateResourceArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
 
-		existing, err := kc.GC.GetResource(ctx, token, realm, args.ClientID, args.ResourceID)
+		existing, err := kc.GC.GetResource(ctx, token, realm, idOfClient, args.ResourceID)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get resource %q for update: %v", args.ResourceID, err))
+			return kcError(fmt.Sprintf("failed to get resource %q for update", args.ResourceID), err)
 		}
 
 		if args.Name != nil {
@@ -267,39 +304,51 @@ func registerAuthorizationTools(s *mcp.Server, kc *keycloak.Client) {
 			existing.URIs = &args.URIs
 		}
 
-		if err := kc.GC.UpdateResource(ctx, token, realm, args.ClientID, *existing); err != nil {
-			return toolError(fmt.Sprintf("Error: failed to update resource %q: %v", args.ResourceID, err))
+		if err := kc.GC.UpdateResource(ctx, token, realm, idOfClient, *existing); err != nil {
+			return kcError(fmt.Sprintf("failed to update resource %q", args.ResourceID), err)
 		}
 		return toolSuccess(fmt.Sprintf("Resource %q updated successfully", args.ResourceID))
 	})
 
 	// 6. delete_resource
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_resource",
-		Description: "Delete an authorization resource",
+		Name:         "delete_resource",
+		Description:  "Delete an authorization resource",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete resource", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args deleteResourceArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
 
-		if err := kc.GC.DeleteResource(ctx, token, realm, args.ClientID, args.ResourceID); err != nil {
-			return toolError(fmt.Sprintf("Error: failed to delete resource %q: %v", args.ResourceID, err))
+		if err := kc.GC.DeleteResource(ctx, token, realm, idOfClient, args.ResourceID); err != nil {
+			return kcError(fmt.Sprintf("failed to delete resource %q", args.ResourceID), err)
 		}
 		return toolSuccess(fmt.Sprintf("Resource %q deleted successfully", args.ResourceID))
 	})
 
 	// 7. list_auth_scopes
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "list_auth_scopes",
-		Description: "List authorization scopes for a client",
+		Name:         "list_auth_scopes",
+		Description:  "List authorization scopes for a client",
+		OutputSchema: listSchema[*gocloak.ScopeRepresentation](),
+		Annotations:  readOnly("List authorization scopes"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args listAuthScopesArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
 
 		params := gocloak.GetScopeParams{
 			First: args.First,
@@ -309,23 +358,29 @@ func registerAuthorizationTools(s *mcp.Server, kc *keycloak.Client) {
 			params.Name = gocloak.StringP(args.Name)
 		}
 
-		scopes, err := kc.GC.GetScopes(ctx, token, realm, args.ClientID, params)
+		scopes, err := kc.GC.GetScopes(ctx, token, realm, idOfClient, params)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to list authorization scopes: %v", err))
+			return kcError("failed to list authorization scopes", err)
 		}
-		return toolResult(scopes)
+		return listResult(scopes)
 	})
 
 	// 8. create_auth_scope
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_auth_scope",
-		Description: "Create an authorization scope for a client",
+		Name:         "create_auth_scope",
+		Description:  "Create an authorization scope for a client",
+		OutputSchema: outputSchema[gocloak.ScopeRepresentation](),
+		Annotations:  additive("Create authorization scope", false),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args createAuthScopeArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
 
 		scope := gocloak.ScopeRepresentation{
 			Name: gocloak.StringP(args.Name),
@@ -334,40 +389,52 @@ func registerAuthorizationTools(s *mcp.Server, kc *keycloak.Client) {
 			scope.DisplayName = gocloak.StringP(args.DisplayName)
 		}
 
-		created, err := kc.GC.CreateScope(ctx, token, realm, args.ClientID, scope)
+		created, err := kc.GC.CreateScope(ctx, token, realm, idOfClient, scope)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to create authorization scope: %v", err))
+			return kcError("failed to create authorization scope", err)
 		}
 		return toolResult(created)
 	})
 
 	// 9. delete_auth_scope
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_auth_scope",
-		Description: "Delete an authorization scope",
+		Name:         "delete_auth_scope",
+		Description:  "Delete an authorization scope",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete authorization scope", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args deleteAuthScopeArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
 
-		if err := kc.GC.DeleteScope(ctx, token, realm, args.ClientID, args.ScopeID); err != nil {
-			return toolError(fmt.Sprintf("Error: failed to delete scope %q: %v", args.ScopeID, err))
+		if err := kc.GC.DeleteScope(ctx, token, realm, idOfClient, args.ScopeID); err != nil {
+			return kcError(fmt.Sprintf("failed to delete scope %q", args.ScopeID), err)
 		}
 		return toolSuccess(fmt.Sprintf("Authorization scope %q deleted successfully", args.ScopeID))
 	})
 
 	// 10. list_policies
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "list_policies",
-		Description: "List authorization policies for a client",
+		Name:         "list_policies",
+		Description:  "List authorization policies for a client",
+		OutputSchema: listSchema[*gocloak.PolicyRepresentation](),
+		Annotations:  readOnly("List policies"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args listPoliciesArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
 
 		params := gocloak.GetPolicyParams{
 			First: args.First,
@@ -377,41 +444,53 @@ func registerAuthorizationTools(s *mcp.Server, kc *keycloak.Client) {
 			params.Name = gocloak.StringP(args.Name)
 		}
 
-		policies, err := kc.GC.GetPolicies(ctx, token, realm, args.ClientID, params)
+		policies, err := kc.GC.GetPolicies(ctx, token, realm, idOfClient, params)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to list policies: %v", err))
+			return kcError("failed to list policies", err)
 		}
-		return toolResult(policies)
+		return listResult(policies)
 	})
 
 	// 11. get_policy
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_policy",
-		Description: "Get an authorization policy by ID",
+		Name:         "get_policy",
+		Description:  "Get an authorization policy by ID",
+		OutputSchema: outputSchema[gocloak.PolicyRepresentation](),
+		Annotations:  readOnly("Get policy"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getPolicyArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
 
-		policy, err := kc.GC.GetPolicy(ctx, token, realm, args.ClientID, args.PolicyID)
+		policy, err := kc.GC.GetPolicy(ctx, token, realm, idOfClient, args.PolicyID)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get policy %q: %v", args.PolicyID, err))
+			return kcError(fmt.Sprintf("failed to get policy %q", args.PolicyID), err)
 		}
 		return toolResult(policy)
 	})
 
 	// 12. create_policy
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_policy",
-		Description: "Create an authorization policy for a client",
+		Name:         "create_policy",
+		Description:  "Create an authorization policy for a client",
+		OutputSchema: outputSchema[gocloak.PolicyRepresentation](),
+		Annotations:  additive("Create policy", false),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args createPolicyArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
 
 		policy := gocloak.PolicyRepresentation{
 			Name: gocloak.StringP(args.Name),
@@ -428,40 +507,52 @@ func registerAuthorizationTools(s *mcp.Server, kc *keycloak.Client) {
 			policy.Config = &args.Config
 		}
 
-		created, err := kc.GC.CreatePolicy(ctx, token, realm, args.ClientID, policy)
+		created, err := kc.GC.CreatePolicy(ctx, token, realm, idOfClient, policy)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to create policy: %v", err))
+			return kcError("failed to create policy", err)
 		}
 		return toolResult(created)
 	})
 
 	// 13. delete_policy
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_policy",
-		Description: "Delete an authorization policy",
+		Name:         "delete_policy",
+		Description:  "Delete an authorization policy",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete policy", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args deletePolicyArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
 
-		if err := kc.GC.DeletePolicy(ctx, token, realm, args.ClientID, args.PolicyID); err != nil {
-			return toolError(fmt.Sprintf("Error: failed to delete policy %q: %v", args.PolicyID, err))
+		if err := kc.GC.DeletePolicy(ctx, token, realm, idOfClient, args.PolicyID); err != nil {
+			return kcError(fmt.Sprintf("failed to delete policy %q", args.PolicyID), err)
 		}
 		return toolSuccess(fmt.Sprintf("Policy %q deleted successfully", args.PolicyID))
 	})
 
 	// 14. list_permissions
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "list_permissions",
-		Description: "List authorization permissions for a client",
+		Name:         "list_permissions",
+		Description:  "List authorization permissions for a client",
+		OutputSchema: listSchema[*gocloak.PermissionRepresentation](),
+		Annotations:  readOnly("List permissions"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args listPermissionsArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
 
 		params := gocloak.GetPermissionParams{
 			First: args.First,
@@ -471,23 +562,29 @@ func registerAuthorizationTools(s *mcp.Server, kc *keycloak.Client) {
 			params.Name = gocloak.StringP(args.Name)
 		}
 
-		permissions, err := kc.GC.GetPermissions(ctx, token, realm, args.ClientID, params)
+		permissions, err := kc.GC.GetPermissions(ctx, token, realm, idOfClient, params)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to list permissions: %v", err))
+			return kcError("failed to list permissions", err)
 		}
-		return toolResult(permissions)
+		return listResult(permissions)
 	})
 
 	// 15. create_permission
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_permission",
-		Description: "Create an authorization permission for a client",
+		Name:         "create_permission",
+		Description:  "Create an authorization permission for a client",
+		OutputSchema: outputSchema[gocloak.PermissionRepresentation](),
+		Annotations:  additive("Create permission", false),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args createPermissionArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
 
 		permission := gocloak.PermissionRepresentation{
 			Name: gocloak.StringP(args.Name),
@@ -510,9 +607,9 @@ func registerAuthorizationTools(s *mcp.Server, kc *keycloak.Client) {
 			permission.DecisionStrategy = gocloak.DecisionStrategyP(ds)
 		}
 
-		created, err := kc.GC.CreatePermission(ctx, token, realm, args.ClientID, permission)
+		created, err := kc.GC.CreatePermission(ctx, token, realm, idOfClient, permission)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to create permission: %v", err))
+			return kcError("failed to create permission", err)
 		}
 		return toolResult(created)
 	})
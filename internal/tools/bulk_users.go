@@ -0,0 +1,367 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/workers"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Actions applied by bulk_update_users.
+const (
+	bulkEnable             = "enable"
+	bulkDisable            = "disable"
+	bulkAddToGroup         = "add_to_group"
+	bulkRemoveFromGroup    = "remove_from_group"
+	bulkAddRoles           = "add_roles"
+	bulkRemoveRoles        = "remove_roles"
+	bulkSetRequiredActions = "set_required_actions"
+	bulkLogout             = "logout"
+)
+
+// Outcomes of bulk_update_users for each selected user.
+const (
+	bulkSelected  = "selected"  // preview: the user would be updated
+	bulkUpdated   = "updated"   // the action was applied
+	bulkFailed    = "failed"    // Keycloak rejected the action
+	bulkCancelled = "cancelled" // the call was cancelled before reaching the user
+)
+
+// userSelector selects the users of a bulk operation: the members of a
+// group, the holders of a role, or the users matching a search. The search
+// fields combine; group and role stand alone.
+type userSelector struct {
+	Search       string `json:"search,omitempty"         jsonschema:"Select users whose username, email or name contains this text"`
+	Email        string `json:"email,omitempty"          jsonschema:"Select users whose email contains this text, e.g. @contractor.com"`
+	Attribute    string `json:"attribute,omitempty"      jsonschema:"Select users by attribute, as key:value; several terms separated by spaces must all match"`
+	Group        string `json:"group,omitempty"          jsonschema:"Select the members of this group (ID, path or unique name)"`
+	Role         string `json:"role,omitempty"           jsonschema:"Select the users granted this role directly: a realm role, or a client role with role_client_id"`
+	RoleClientID string `json:"role_client_id,omitempty" jsonschema:"Client (UUID or clientId) whose role selects users"`
+}
+
+type bulkUpdateUsersArgs struct {
+	Realm string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
+	userSelector
+	Action          string   `json:"action"                     jsonschema:"enable, disable, add_to_group, remove_from_group, add_roles, remove_roles, set_required_actions or logout"`
+	TargetGroup     string   `json:"target_group,omitempty"     jsonschema:"Group for add_to_group and remove_from_group (ID, path or unique name)"`
+	Roles           []string `json:"roles,omitempty"            jsonschema:"Role names for add_roles and remove_roles"`
+	ClientID        string   `json:"client_id,omitempty"        jsonschema:"Client (UUID or clientId) of the roles for add_roles and remove_roles; realm roles if omitted"`
+	RequiredActions []string `json:"required_actions,omitempty" jsonschema:"Required actions for set_required_actions; they replace the users' current ones"`
+	Preview         bool     `json:"preview,omitempty"          jsonschema:"Only list the selected users"`
+	Concurrency     int      `json:"concurrency,omitempty"      jsonschema:"Users updated at once (default 4, max 16)"`
+}
+
+// bulkReport is the outcome of bulk_update_users, with one entry per user.
+type bulkReport struct {
+	Realm     string           `json:"realm"`
+	Action    string           `json:"action"`
+	Preview   bool             `json:"preview,omitempty"`
+	Matched   int              `json:"matched"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Cancelled int              `json:"cancelled"`
+	Users     []bulkUserResult `json:"users"`
+}
+
+type bulkUserResult struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email,omitempty"`
+	Status   string `json:"status"` // one of the bulk* outcomes
+	Error    string `json:"error,omitempty"`
+}
+
+// bulkAction applies the action to one user.
+type bulkAction func(ctx context.Context, token string, user *gocloak.User) error
+
+func registerBulkUserTools(s *mcp.Server, kc *keycloak.Client) {
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "bulk_update_users",
+		Description: "Apply one action to every user selected by search, email, attribute, group membership or role: " +
+			"enable, disable, add_to_group, remove_from_group, add_roles, remove_roles, set_required_actions or logout. " +
+			"Use preview to list the selected users first. Returns the outcome for every user",
+		OutputSchema: outputSchema[bulkReport](),
+		Annotations:  destructive("Bulk update users", true),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args bulkUpdateUsersArgs) (*mcp.CallToolResult, any, error) {
+		token, err := kc.Token(ctx)
+		if err != nil {
+			return tokenError(err)
+		}
+		realm := kc.ResolveRealm(args.Realm)
+
+		// Check the action before selecting anyone, so that mistakes cost nothing.
+		apply, err := bulkActionFor(ctx, kc, token, realm, args)
+		if err != nil {
+			return kcError("failed to prepare "+args.Action, err)
+		}
+		users, err := selectUsers(ctx, kc, token, realm, args.userSelector)
+		if err != nil {
+			return pageError("failed to select users", err)
+		}
+		if confirmed, ok := confirmedIDs(ctx); ok && !slices.Equal(confirmed, userIDs(users)) {
+			return toolError(&toolErr{
+				Code:    codeConflict,
+				Message: fmt.Sprintf("the selection changed after it was confirmed: %d users were confirmed, %d match now", len(confirmed), len(users)),
+				Hint:    "No user was changed. Call bulk_update_users again so that the user can confirm the current selection.",
+			})
+		}
+
+		report := &bulkReport{Realm: realm, Action: args.Action, Preview: args.Preview, Matched: len(users), Users: make([]bulkUserResult, len(users))}
+		for i, u := range users {
+			report.Users[i] = bulkUserResult{ID: gocloak.PString(u.ID), Username: gocloak.PString(u.Username), Email: gocloak.PString(u.Email), Status: bulkSelected}
+		}
+		if args.Preview {
+			return toolResult(report)
+		}
+
+		skipped := workers.Run(ctx, len(users), args.Concurrency, func(i int) {
+			r := &report.Users[i]
+			// Long runs outlive an access token; Token refreshes it when needed.
+			token, err := kc.Token(ctx)
+			if err == nil {
+				err = apply(ctx, token, users[i])
+			}
+			switch {
+			case err == nil:
+				r.Status = bulkUpdated
+			case ctx.Err() != nil:
+				r.Status = bulkCancelled
+			default:
+				r.Status = bulkFailed
+				r.Error = classifyError(args.Action+" failed", err).Message
+			}
+		}, func(done int) {
+			reportProgress(ctx, done, len(users), fmt.Sprintf("%s: %d of %d users", args.Action, done, len(users)))
+		})
+		for _, i := range skipped {
+			report.Users[i].Status = bulkCancelled
+		}
+
+		for _, r := range report.Users {
+			switch r.Status {
+			case bulkUpdated:
+				report.Succeeded++
+			case bulkFailed:
+				report.Failed++
+			case bulkCancelled:
+				report.Cancelled++
+			}
+		}
+		if report.Failed > 0 {
+			log.Ctx(ctx).Warn().Str("realm", realm).Str("action", args.Action).Int("failed", report.Failed).Int("succeeded", report.Succeeded).
+				Msg("bulk user update finished with failures")
+		}
+		return toolResult(report)
+	})
+}
+
+// userIDs returns the IDs of users, sorted.
+func userIDs(users []*gocloak.User) []string {
+	ids := make([]string, len(users))
+	for i, u := range users {
+		ids[i] = gocloak.PString(u.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// selectUsers returns the users chosen by sel, up to the server's result cap.
+// A selection larger than the cap is refused rather than silently cut short.
+func selectUsers(ctx context.Context, kc *keycloak.Client, token, realm string, sel userSelector) ([]*gocloak.User, error) {
+	search := sel.Search != "" || sel.Email != "" || sel.Attribute != ""
+	var fetch func(first, max int) ([]*gocloak.User, error)
+	switch {
+	case sel.Group != "" && (sel.Role != "" || search), sel.Role != "" && search:
+		return nil, validationErr("select users by group, by role or by search, email and attribute, not a combination")
+	case sel.Group != "":
+		groupID, err := kc.ResolveGroupID(ctx, token, realm, sel.Group)
+		if err != nil {
+			return nil, err
+		}
+		fetch = func(first, max int) ([]*gocloak.User, error) {
+			return kc.GC.GetGroupMembers(ctx, token, realm, groupID, gocloak.GetGroupsParams{First: &first, Max: &max})
+		}
+	case sel.Role != "" && sel.RoleClientID != "":
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, sel.RoleClientID)
+		if err != nil {
+			return nil, err
+		}
+		fetch = func(first, max int) ([]*gocloak.User, error) {
+			return kc.GC.GetUsersByClientRoleName(ctx, token, realm, idOfClient, sel.Role, gocloak.GetUsersByRoleParams{First: &first, Max: &max})
+		}
+	case sel.Role != "":
+		fetch = func(first, max int) ([]*gocloak.User, error) {
+			return kc.GC.GetUsersByRoleName(ctx, token, realm, sel.Role, gocloak.GetUsersByRoleParams{First: &first, Max: &max})
+		}
+	case search:
+		params := gocloak.GetUsersParams{}
+		if sel.Search != "" {
+			params.Search = gocloak.StringP(sel.Search)
+		}
+		if sel.Email != "" {
+			params.Email = gocloak.StringP(sel.Email)
+		}
+		if sel.Attribute != "" {
+			params.Q = gocloak.StringP(sel.Attribute)
+		}
+		fetch = func(first, max int) ([]*gocloak.User, error) {
+			p := params
+			p.First, p.Max = &first, &max
+			return kc.GC.GetUsers(ctx, token, realm, p)
+		}
+	default:
+		return nil, validationErr("select users with search, email, attribute, group or role")
+	}
+
+	res, err := paginate(ctx, newPageQuery("bulk_update_users", realm), pageArgs{All: true}, nil, kc.MaxResults(), fetch)
+	if err != nil {
+		return nil, err
+	}
+	if res.Truncated {
+		return nil, validationErr(fmt.Sprintf("the selection matches more than %d users (KEYCLOAK_MAX_RESULTS); narrow it", kc.MaxResults()))
+	}
+	return res.Items, nil
+}
+
+// bulkActionFor checks the arguments of the action and resolves its group or
+// roles once for all users.
+func bulkActionFor(ctx context.Context, kc *keycloak.Client, token, realm string, args bulkUpdateUsersArgs) (bulkAction, error) {
+	setEnabled := func(enabled bool) bulkAction {
+		return func(ctx context.Context, token string, u *gocloak.User) error {
+			user, err := kc.GC.GetUserByID(ctx, token, realm, gocloak.PString(u.ID))
+			if err != nil {
+				return err
+			}
+			user.Enabled = gocloak.BoolP(enabled)
+			return kc.GC.UpdateUser(ctx, token, realm, *user)
+		}
+	}
+
+	switch args.Action {
+	case bulkEnable:
+		return setEnabled(true), nil
+	case bulkDisable:
+		return setEnabled(false), nil
+
+	case bulkAddToGroup, bulkRemoveFromGroup:
+		if args.TargetGroup == "" {
+			return nil, validationErr(args.Action + " needs target_group")
+		}
+		groupID, err := kc.ResolveGroupID(ctx, token, realm, args.TargetGroup)
+		if err != nil {
+			return nil, err
+		}
+		if args.Action == bulkAddToGroup {
+			return func(ctx context.Context, token string, u *gocloak.User) error {
+				return kc.GC.AddUserToGroup(ctx, token, realm, gocloak.PString(u.ID), groupID)
+			}, nil
+		}
+		return func(ctx context.Context, token string, u *gocloak.User) error {
+			return kc.GC.DeleteUserFromGroup(ctx, token, realm, gocloak.PString(u.ID), groupID)
+		}, nil
+
+	case bulkAddRoles, bulkRemoveRoles:
+		if len(args.Roles) == 0 {
+			return nil, validationErr(args.Action + " needs roles")
+		}
+		idOfClient := ""
+		if args.ClientID != "" {
+			var err error
+			if idOfClient, err = kc.ResolveClientID(ctx, token, realm, args.ClientID); err != nil {
+				return nil, err
+			}
+		}
+		roles := make([]gocloak.Role, 0, len(args.Roles))
+		for _, name := range args.Roles {
+			var role *gocloak.Role
+			var err error
+			if idOfClient != "" {
+				role, err = kc.ResolveClientRole(ctx, token, realm, idOfClient, name)
+			} else {
+				role, err = kc.ResolveRealmRole(ctx, token, realm, name)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("role %q: %w", name, err)
+			}
+			roles = append(roles, *role)
+		}
+		add := args.Action == bulkAddRoles
+		return func(ctx context.Context, token string, u *gocloak.User) error {
+			userID := gocloak.PString(u.ID)
+			switch {
+			case idOfClient != "" && add:
+				return kc.GC.AddClientRoleToUser(ctx, token, realm, idOfClient, userID, roles)
+			case idOfClient != "":
+				return kc.GC.DeleteClientRoleFromUser(ctx, token, realm, idOfClient, userID, roles)
+			case add:
+				return kc.GC.AddRealmRoleToUser(ctx, token, realm, userID, roles)
+			default:
+				return kc.GC.DeleteRealmRoleFromUser(ctx, token, realm, userID, roles)
+			}
+		}, nil
+
+	case bulkSetRequiredActions:
+		if args.RequiredActions == nil {
+			return nil, validationErr(bulkSetRequiredActions + " needs required_actions; pass [] to clear them")
+		}
+		if err := checkRequiredActions(ctx, kc, token, realm, args.RequiredActions); err != nil {
+			return nil, err
+		}
+		actions := args.RequiredActions
+		return func(ctx context.Context, token string, u *gocloak.User) error {
+			user, err := kc.GC.GetUserByID(ctx, token, realm, gocloak.PString(u.ID))
+			if err != nil {
+				return err
+			}
+			user.RequiredActions = &actions
+			return kc.GC.UpdateUser(ctx, token, realm, *user)
+		}, nil
+
+	case bulkLogout:
+		return func(ctx context.Context, token string, u *gocloak.User) error {
+			return kc.GC.LogoutAllSessions(ctx, token, realm, gocloak.PString(u.ID))
+		}, nil
+	}
+	return nil, validationErr(fmt.Sprintf("unknown action %q", args.Action))
+}
+
+// bulkImpact summarises a bulk_update_users call for confirmation.
+func bulkImpact(ctx context.Context, kc *keycloak.Client, token, realm string, raw map[string]any) (string, error) {
+	var args bulkUpdateUsersArgs
+	b, _ := json.Marshal(raw)
+	if err := json.Unmarshal(b, &args); err != nil {
+		return "", err
+	}
+	users, err := selectUsers(ctx, kc, token, realm, args.userSelector)
+	if err != nil {
+		return "", err
+	}
+	recordConfirmed(ctx, userIDs(users))
+	const shown = 10
+	var names []string
+	for i, u := range users {
+		if i == shown {
+			names = append(names, fmt.Sprintf("and %d more", len(users)-shown))
+			break
+		}
+		names = append(names, gocloak.PString(u.Username))
+	}
+	action := args.Action
+	switch args.Action {
+	case bulkAddToGroup, bulkRemoveFromGroup:
+		action += " " + args.TargetGroup
+	case bulkAddRoles, bulkRemoveRoles:
+		action += " " + strings.Join(args.Roles, ", ")
+	case bulkSetRequiredActions:
+		action += " [" + strings.Join(args.RequiredActions, ", ") + "]"
+	}
+	return fmt.Sprintf("%s will be applied to %d users in realm %s: %s.", action, len(users), realm, strings.Join(names, ", ")), nil
+}
@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestBulkUpdateUsers(t *testing.T) {
+	h := newHarness(t)
+	h.fake.AddGroup("acme", "", "contractors")
+	h.fake.AddRealmRole("acme", "auditor")
+	h.fake.AddUser("acme", "alice", "alice@contractor.com")
+	h.fake.AddUser("acme", "bob", "bob@contractor.com")
+	h.fake.AddUser("acme", "carol", "carol@example.com")
+
+	// A preview selects without changing anyone.
+	var report bulkReport
+	h.okJSON("bulk_update_users", map[string]any{"email": "@contractor.com", "action": "disable", "preview": true}, &report)
+	if report.Matched != 2 || report.Succeeded != 0 || report.Users[0].Status != bulkSelected {
+		t.Fatalf("preview report = %+v", report)
+	}
+	var user struct {
+		Enabled         bool     `json:"enabled"`
+		RequiredActions []string `json:"requiredActions"`
+	}
+	h.okJSON("get_user", map[string]any{"user_id": "alice"}, &user)
+	if !user.Enabled {
+		t.Fatal("preview disabled alice")
+	}
+
+	h.okJSON("bulk_update_users", map[string]any{"email": "@contractor.com", "action": "add_to_group", "target_group": "/contractors", "concurrency": 2}, &report)
+	if report.Matched != 2 || report.Succeeded != 2 || report.Failed != 0 {
+		t.Fatalf("add_to_group report = %+v", report)
+	}
+
+	// Group members are selected on their own.
+	h.okJSON("bulk_update_users", map[string]any{"group": "/contractors", "action": "add_roles", "roles": []string{"auditor"}}, &report)
+	if report.Succeeded != 2 {
+		t.Fatalf("add_roles report = %+v", report)
+	}
+	h.okJSON("bulk_update_users", map[string]any{"role": "auditor", "action": "set_required_actions", "required_actions": []string{"UPDATE_PASSWORD"}}, &report)
+	if report.Succeeded != 2 {
+		t.Fatalf("set_required_actions report = %+v", report)
+	}
+	h.okJSON("bulk_update_users", map[string]any{"role": "auditor", "action": "disable"}, &report)
+	h.okJSON("get_user", map[string]any{"user_id": "bob"}, &user)
+	if user.Enabled || len(user.RequiredActions) != 1 || user.RequiredActions[0] != "UPDATE_PASSWORD" {
+		t.Fatalf("bob = %+v", user)
+	}
+	h.okJSON("get_user", map[string]any{"user_id": "carol"}, &user)
+	if !user.Enabled {
+		t.Fatal("carol was disabled")
+	}
+
+	h.okJSON("bulk_update_users", map[string]any{"group": "contractors", "action": "logout"}, &report)
+	if report.Succeeded != 2 {
+		t.Fatalf("logout report = %+v", report)
+	}
+}
+
+func TestBulkUpdateUsersValidates(t *testing.T) {
+	h := newHarness(t)
+	h.fake.AddUser("acme", "alice", "alice@example.com")
+
+	h.fail("bulk_update_users", map[string]any{"action": "disable"}, codeValidation)
+	h.fail("bulk_update_users", map[string]any{"search": "alice", "group": "/eng", "action": "disable"}, codeValidation)
+	h.fail("bulk_update_users", map[string]any{"search": "alice", "action": "add_roles"}, codeValidation)
+	h.fail("bulk_update_users", map[string]any{"search": "alice", "action": "archive"}, codeValidation)
+	h.fail("bulk_update_users", map[string]any{"search": "alice", "action": "add_to_group", "target_group": "/missing"}, codeNotFound)
+}
+
+func TestBulkUpdateUsersActsOnConfirmedSelection(t *testing.T) {
+	var h *harness
+	joined := false
+	h, asked := newElicitingHarness(t, nil, func(*mcp.ElicitParams) *mcp.ElicitResult {
+		// Another user starts matching while the first question is open.
+		if !joined {
+			h.fake.AddUser("acme", "dave", "dave@contractor.com")
+			joined = true
+		}
+		return &mcp.ElicitResult{Action: "accept"}
+	})
+	h.fake.AddUser("acme", "alice", "alice@contractor.com")
+	args := map[string]any{"email": "@contractor.com", "action": "disable"}
+
+	e := h.fail("bulk_update_users", args, codeConflict)
+	if !strings.Contains(e.Message, "1 users were confirmed, 2 match now") {
+		t.Fatalf("message = %q", e.Message)
+	}
+	var user struct {
+		Enabled bool `json:"enabled"`
+	}
+	for _, name := range []string{"alice", "dave"} {
+		h.okJSON("get_user", map[string]any{"user_id": name}, &user)
+		if !user.Enabled {
+			t.Fatalf("%s was disabled without confirmation", name)
+		}
+	}
+
+	var report bulkReport
+	h.okJSON("bulk_update_users", args, &report)
+	if report.Succeeded != 2 || len(*asked) != 2 || !strings.Contains((*asked)[1], "alice, dave") {
+		t.Fatalf("report = %+v after %q", report, *asked)
+	}
+}
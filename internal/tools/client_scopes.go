@@ -16,12 +16,15 @@ import (
 // ---------------------------------------------------------------------------
 
 type listClientScopesArgs struct {
-	Realm string `json:"realm,omitempty" jsonschema:"The realm name (uses default realm if omitted)"`
+	Realm   string `json:"realm,omitempty" jsonschema:"The realm name (uses default realm if omitted)"`
+	NoCache bool   `json:"no_cache,omitempty" jsonschema:"Bypass the lookup cache and read directly from Keycloak"`
+	viewArgs
 }
 
 type getClientScopeArgs struct {
 	Realm   string `json:"realm,omitempty" jsonschema:"The realm name (uses default realm if omitted)"`
 	ScopeID string `json:"scope_id" jsonschema:"The client scope ID"`
+	viewArgs
 }
 
 type createClientScopeArgs struct {
@@ -48,6 +51,7 @@ type deleteClientScopeArgs struct {
 type listClientScopeProtocolMappersArgs struct {
 	Realm   string `json:"realm,omitempty" jsonschema:"The realm name (uses default realm if omitted)"`
 	ScopeID string `json:"scope_id" jsonschema:"The client scope ID"`
+	viewArgs
 }
 
 type createClientScopeProtocolMapperArgs struct {
@@ -75,6 +79,7 @@ type deleteClientScopeProtocolMapperArgs struct {
 
 type getDefaultClientScopesArgs struct {
 	Realm string `json:"realm,omitempty" jsonschema:"The realm name (uses default realm if omitted)"`
+	viewArgs
 }
 
 // ---------------------------------------------------------------------------
@@ -84,37 +89,41 @@ type getDefaultClientScopesArgs struct {
 func registerClientScopeTools(s *mcp.Server, kc *keycloak.Client) {
 	// 1. list_client_scopes
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "list_client_scopes",
-		Description: "List all client scopes in a Keycloak realm",
+		Name:         "list_client_scopes",
+		Description:  "List all client scopes in a Keycloak realm",
+		OutputSchema: listSchema[*gocloak.ClientScope](),
+		Annotations:  readOnly("List client scopes"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args listClientScopesArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
-		scopes, err := kc.GC.GetClientScopes(ctx, token, realm)
+		scopes, err := kc.GetClientScopes(keycloak.WithNoCache(ctx, args.NoCache), token, realm)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to list client scopes in realm %q: %v", realm, err))
+			return kcError(fmt.Sprintf("failed to list client scopes in realm %q", realm), err)
 		}
 
-		return toolResult(scopes)
+		return listResult(scopes)
 	})
 
 	// 2. get_client_scope
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_client_scope",
-		Description: "Get a client scope by ID",
+		Name:         "get_client_scope",
+		Description:  "Get a client scope by ID",
+		OutputSchema: outputSchema[gocloak.ClientScope](),
+		Annotations:  readOnly("Get client scope"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getClientScopeArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
 		scope, err := kc.GC.GetClientScope(ctx, token, realm, args.ScopeID)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get client scope %q in realm %q: %v", args.ScopeID, realm, err))
+			return kcError(fmt.Sprintf("failed to get client scope %q in realm %q", args.ScopeID, realm), err)
 		}
 
 		return toolResult(scope)
@@ -122,12 +131,14 @@ func registerClientScopeTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 3. create_client_scope
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_client_scope",
-		Description: "Create a new client scope in a Keycloak realm",
+		Name:         "create_client_scope",
+		Description:  "Create a new client scope in a Keycloak realm",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Create client scope", false),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args createClientScopeArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
@@ -153,27 +164,31 @@ func registerClientScopeTools(s *mcp.Server, kc *keycloak.Client) {
 
 		createdID, err := kc.GC.CreateClientScope(ctx, token, realm, scopeRep)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to create client scope %q in realm %q: %v", args.Name, realm, err))
+			return kcError(fmt.Sprintf("failed to create client scope %q in realm %q", args.Name, realm), err)
 		}
 
-		return toolSuccess(fmt.Sprintf("Client scope %q created successfully (id: %s)", args.Name, createdID))
+		kc.Invalidate(realm, keycloak.ResourceClientScopes)
+
+		return toolCreated(createdID, fmt.Sprintf("Client scope %q created successfully (id: %s)", args.Name, createdID))
 	})
 
 	// 4. update_client_scope
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "update_client_scope",
-		Description: "Update an existing client scope in a Keycloak realm",
+		Name:         "update_client_scope",
+		Description:  "Update an existing client scope in a Keycloak realm",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Update client scope", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args updateClientScopeArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
 
 		existing, err := kc.GC.GetClientScope(ctx, token, realm, args.ScopeID)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get client scope %q for update in realm %q: %v", args.ScopeID, realm, err))
+			return kcError(fmt.Sprintf("failed to get client scope %q for update in realm %q", args.ScopeID, realm), err)
 		}
 
 		if args.Name != nil {
@@ -187,57 +202,67 @@ func registerClientScopeTools(s *mcp.Server, kc *keycloak.Client) {
 		}
 
 		if err := kc.GC.UpdateClientScope(ctx, token, realm, *existing); err != nil {
-			return toolError(fmt.Sprintf("Error: failed to update client scope %q in realm %q: %v", args.ScopeID, realm, err))
+			return kcError(fmt.Sprintf("failed to update client scope %q in realm %q", args.ScopeID, realm), err)
 		}
 
+		kc.Invalidate(realm, keycloak.ResourceClientScopes)
+
 		return toolSuccess(fmt.Sprintf("Client scope %q updated successfully", args.ScopeID))
 	})
 
 	// 5. delete_client_scope
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_client_scope",
-		Description: "Delete a client scope from a Keycloak realm",
+		Name:         "delete_client_scope",
+		Description:  "Delete a client scope from a Keycloak realm",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete client scope", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args deleteClientScopeArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
 		if err := kc.GC.DeleteClientScope(ctx, token, realm, args.ScopeID); err != nil {
-			return toolError(fmt.Sprintf("Error: failed to delete client scope %q in realm %q: %v", args.ScopeID, realm, err))
+			return kcError(fmt.Sprintf("failed to delete client scope %q in realm %q", args.ScopeID, realm), err)
 		}
 
+		kc.Invalidate(realm, keycloak.ResourceClientScopes)
+
 		return toolSuccess(fmt.Sprintf("Client scope %q deleted successfully", args.ScopeID))
 	})
 
 	// 6. list_client_scope_protocol_mappers
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "list_client_scope_protocol_mappers",
-		Description: "List all protocol mappers for a client scope",
+		Name:         "list_client_scope_protocol_mappers",
+		Description:  "List all protocol mappers for a client scope",
+		OutputSchema: listSchema[*gocloak.ProtocolMappers](),
+		Annotations:  readOnly("List client scope protocol mappers"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args listClientScopeProtocolMappersArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
 		mappers, err := kc.GC.GetClientScopeProtocolMappers(ctx, token, realm, args.ScopeID)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to list protocol mappers for client scope %q in realm %q: %v", args.ScopeID, realm, err))
+			return kcError(fmt.Sprintf("failed to list protocol mappers for client scope %q in realm %q", args.ScopeID, realm), err)
 		}
 
-		return toolResult(mappers)
+		return listResult(mappers)
 	})
 
 	// 7. create_client_scope_protocol_mapper
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_client_scope_protocol_mapper",
-		Description: "Create a protocol mapper in a client scope",
+		Name:         "create_client_scope_protocol_mapper",
+		Description:  "Create a protocol mapper in a client scope",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Create client scope protocol mapper", false),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args createClientScopeProtocolMapperArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
@@ -256,20 +281,24 @@ func registerClientScopeTools(s *mcp.Server, kc *keycloak.Client) {
 
 		createdID, err := kc.GC.CreateClientScopeProtocolMapper(ctx, token, realm, args.ScopeID, mapper)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to create protocol mapper %q for client scope %q in realm %q: %v", args.Name, args.ScopeID, realm, err))
+			return kcError(fmt.Sprintf("failed to create protocol mapper %q for client scope %q in realm %q", args.Name, args.ScopeID, realm), err)
 		}
 
-		return toolSuccess(fmt.Sprintf("Protocol mapper %q created successfully (id: %s)", args.Name, createdID))
+		kc.Invalidate(realm, keycloak.ResourceClientScopes)
+
+		return toolCreated(createdID, fmt.Sprintf("Protocol mapper %q created successfully (id: %s)", args.Name, createdID))
 	})
 
 	// 8. update_client_scope_protocol_mapper
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "update_client_scope_protocol_mapper",
-		Description: "Update a protocol mapper in a client scope",
+		Name:         "update_client_scope_protocol_mapper",
+		Description:  "Update a protocol mapper in a client scope",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Update client scope protocol mapper", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args updateClientScopeProtocolMapperArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
@@ -277,7 +306,7 @@ func registerClientScopeTools(s *mcp.Server, kc *keycloak.Client) {
 		// Fetch all mappers and find the one to update.
 		mappers, err := kc.GC.GetClientScopeProtocolMappers(ctx, token, realm, args.ScopeID)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get protocol mappers for client scope %q in realm %q: %v", args.ScopeID, realm, err))
+			return kcError(fmt.Sprintf("failed to get protocol mappers for client scope %q in realm %q", args.ScopeID, realm), err)
 		}
 
 		var existing *gocloak.ProtocolMappers
@@ -288,7 +317,7 @@ func registerClientScopeTools(s *mcp.Server, kc *keycloak.Client) {
 			}
 		}
 		if existing == nil {
-			return toolError(fmt.Sprintf("Error: protocol mapper %q not found in client scope %q", args.MapperID, args.ScopeID))
+			return notFoundError(fmt.Sprintf("protocol mapper %q not found in client scope %q", args.MapperID, args.ScopeID))
 		}
 
 		if args.Name != nil {
@@ -302,46 +331,54 @@ func registerClientScopeTools(s *mcp.Server, kc *keycloak.Client) {
 		}
 
 		if err := kc.GC.UpdateClientScopeProtocolMapper(ctx, token, realm, args.ScopeID, *existing); err != nil {
-			return toolError(fmt.Sprintf("Error: failed to update protocol mapper %q in client scope %q in realm %q: %v", args.MapperID, args.ScopeID, realm, err))
+			return kcError(fmt.Sprintf("failed to update protocol mapper %q in client scope %q in realm %q", args.MapperID, args.ScopeID, realm), err)
 		}
 
+		kc.Invalidate(realm, keycloak.ResourceClientScopes)
+
 		return toolSuccess(fmt.Sprintf("Protocol mapper %q updated successfully", args.MapperID))
 	})
 
 	// 9. delete_client_scope_protocol_mapper
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_client_scope_protocol_mapper",
-		Description: "Delete a protocol mapper from a client scope",
+		Name:         "delete_client_scope_protocol_mapper",
+		Description:  "Delete a protocol mapper from a client scope",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete client scope protocol mapper", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args deleteClientScopeProtocolMapperArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
 		if err := kc.GC.DeleteClientScopeProtocolMapper(ctx, token, realm, args.ScopeID, args.MapperID); err != nil {
-			return toolError(fmt.Sprintf("Error: failed to delete protocol mapper %q from client scope %q in realm %q: %v", args.MapperID, args.ScopeID, realm, err))
+			return kcError(fmt.Sprintf("failed to delete protocol mapper %q from client scope %q in realm %q", args.MapperID, args.ScopeID, realm), err)
 		}
 
+		kc.Invalidate(realm, keycloak.ResourceClientScopes)
+
 		return toolSuccess(fmt.Sprintf("Protocol mapper %q deleted from client scope %q successfully", args.MapperID, args.ScopeID))
 	})
 
 	// 10. get_default_client_scopes
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_default_client_scopes",
-		Description: "Get the realm's default client scopes",
+		Name:         "get_default_client_scopes",
+		Description:  "Get the realm's default client scopes",
+		OutputSchema: listSchema[*gocloak.ClientScope](),
+		Annotations:  readOnly("Get default client scopes"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getDefaultClientScopesArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
 		scopes, err := kc.GC.GetDefaultDefaultClientScopes(ctx, token, realm)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get default client scopes for realm %q: %v", realm, err))
+			return kcError(fmt.Sprintf("failed to get default client scopes for realm %q", realm), err)
 		}
 
-		return toolResult(scopes)
+		return listResult(scopes)
 	})
 }
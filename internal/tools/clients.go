@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/Nerzal/gocloak/v13"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/config"
 	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -16,11 +17,14 @@ import (
 type listClientsArgs struct {
 	Realm    string `json:"realm,omitempty"    jsonschema:"Keycloak realm (uses default if omitted)"`
 	ClientID string `json:"client_id,omitempty" jsonschema:"Filter by clientId"`
+	NoCache  bool   `json:"no_cache,omitempty"  jsonschema:"Bypass the lookup cache and read directly from Keycloak"`
+	viewArgs
 }
 
 type getClientArgs struct {
 	Realm string `json:"realm,omitempty" jsonschema:"Keycloak realm (uses default if omitted)"`
-	ID    string `json:"id"             jsonschema:"Internal UUID of the client"`
+	ID    string `json:"id"             jsonschema:"Client UUID or clientId"`
+	viewArgs
 }
 
 type createClientArgs struct {
@@ -41,7 +45,7 @@ type createClientArgs struct {
 
 type updateClientArgs struct {
 	Realm        string            `json:"realm,omitempty"          jsonschema:"Keycloak realm (uses default if omitted)"`
-	ID           string            `json:"id"                       jsonschema:"Internal UUID of the client"`
+	ID           string            `json:"id"                       jsonschema:"Client UUID or clientId"`
 	Name         *string           `json:"name,omitempty"           jsonschema:"Display name"`
 	RootURL      *string           `json:"root_url,omitempty"       jsonschema:"Root URL"`
 	RedirectURIs *[]string         `json:"redirect_uris,omitempty"  jsonschema:"Valid redirect URIs"`
@@ -52,61 +56,64 @@ type updateClientArgs struct {
 
 type deleteClientArgs struct {
 	Realm string `json:"realm,omitempty" jsonschema:"Keycloak realm (uses default if omitted)"`
-	ID    string `json:"id"             jsonschema:"Internal UUID of the client"`
+	ID    string `json:"id"             jsonschema:"Client UUID or clientId"`
 }
 
 type getClientSecretArgs struct {
 	Realm string `json:"realm,omitempty" jsonschema:"Keycloak realm (uses default if omitted)"`
-	ID    string `json:"id"             jsonschema:"Internal UUID of the client"`
+	ID    string `json:"id"             jsonschema:"Client UUID or clientId"`
 }
 
 type regenerateClientSecretArgs struct {
 	Realm string `json:"realm,omitempty" jsonschema:"Keycloak realm (uses default if omitted)"`
-	ID    string `json:"id"             jsonschema:"Internal UUID of the client"`
+	ID    string `json:"id"             jsonschema:"Client UUID or clientId"`
 }
 
 type getClientServiceAccountArgs struct {
 	Realm string `json:"realm,omitempty" jsonschema:"Keycloak realm (uses default if omitted)"`
-	ID    string `json:"id"             jsonschema:"Internal UUID of the client"`
+	ID    string `json:"id"             jsonschema:"Client UUID or clientId"`
+	viewArgs
 }
 
 type getClientDefaultScopesArgs struct {
 	Realm string `json:"realm,omitempty" jsonschema:"Keycloak realm (uses default if omitted)"`
-	ID    string `json:"id"             jsonschema:"Internal UUID of the client"`
+	ID    string `json:"id"             jsonschema:"Client UUID or clientId"`
+	viewArgs
 }
 
 type addClientDefaultScopeArgs struct {
 	Realm   string `json:"realm,omitempty" jsonschema:"Keycloak realm (uses default if omitted)"`
-	ID      string `json:"id"             jsonschema:"Internal UUID of the client"`
+	ID      string `json:"id"             jsonschema:"Client UUID or clientId"`
 	ScopeID string `json:"scope_id"       jsonschema:"ID of the scope to add"`
 }
 
 type removeClientDefaultScopeArgs struct {
 	Realm   string `json:"realm,omitempty" jsonschema:"Keycloak realm (uses default if omitted)"`
-	ID      string `json:"id"             jsonschema:"Internal UUID of the client"`
+	ID      string `json:"id"             jsonschema:"Client UUID or clientId"`
 	ScopeID string `json:"scope_id"       jsonschema:"ID of the scope to remove"`
 }
 
 type getClientOptionalScopesArgs struct {
 	Realm string `json:"realm,omitempty" jsonschema:"Keycloak realm (uses default if omitted)"`
-	ID    string `json:"id"             jsonschema:"Internal UUID of the client"`
+	ID    string `json:"id"             jsonschema:"Client UUID or clientId"`
+	viewArgs
 }
 
 type addClientOptionalScopeArgs struct {
 	Realm   string `json:"realm,omitempty" jsonschema:"Keycloak realm (uses default if omitted)"`
-	ID      string `json:"id"             jsonschema:"Internal UUID of the client"`
+	ID      string `json:"id"             jsonschema:"Client UUID or clientId"`
 	ScopeID string `json:"scope_id"       jsonschema:"ID of the scope to add"`
 }
 
 type removeClientOptionalScopeArgs struct {
 	Realm   string `json:"realm,omitempty" jsonschema:"Keycloak realm (uses default if omitted)"`
-	ID      string `json:"id"             jsonschema:"Internal UUID of the client"`
+	ID      string `json:"id"             jsonschema:"Client UUID or clientId"`
 	ScopeID string `json:"scope_id"       jsonschema:"ID of the scope to remove"`
 }
 
 type createClientProtocolMapperArgs struct {
 	Realm      string            `json:"realm,omitempty"  jsonschema:"Keycloak realm (uses default if omitted)"`
-	ID         string            `json:"id"               jsonschema:"Internal UUID of the client"`
+	ID         string            `json:"id"               jsonschema:"Client UUID or clientId"`
 	Name       string            `json:"name"             jsonschema:"Name of the protocol mapper"`
 	Protocol   string            `json:"protocol"         jsonschema:"Protocol (e.g. openid-connect)"`
 	MapperType string            `json:"mapper_type"      jsonschema:"Protocol mapper type (e.g. oidc-usermodel-attribute-mapper)"`
@@ -115,7 +122,7 @@ type createClientProtocolMapperArgs struct {
 
 type updateClientProtocolMapperArgs struct {
 	Realm    string            `json:"realm,omitempty"  jsonschema:"Keycloak realm (uses default if omitted)"`
-	ID       string            `json:"id"               jsonschema:"Internal UUID of the client"`
+	ID       string            `json:"id"               jsonschema:"Client UUID or clientId"`
 	MapperID string            `json:"mapper_id"        jsonschema:"ID of the protocol mapper"`
 	Name     *string           `json:"name,omitempty"   jsonschema:"New name for the mapper"`
 	Config   map[string]string `json:"config,omitempty" jsonschema:"Updated mapper configuration"`
@@ -123,28 +130,30 @@ type updateClientProtocolMapperArgs struct {
 
 type deleteClientProtocolMapperArgs struct {
 	Realm    string `json:"realm,omitempty" jsonschema:"Keycloak realm (uses default if omitted)"`
-	ID       string `json:"id"             jsonschema:"Internal UUID of the client"`
+	ID       string `json:"id"             jsonschema:"Client UUID or clientId"`
 	MapperID string `json:"mapper_id"      jsonschema:"ID of the protocol mapper"`
 }
 
 type getClientSessionsArgs struct {
 	Realm string `json:"realm,omitempty" jsonschema:"Keycloak realm (uses default if omitted)"`
-	ID    string `json:"id"             jsonschema:"Internal UUID of the client"`
+	ID    string `json:"id"             jsonschema:"Client UUID or clientId"`
 	First *int   `json:"first,omitempty" jsonschema:"Pagination offset"`
 	Max   *int   `json:"max,omitempty"   jsonschema:"Maximum number of results"`
+	pageArgs
+	viewArgs
 }
 
 // ---------------------------------------------------------------------------
 // Registration
 // ---------------------------------------------------------------------------
 
-func registerClientTools(s *mcp.Server, kc *keycloak.Client) {
+func registerClientTools(s *mcp.Server, kc *keycloak.Client, cfg *config.Config) {
 	registerListClients(s, kc)
 	registerGetClient(s, kc)
 	registerCreateClient(s, kc)
 	registerUpdateClient(s, kc)
 	registerDeleteClient(s, kc)
-	registerGetClientSecret(s, kc)
+	registerGetClientSecret(s, kc, cfg)
 	registerRegenerateClientSecret(s, kc)
 	registerGetClientServiceAccount(s, kc)
 	registerGetClientDefaultScopes(s, kc)
@@ -165,12 +174,14 @@ func registerClientTools(s *mcp.Server, kc *keycloak.Client) {
 
 func registerListClients(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "list_clients",
-		Description: "List clients in a Keycloak realm, optionally filtered by clientId",
+		Name:         "list_clients",
+		Description:  "List clients in a Keycloak realm, optionally filtered by clientId",
+		OutputSchema: listSchema[*gocloak.Client](),
+		Annotations:  readOnly("List clients"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args listClientsArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
@@ -179,11 +190,11 @@ func registerListClients(s *mcp.Server, kc *keycloak.Client) {
 			params.ClientID = &args.ClientID
 		}
 
-		clients, err := kc.GC.GetClients(ctx, token, realm, params)
+		clients, err := kc.GetClients(keycloak.WithNoCache(ctx, args.NoCache), token, realm, params)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to list clients: %v", err))
+			return kcError("failed to list clients", err)
 		}
-		return toolResult(clients)
+		return listResult(clients)
 	})
 }
 
@@ -193,18 +204,24 @@ func registerListClients(s *mcp.Server, kc *keycloak.Client) {
 
 func registerGetClient(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_client",
-		Description: "Get a Keycloak client by its internal UUID",
+		Name:         "get_client",
+		Description:  "Get a Keycloak client by its internal UUID or clientId",
+		OutputSchema: outputSchema[gocloak.Client](),
+		Annotations:  readOnly("Get client"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getClientArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ID), err)
+		}
 
-		client, err := kc.GC.GetClient(ctx, token, realm, args.ID)
+		client, err := kc.GC.GetClient(ctx, token, realm, idOfClient)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get client: %v", err))
+			return kcError("failed to get client", err)
 		}
 		return toolResult(client)
 	})
@@ -216,12 +233,14 @@ func registerGetClient(s *mcp.Server, kc *keycloak.Client) {
 
 func registerCreateClient(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_client",
-		Description: "Create a new client in a Keycloak realm",
+		Name:         "create_client",
+		Description:  "Create a new client in a Keycloak realm",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Create client", false),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args createClientArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
@@ -292,9 +311,10 @@ func registerCreateClient(s *mcp.Server, kc *keycloak.Client) {
 
 		id, err := kc.GC.CreateClient(ctx, token, realm, newClient)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to create client: %v", err))
+			return kcError("failed to create client", err)
 		}
-		return toolSuccess(fmt.Sprintf("client created with id: %s", id))
+		kc.Invalidate(realm, keycloak.ResourceClients)
+		return toolCreated(id, fmt.Sprintf("client created with id: %s", id))
 	})
 }
 
@@ -304,18 +324,24 @@ func registerCreateClient(s *mcp.Server, kc *keycloak.Client) {
 
 func registerUpdateClient(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "update_client",
-		Description: "Update an existing Keycloak client",
+		Name:         "update_client",
+		Description:  "Update an existing Keycloak client",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Update client", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args updateClientArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ID), err)
+		}
 
-		client, err := kc.GC.GetClient(ctx, token, realm, args.ID)
+		client, err := kc.GC.GetClient(ctx, token, realm, idOfClient)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get client for update: %v", err))
+			return kcError("failed to get client for update", err)
 		}
 
 		if args.Name != nil {
@@ -347,8 +373,9 @@ func registerUpdateClient(s *mcp.Server, kc *keycloak.Client) {
 
 		err = kc.GC.UpdateClient(ctx, token, realm, *client)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to update client: %v", err))
+			return kcError("failed to update client", err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceClients)
 		return toolSuccess("client updated successfully")
 	})
 }
@@ -359,19 +386,26 @@ func registerUpdateClient(s *mcp.Server, kc *keycloak.Client) {
 
 func registerDeleteClient(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_client",
-		Description: "Delete a client from a Keycloak realm",
+		Name:         "delete_client",
+		Description:  "Delete a client from a Keycloak realm",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete client", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args deleteClientArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ID), err)
+		}
 
-		err = kc.GC.DeleteClient(ctx, token, realm, args.ID)
+		err = kc.GC.DeleteClient(ctx, token, realm, idOfClient)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to delete client: %v", err))
+			return kcError("failed to delete client", err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceClients)
 		return toolSuccess("client deleted successfully")
 	})
 }
@@ -380,22 +414,35 @@ func registerDeleteClient(s *mcp.Server, kc *keycloak.Client) {
 // 6. get_client_secret
 // ---------------------------------------------------------------------------
 
-func registerGetClientSecret(s *mcp.Server, kc *keycloak.Client) {
+func registerGetClientSecret(s *mcp.Server, kc *keycloak.Client, cfg *config.Config) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_client_secret",
-		Description: "Get the secret for a Keycloak client",
+		Name:         "get_client_secret",
+		Description:  "Get the secret for a Keycloak client. Only available when KEYCLOAK_REVEAL_SECRETS is enabled",
+		OutputSchema: outputSchema[valueOutput](),
+		Annotations:  readOnly("Get client secret"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getClientSecretArgs) (*mcp.CallToolResult, any, error) {
+		if !cfg.RevealSecrets {
+			return toolError(&toolErr{
+				Code:    codeForbidden,
+				Message: "client secrets are redacted: the server is not configured to reveal them",
+				Hint:    "Set KEYCLOAK_REVEAL_SECRETS=true to allow get_client_secret.",
+			})
+		}
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ID), err)
+		}
 
-		cred, err := kc.GC.GetClientSecret(ctx, token, realm, args.ID)
+		cred, err := kc.GC.GetClientSecret(ctx, token, realm, idOfClient)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get client secret: %v", err))
+			return kcError("failed to get client secret", err)
 		}
-		return toolResult(cred.Value)
+		return valueResult(cred.Value)
 	})
 }
 
@@ -405,19 +452,26 @@ func registerGetClientSecret(s *mcp.Server, kc *keycloak.Client) {
 
 func registerRegenerateClientSecret(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "regenerate_client_secret",
-		Description: "Regenerate the secret for a Keycloak client",
+		Name:         "regenerate_client_secret",
+		Description:  "Regenerate the secret for a Keycloak client. The new secret is redacted; read it with get_client_secret",
+		OutputSchema: outputSchema[gocloak.CredentialRepresentation](),
+		Annotations:  destructive("Regenerate client secret", false),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args regenerateClientSecretArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ID), err)
+		}
 
-		cred, err := kc.GC.RegenerateClientSecret(ctx, token, realm, args.ID)
+		cred, err := kc.GC.RegenerateClientSecret(ctx, token, realm, idOfClient)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to regenerate client secret: %v", err))
+			return kcError("failed to regenerate client secret", err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceClients)
 		return toolResult(cred)
 	})
 }
@@ -428,18 +482,24 @@ func registerRegenerateClientSecret(s *mcp.Server, kc *keycloak.Client) {
 
 func registerGetClientServiceAccount(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_client_service_account",
-		Description: "Get the service account user associated with a Keycloak client",
+		Name:         "get_client_service_account",
+		Description:  "Get the service account user associated with a Keycloak client",
+		OutputSchema: outputSchema[gocloak.User](),
+		Annotations:  readOnly("Get client service account"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getClientServiceAccountArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ID), err)
+		}
 
-		user, err := kc.GC.GetClientServiceAccount(ctx, token, realm, args.ID)
+		user, err := kc.GC.GetClientServiceAccount(ctx, token, realm, idOfClient)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get service account: %v", err))
+			return kcError("failed to get service account", err)
 		}
 		return toolResult(user)
 	})
@@ -451,20 +511,26 @@ func registerGetClientServiceAccount(s *mcp.Server, kc *keycloak.Client) {
 
 func registerGetClientDefaultScopes(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_client_default_scopes",
-		Description: "Get the default scopes assigned to a Keycloak client",
+		Name:         "get_client_default_scopes",
+		Description:  "Get the default scopes assigned to a Keycloak client",
+		OutputSchema: listSchema[*gocloak.ClientScope](),
+		Annotations:  readOnly("Get client default scopes"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getClientDefaultScopesArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ID), err)
+		}
 
-		scopes, err := kc.GC.GetClientsDefaultScopes(ctx, token, realm, args.ID)
+		scopes, err := kc.GC.GetClientsDefaultScopes(ctx, token, realm, idOfClient)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get default scopes: %v", err))
+			return kcError("failed to get default scopes", err)
 		}
-		return toolResult(scopes)
+		return listResult(scopes)
 	})
 }
 
@@ -474,19 +540,26 @@ func registerGetClientDefaultScopes(s *mcp.Server, kc *keycloak.Client) {
 
 func registerAddClientDefaultScope(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "add_client_default_scope",
-		Description: "Add a default scope to a Keycloak client",
+		Name:         "add_client_default_scope",
+		Description:  "Add a default scope to a Keycloak client",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Add client default scope", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args addClientDefaultScopeArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ID), err)
+		}
 
-		err = kc.GC.AddDefaultScopeToClient(ctx, token, realm, args.ID, args.ScopeID)
+		err = kc.GC.AddDefaultScopeToClient(ctx, token, realm, idOfClient, args.ScopeID)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to add default scope: %v", err))
+			return kcError("failed to add default scope", err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceClients)
 		return toolSuccess("default scope added to client")
 	})
 }
@@ -497,19 +570,26 @@ func registerAddClientDefaultScope(s *mcp.Server, kc *keycloak.Client) {
 
 func registerRemoveClientDefaultScope(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "remove_client_default_scope",
-		Description: "Remove a default scope from a Keycloak client",
+		Name:         "remove_client_default_scope",
+		Description:  "Remove a default scope from a Keycloak client",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Remove client default scope", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args removeClientDefaultScopeArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ID), err)
+		}
 
-		err = kc.GC.RemoveDefaultScopeFromClient(ctx, token, realm, args.ID, args.ScopeID)
+		err = kc.GC.RemoveDefaultScopeFromClient(ctx, token, realm, idOfClient, args.ScopeID)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to remove default scope: %v", err))
+			return kcError("failed to remove default scope", err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceClients)
 		return toolSuccess("default scope removed from client")
 	})
 }
@@ -520,20 +600,26 @@ func registerRemoveClientDefaultScope(s *mcp.Server, kc *keycloak.Client) {
 
 func registerGetClientOptionalScopes(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_client_optional_scopes",
-		Description: "Get the optional scopes assigned to a Keycloak client",
+		Name:         "get_client_optional_scopes",
+		Description:  "Get the optional scopes assigned to a Keycloak client",
+		OutputSchema: listSchema[*gocloak.ClientScope](),
+		Annotations:  readOnly("Get client optional scopes"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getClientOptionalScopesArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ID), err)
+		}
 
-		scopes, err := kc.GC.GetClientsOptionalScopes(ctx, token, realm, args.ID)
+		scopes, err := kc.GC.GetClientsOptionalScopes(ctx, token, realm, idOfClient)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get optional scopes: %v", err))
+			return kcError("failed to get optional scopes", err)
 		}
-		return toolResult(scopes)
+		return listResult(scopes)
 	})
 }
 
@@ -543,19 +629,26 @@ func registerGetClientOptionalScopes(s *mcp.Server, kc *keycloak.Client) {
 
 func registerAddClientOptionalScope(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "add_client_optional_scope",
-		Description: "Add an optional scope to a Keycloak client",
+		Name:         "add_client_optional_scope",
+		Description:  "Add an optional scope to a Keycloak client",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Add client optional scope", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args addClientOptionalScopeArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ID), err)
+		}
 
-		err = kc.GC.AddOptionalScopeToClient(ctx, token, realm, args.ID, args.ScopeID)
+		err = kc.GC.AddOptionalScopeToClient(ctx, token, realm, idOfClient, args.ScopeID)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to add optional scope: %v", err))
+			return kcError("failed to add optional scope", err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceClients)
 		return toolSuccess("optional scope added to client")
 	})
 }
@@ -566,19 +659,26 @@ func registerAddClientOptionalScope(s *mcp.Server, kc *keycloak.Client) {
 
 func registerRemoveClientOptionalScope(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "remove_client_optional_scope",
-		Description: "Remove an optional scope from a Keycloak client",
+		Name:         "remove_client_optional_scope",
+		Description:  "Remove an optional scope from a Keycloak client",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Remove client optional scope", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args removeClientOptionalScopeArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ID), err)
+		}
 
-		err = kc.GC.RemoveOptionalScopeFromClient(ctx, token, realm, args.ID, args.ScopeID)
+		err = kc.GC.RemoveOptionalScopeFromClient(ctx, token, realm, idOfClient, args.ScopeID)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to remove optional scope: %v", err))
+			return kcError("failed to remove optional scope", err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceClients)
 		return toolSuccess("optional scope removed from client")
 	})
 }
@@ -589,14 +689,20 @@ func registerRemoveClientOptionalScope(s *mcp.Server, kc *keycloak.Client) {
 
 func registerCreateClientProtocolMapper(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_client_protocol_mapper",
-		Description: "Create a protocol mapper for a Keycloak client",
+		Name:         "create_client_protocol_mapper",
+		Description:  "Create a protocol mapper for a Keycloak client",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Create client protocol mapper", false),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args createClientProtocolMapperArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ID), err)
+		}
 
 		mapper := gocloak.ProtocolMapperRepresentation{
 			Name:           gocloak.StringP(args.Name),
@@ -605,11 +711,12 @@ func registerCreateClientProtocolMapper(s *mcp.Server, kc *keycloak.Client) {
 			Config:         &args.Config,
 		}
 
-		id, err := kc.GC.CreateClientProtocolMapper(ctx, token, realm, args.ID, mapper)
+		id, err := kc.GC.CreateClientProtocolMapper(ctx, token, realm, idOfClient, mapper)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to create protocol mapper: %v", err))
+			return kcError("failed to create protocol mapper", err)
 		}
-		return toolSuccess(fmt.Sprintf("protocol mapper created with id: %s", id))
+		kc.Invalidate(realm, keycloak.ResourceClients)
+		return toolCreated(id, fmt.Sprintf("protocol mapper created with id: %s", id))
 	})
 }
 
@@ -619,14 +726,20 @@ func registerCreateClientProtocolMapper(s *mcp.Server, kc *keycloak.Client) {
 
 func registerUpdateClientProtocolMapper(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "update_client_protocol_mapper",
-		Description: "Update a protocol mapper for a Keycloak client",
+		Name:         "update_client_protocol_mapper",
+		Description:  "Update a protocol mapper for a Keycloak client",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Update client protocol mapper", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args updateClientProtocolMapperArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ID), err)
+		}
 
 		mapper := gocloak.ProtocolMapperRepresentation{
 			ID: gocloak.StringP(args.MapperID),
@@ -638,10 +751,11 @@ func registerUpdateClientProtocolMapper(s *mcp.Server, kc *keycloak.Client) {
 			mapper.Config = &args.Config
 		}
 
-		err = kc.GC.UpdateClientProtocolMapper(ctx, token, realm, args.ID, args.MapperID, mapper)
+		err = kc.GC.UpdateClientProtocolMapper(ctx, token, realm, idOfClient, args.MapperID, mapper)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to update protocol mapper: %v", err))
+			return kcError("failed to update protocol mapper", err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceClients)
 		return toolSuccess("protocol mapper updated successfully")
 	})
 }
@@ -652,19 +766,26 @@ func registerUpdateClientProtocolMapper(s *mcp.Server, kc *keycloak.Client) {
 
 func registerDeleteClientProtocolMapper(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_client_protocol_mapper",
-		Description: "Delete a protocol mapper from a Keycloak client",
+		Name:         "delete_client_protocol_mapper",
+		Description:  "Delete a protocol mapper from a Keycloak client",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete client protocol mapper", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args deleteClientProtocolMapperArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ID), err)
+		}
 
-		err = kc.GC.DeleteClientProtocolMapper(ctx, token, realm, args.ID, args.MapperID)
+		err = kc.GC.DeleteClientProtocolMapper(ctx, token, realm, idOfClient, args.MapperID)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to delete protocol mapper: %v", err))
+			return kcError("failed to delete protocol mapper", err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceClients)
 		return toolSuccess("protocol mapper deleted successfully")
 	})
 }
@@ -675,24 +796,42 @@ func registerDeleteClientProtocolMapper(s *mcp.Server, kc *keycloak.Client) {
 
 func registerGetClientSessions(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_client_sessions",
-		Description: "Get active user sessions for a Keycloak client",
+		Name:         "get_client_sessions",
+		Description:  "Get active user sessions for a Keycloak client",
+		OutputSchema: listSchema[*gocloak.UserSessionRepresentation](),
+		Annotations:  readOnly("Get client sessions"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getClientSessionsArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ID), err)
+		}
 
 		params := gocloak.GetClientUserSessionsParams{
 			First: args.First,
 			Max:   args.Max,
 		}
 
-		sessions, err := kc.GC.GetClientUserSessions(ctx, token, realm, args.ID, params)
+		if args.paginated() {
+			res, err := paginate(ctx, newPageQuery("get_client_sessions", realm, idOfClient), args.pageArgs, args.First, resultLimit(args.Max, kc.MaxResults()), func(first, max int) ([]*gocloak.UserSessionRepresentation, error) {
+				p := params
+				p.First, p.Max = &first, &max
+				return kc.GC.GetClientUserSessions(ctx, token, realm, idOfClient, p)
+			})
+			if err != nil {
+				return pageError("failed to get client sessions", err)
+			}
+			return toolResult(res)
+		}
+
+		sessions, err := kc.GC.GetClientUserSessions(ctx, token, realm, idOfClient, params)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get client sessions: %v", err))
+			return kcError("failed to get client sessions", err)
 		}
-		return toolResult(sessions)
+		return listResult(sessions)
 	})
 }
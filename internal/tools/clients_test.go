@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/config"
+)
+
+func TestClientTools(t *testing.T) {
+	h := newHarness(t)
+
+	id := idFrom(t, h.ok("create_client", map[string]any{
+		"client_id": "web", "name": "Web", "redirect_uris": []string{"https://app.example.com/*"},
+	}))
+	h.ok("create_client", map[string]any{"client_id": "sso", "protocol": "saml"})
+	h.fail("create_client", map[string]any{"client_id": "web"}, codeConflict)
+
+	var clients []map[string]any
+	h.okJSON("list_clients", nil, &clients)
+	assertNames(t, "clients", clients, "clientId", "sso", "web")
+	h.okJSON("list_clients", map[string]any{"client_id": "web"}, &clients)
+	assertNames(t, "clients filtered", clients, "clientId", "web")
+
+	var sso map[string]any
+	h.okJSON("get_client", map[string]any{"id": "sso"}, &sso)
+	attrs, _ := sso["attributes"].(map[string]any)
+	if sso["protocol"] != "saml" || attrs["saml.authnstatement"] != "true" {
+		t.Fatalf("SAML defaults not applied: %v", sso)
+	}
+
+	// Clients can be addressed by UUID or clientId.
+	for _, ref := range []string{id, "web"} {
+		var c map[string]any
+		h.okJSON("get_client", map[string]any{"id": ref}, &c)
+		if c["id"] != id {
+			t.Fatalf("get_client(%q) returned %v", ref, c["id"])
+		}
+	}
+	h.fail("get_client", map[string]any{"id": "missing"}, codeNotFound)
+
+	h.ok("update_client", map[string]any{"id": "web", "enabled": false, "attributes": map[string]string{"pkce.code.challenge.method": "S256"}})
+	var web map[string]any
+	h.okJSON("get_client", map[string]any{"id": id}, &web)
+	if web["enabled"] != false || web["name"] != "Web" {
+		t.Fatalf("update_client not applied: %v", web)
+	}
+
+	h.fail("get_client_secret", map[string]any{"id": "web"}, codeForbidden)
+	var regenerated map[string]any
+	h.okJSON("regenerate_client_secret", map[string]any{"id": "web"}, &regenerated)
+	if regenerated["type"] != "secret" || regenerated["value"] != redactedValue {
+		t.Fatalf("regenerated secret not redacted: %v", regenerated)
+	}
+
+	h.fail("get_client_service_account", map[string]any{"id": "web"}, codeValidation)
+
+	var scopes []map[string]any
+	h.ok("add_client_default_scope", map[string]any{"id": "web", "scope_id": "profile"})
+	h.okJSON("get_client_default_scopes", map[string]any{"id": "web"}, &scopes)
+	assertNames(t, "default scopes", scopes, "id", "profile")
+	h.ok("remove_client_default_scope", map[string]any{"id": "web", "scope_id": "profile"})
+	h.ok("add_client_optional_scope", map[string]any{"id": "web", "scope_id": "offline_access"})
+	h.okJSON("get_client_optional_scopes", map[string]any{"id": "web"}, &scopes)
+	assertNames(t, "optional scopes", scopes, "id", "offline_access")
+	h.ok("remove_client_optional_scope", map[string]any{"id": "web", "scope_id": "offline_access"})
+	h.okJSON("get_client_optional_scopes", map[string]any{"id": "web"}, &scopes)
+	assertNames(t, "optional scopes after removal", scopes, "id")
+
+	mapperID := idFrom(t, h.ok("create_client_protocol_mapper", map[string]any{
+		"id": "web", "name": "tenant", "protocol": "openid-connect", "mapper_type": "oidc-hardcoded-claim-mapper",
+		"config": map[string]string{"claim.name": "tenant", "claim.value": "acme"},
+	}))
+	h.ok("update_client_protocol_mapper", map[string]any{"id": "web", "mapper_id": mapperID, "config": map[string]string{"claim.value": "acme-eu"}})
+	h.ok("delete_client_protocol_mapper", map[string]any{"id": "web", "mapper_id": mapperID})
+	h.fail("delete_client_protocol_mapper", map[string]any{"id": "web", "mapper_id": mapperID}, codeNotFound)
+
+	var sessions []map[string]any
+	h.okJSON("get_client_sessions", map[string]any{"id": "web"}, &sessions)
+
+	h.ok("delete_client", map[string]any{"id": "web"})
+	h.fail("get_client", map[string]any{"id": id}, codeNotFound)
+}
+
+func TestClientServiceAccount(t *testing.T) {
+	h := newHarness(t)
+	id := h.fake.AddClient("acme", "worker")
+	h.fake.EnableServiceAccount("acme", id)
+
+	var user map[string]any
+	h.okJSON("get_client_service_account", map[string]any{"id": "worker"}, &user)
+	if user["username"] != "service-account-worker" {
+		t.Fatalf("service account user = %v", user)
+	}
+}
+
+func TestRevealClientSecret(t *testing.T) {
+	h := newHarnessWithConfig(t, func(cfg *config.Config) { cfg.RevealSecrets = true })
+	id := h.fake.AddClient("acme", "web")
+
+	var before, after string
+	h.okJSON("get_client_secret", map[string]any{"id": "web"}, &before)
+	h.ok("regenerate_client_secret", map[string]any{"id": "web"})
+	h.okJSON("get_client_secret", map[string]any{"id": "web"}, &after)
+	if before == "" || before == redactedValue || after == before {
+		t.Fatalf("secret not revealed or not regenerated: %q -> %q", before, after)
+	}
+
+	// Other tools stay redacted even when secrets may be revealed.
+	var raw struct {
+		Body map[string]any `json:"body"`
+	}
+	h.okJSON("admin_api_request", map[string]any{"method": "GET", "path": "/clients/" + id + "/client-secret"}, &raw)
+	if raw.Body["value"] != redactedValue {
+		t.Fatalf("admin_api_request returned the secret: %v", raw.Body)
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	v := map[string]any{
+		"clientId": "web",
+		"secret":   "s3cret",
+		"config":   map[string]any{"clientSecret": "idp-secret", "syncMode": "IMPORT"},
+		"components": []any{
+			map[string]any{"config": map[string]any{"bindCredential": []any{"ldap-pw"}, "bindDn": []any{"cn=admin"}}},
+		},
+		"credentials": []any{map[string]any{"type": "password", "value": "pw", "secretData": "{}"}},
+		"smtpServer":  map[string]any{"password": "smtp-pw", "user": "mailer"},
+	}
+	redactSecrets(v)
+
+	got, _ := generic(v)
+	want, _ := generic(map[string]any{
+		"clientId": "web",
+		"secret":   redactedValue,
+		"config":   map[string]any{"clientSecret": redactedValue, "syncMode": "IMPORT"},
+		"components": []any{
+			map[string]any{"config": map[string]any{"bindCredential": []any{redactedValue}, "bindDn": []any{"cn=admin"}}},
+		},
+		"credentials": []any{map[string]any{"type": "password", "value": redactedValue, "secretData": redactedValue}},
+		"smtpServer":  map[string]any{"password": redactedValue, "user": "mailer"},
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("redactSecrets = %v", got)
+	}
+}
@@ -0,0 +1,271 @@
+package tools
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// completionTTL is how long the candidates for one argument are reused. It
+// is short: completion is interactive and should reflect recent changes.
+const completionTTL = 30 * time.Second
+
+// maxCompletions is the number of values MCP allows in one completion result.
+const maxCompletions = 100
+
+// completer lists the candidate values of an argument in realm. prefix is
+// the typed value, for completers that can only fetch part of the set.
+type completer func(ctx context.Context, kc *keycloak.Client, token, realm, prefix string) ([]string, error)
+
+// completers are keyed by argument name. Prompt arguments use the tool
+// argument names (client_id, username); resource templates use Keycloak's
+// (clientId, path, role).
+var completers = map[string]completer{
+	"realm":     completeRealms,
+	"client_id": completeClients,
+	"clientId":  completeClients,
+	"username":  completeUsers,
+	"path":      completeGroups,
+	"role":      completeRealmRoles,
+	"alias":     completeIdentityProviders,
+}
+
+// refCompleters override completers for an argument of one prompt or
+// resource template, where the same name means something else.
+var refCompleters = map[string]map[string]completer{
+	resourceScheme + "://{realm}/flows/{alias}": {"alias": completeFlows},
+}
+
+// CompletionHandler answers completion/complete for prompt arguments and
+// resource template variables with live values from Keycloak, filtered by the
+// typed prefix. Pass it as mcp.ServerOptions.CompletionHandler.
+func CompletionHandler(kc *keycloak.Client) func(context.Context, *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	cache := &completionCache{entries: map[string]completionEntry{}}
+	return func(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+		p := req.Params
+		ref := p.Ref.Name
+		if p.Ref.Type == "ref/resource" {
+			ref = p.Ref.URI
+		}
+		fn, ok := refCompleters[ref][p.Argument.Name]
+		if !ok {
+			fn, ok = completers[p.Argument.Name]
+		}
+		if !ok {
+			return &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{Values: []string{}}}, nil
+		}
+
+		realm := ""
+		if p.Context != nil {
+			realm = p.Context.Arguments["realm"]
+		}
+		realm = kc.ResolveRealm(realm)
+		prefix := p.Argument.Value
+		values, err := cache.get(p.Argument.Name+"\x00"+ref+"\x00"+realm+"\x00"+fetchKey(p.Argument.Name, prefix), func() ([]string, error) {
+			token, err := kc.Token(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return fn(ctx, kc, token, realm, prefix)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		matches := []string{}
+		for _, v := range values {
+			if strings.HasPrefix(strings.ToLower(v), strings.ToLower(prefix)) {
+				matches = append(matches, v)
+			}
+		}
+		sort.Strings(matches)
+		res := &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{Values: matches, Total: len(matches)}}
+		if len(matches) > maxCompletions {
+			res.Completion.Values = matches[:maxCompletions]
+			res.Completion.HasMore = true
+		}
+		return res, nil
+	}
+}
+
+// usernameStem is how many typed characters a username search uses. Longer
+// prefixes are filtered from the candidates cached for their stem.
+const usernameStem = 2
+
+// fetchKey is the part of the prefix a completer's result depends on, so that
+// typing more characters reuses the cached candidates. Users are searched by
+// the stem of the prefix; groups are listed per parent path.
+func fetchKey(arg, prefix string) string {
+	switch arg {
+	case "username":
+		return userStem(prefix)
+	case "path":
+		if i := strings.LastIndex(prefix, "/"); i >= 0 {
+			return prefix[:i]
+		}
+	}
+	return ""
+}
+
+type completionEntry struct {
+	values  []string
+	expires time.Time
+}
+
+// completionCache keeps candidate lists for completionTTL.
+type completionCache struct {
+	mu      sync.Mutex
+	entries map[string]completionEntry
+}
+
+func (c *completionCache) get(key string, fetch func() ([]string, error)) ([]string, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(e.expires) {
+		return e.values, nil
+	}
+	values, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	c.mu.Lock()
+	for k, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = completionEntry{values: values, expires: now.Add(completionTTL)}
+	c.mu.Unlock()
+	return values, nil
+}
+
+func completeRealms(ctx context.Context, kc *keycloak.Client, token, _, _ string) ([]string, error) {
+	realms, err := kc.GetRealms(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(realms))
+	for _, r := range realms {
+		out = append(out, gocloak.PString(r.Realm))
+	}
+	return out, nil
+}
+
+func completeClients(ctx context.Context, kc *keycloak.Client, token, realm, _ string) ([]string, error) {
+	clients, err := kc.GetClients(ctx, token, realm, gocloak.GetClientsParams{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(clients))
+	for _, c := range clients {
+		out = append(out, gocloak.PString(c.ClientID))
+	}
+	return out, nil
+}
+
+// userStem returns the first usernameStem characters of prefix, lower-cased.
+func userStem(prefix string) string {
+	r := []rune(strings.ToLower(prefix))
+	return string(r[:min(len(r), usernameStem)])
+}
+
+// completeUsers searches users by the stem of prefix. It fetches up to the
+// server's result cap, not just maxCompletions, so that the candidates for
+// longer prefixes can be filtered from the same result.
+func completeUsers(ctx context.Context, kc *keycloak.Client, token, realm, prefix string) ([]string, error) {
+	params := gocloak.GetUsersParams{Max: gocloak.IntP(kc.MaxResults()), BriefRepresentation: gocloak.BoolP(true)}
+	if stem := userStem(prefix); stem != "" {
+		params.Username = gocloak.StringP(stem)
+	}
+	users, err := kc.GC.GetUsers(ctx, token, realm, params)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(users))
+	for _, u := range users {
+		out = append(out, gocloak.PString(u.Username))
+	}
+	return out, nil
+}
+
+// completeGroups lists group paths without the leading slash, as used in
+// keycloak://{realm}/groups/{+path}. Below the top level, the children of the
+// typed parent path are listed.
+func completeGroups(ctx context.Context, kc *keycloak.Client, token, realm, prefix string) ([]string, error) {
+	var groups []*gocloak.Group
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		parent, err := kc.GC.GetGroupByPath(ctx, token, realm, prefix[:i])
+		if err != nil {
+			return nil, err
+		}
+		if parent.SubGroups != nil {
+			for i := range *parent.SubGroups {
+				groups = append(groups, &(*parent.SubGroups)[i])
+			}
+		}
+	} else {
+		var err error
+		groups, err = kc.GC.GetGroups(ctx, token, realm, gocloak.GetGroupsParams{Max: gocloak.IntP(kc.MaxResults())})
+		if err != nil {
+			return nil, err
+		}
+	}
+	var out []string
+	var walk func(g gocloak.Group)
+	walk = func(g gocloak.Group) {
+		out = append(out, strings.TrimPrefix(gocloak.PString(g.Path), "/"))
+		if g.SubGroups != nil {
+			for _, sub := range *g.SubGroups {
+				walk(sub)
+			}
+		}
+	}
+	for _, g := range groups {
+		walk(*g)
+	}
+	return out, nil
+}
+
+func completeRealmRoles(ctx context.Context, kc *keycloak.Client, token, realm, _ string) ([]string, error) {
+	roles, err := kc.GetRealmRoles(ctx, token, realm, gocloak.GetRoleParams{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(roles))
+	for _, r := range roles {
+		out = append(out, gocloak.PString(r.Name))
+	}
+	return out, nil
+}
+
+func completeIdentityProviders(ctx context.Context, kc *keycloak.Client, token, realm, _ string) ([]string, error) {
+	idps, err := kc.GC.GetIdentityProviders(ctx, token, realm)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(idps))
+	for _, idp := range idps {
+		out = append(out, gocloak.PString(idp.Alias))
+	}
+	return out, nil
+}
+
+func completeFlows(ctx context.Context, kc *keycloak.Client, token, realm, _ string) ([]string, error) {
+	flows, err := kc.GC.GetAuthenticationFlows(ctx, token, realm)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(flows))
+	for _, f := range flows {
+		out = append(out, gocloak.PString(f.Alias))
+	}
+	return out, nil
+}
@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestCompletion(t *testing.T) {
+	h := newHarness(t)
+	h.fake.AddClient("acme", "web")
+	h.fake.AddUser("acme", "alice", "alice@example.com")
+	h.fake.AddUser("acme", "bob", "bob@example.com")
+	eng := h.fake.AddGroup("acme", "", "eng")
+	h.fake.AddGroup("acme", eng, "platform")
+	h.fake.AddRealmRole("acme", "deployer")
+
+	prompt := func(name string) *mcp.CompleteReference {
+		return &mcp.CompleteReference{Type: "ref/prompt", Name: name}
+	}
+	resource := func(uri string) *mcp.CompleteReference { return &mcp.CompleteReference{Type: "ref/resource", URI: uri} }
+	complete := func(ref *mcp.CompleteReference, arg, value string) string {
+		t.Helper()
+		res, err := h.cs.Complete(context.Background(), &mcp.CompleteParams{
+			Ref:      ref,
+			Argument: mcp.CompleteParamsArgument{Name: arg, Value: value},
+			Context:  &mcp.CompleteContext{Arguments: map[string]string{"realm": "acme"}},
+		})
+		if err != nil {
+			t.Fatalf("complete %s=%q: %v", arg, value, err)
+		}
+		return fmt.Sprint(res.Completion.Values)
+	}
+
+	cases := []struct {
+		ref        *mcp.CompleteReference
+		arg, value string
+		want       string
+	}{
+		{prompt("offboard_employee"), "realm", "", "[acme master]"},
+		{prompt("offboard_employee"), "realm", "AC", "[acme]"},
+		{prompt("onboard_oidc_application"), "client_id", "we", "[web]"},
+		{prompt("investigate_lockout"), "username", "al", "[alice]"},
+		{resource("keycloak://{realm}/clients/{clientId}"), "clientId", "w", "[web]"},
+		{resource("keycloak://{realm}/groups/{+path}"), "path", "e", "[eng eng/platform]"},
+		{resource("keycloak://{realm}/groups/{+path}"), "path", "eng/p", "[eng/platform]"},
+		{resource("keycloak://{realm}/roles/{role}"), "role", "dep", "[deployer]"},
+		{resource("keycloak://{realm}/flows/{alias}"), "alias", "br", "[browser]"},
+		{prompt("offboard_employee"), "reason", "x", "[]"},
+	}
+	for _, c := range cases {
+		if got := complete(c.ref, c.arg, c.value); got != c.want {
+			t.Errorf("%s %s=%q: got %s, want %s", c.ref.Name+c.ref.URI, c.arg, c.value, got, c.want)
+		}
+	}
+
+	// Candidates are cached briefly, so a new client shows up only later.
+	h.fake.AddClient("acme", "worker")
+	if got := complete(prompt("onboard_oidc_application"), "client_id", "w"); got != "[web]" {
+		t.Errorf("client_id completion not cached: %s", got)
+	}
+
+	// Longer usernames are filtered from the users fetched for their stem.
+	h.fake.AddUser("acme", "alan", "")
+	if got := complete(prompt("investigate_lockout"), "username", "ala"); got != "[]" {
+		t.Errorf("username completion for a longer prefix refetched: %s", got)
+	}
+	if got := complete(prompt("investigate_lockout"), "username", "Ali"); got != "[alice]" {
+		t.Errorf("username completion for a longer prefix = %s, want [alice]", got)
+	}
+}
@@ -18,11 +18,13 @@ type listComponentsArgs struct {
 	Realm string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
 	Name  string `json:"name,omitempty"  jsonschema:"Filter by component name"`
 	Type  string `json:"type,omitempty"  jsonschema:"Filter by provider type"`
+	viewArgs
 }
 
 type getComponentArgs struct {
 	Realm       string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
 	ComponentID string `json:"component_id"    jsonschema:"Component ID"`
+	viewArgs
 }
 
 type createComponentArgs struct {
@@ -54,12 +56,14 @@ func registerComponentTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 1. list_components
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "list_components",
-		Description: "List components (user storage, LDAP, etc.) in a realm",
+		Name:         "list_components",
+		Description:  "List components (user storage, LDAP, etc.) in a realm",
+		OutputSchema: listSchema[*gocloak.Component](),
+		Annotations:  readOnly("List components"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args listComponentsArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
@@ -73,37 +77,41 @@ func registerComponentTools(s *mcp.Server, kc *keycloak.Client) {
 
 		components, err := kc.GC.GetComponentsWithParams(ctx, token, realm, params)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to list components: %v", err))
+			return kcError("failed to list components", err)
 		}
-		return toolResult(components)
+		return listResult(components)
 	})
 
 	// 2. get_component
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_component",
-		Description: "Get a component by ID",
+		Name:         "get_component",
+		Description:  "Get a component by ID",
+		OutputSchema: outputSchema[gocloak.Component](),
+		Annotations:  readOnly("Get component"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getComponentArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
 		component, err := kc.GC.GetComponent(ctx, token, realm, args.ComponentID)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get component %q: %v", args.ComponentID, err))
+			return kcError(fmt.Sprintf("failed to get component %q", args.ComponentID), err)
 		}
 		return toolResult(component)
 	})
 
 	// 3. create_component
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_component",
-		Description: "Create a component (e.g. user federation provider) in a realm",
+		Name:         "create_component",
+		Description:  "Create a component (e.g. user federation provider) in a realm",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Create component", false),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args createComponentArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
@@ -121,25 +129,27 @@ func registerComponentTools(s *mcp.Server, kc *keycloak.Client) {
 
 		id, err := kc.GC.CreateComponent(ctx, token, realm, component)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to create component: %v", err))
+			return kcError("failed to create component", err)
 		}
-		return toolSuccess(fmt.Sprintf("Component created with ID: %s", id))
+		return toolCreated(id, fmt.Sprintf("Component created with ID: %s", id))
 	})
 
 	// 4. update_component
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "update_component",
-		Description: "Update a component in a realm",
+		Name:         "update_component",
+		Description:  "Update a component in a realm",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Update component", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args updateComponentArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
 		existing, err := kc.GC.GetComponent(ctx, token, realm, args.ComponentID)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get component %q for update: %v", args.ComponentID, err))
+			return kcError(fmt.Sprintf("failed to get component %q for update", args.ComponentID), err)
 		}
 
 		if args.Name != nil {
@@ -150,24 +160,26 @@ func registerComponentTools(s *mcp.Server, kc *keycloak.Client) {
 		}
 
 		if err := kc.GC.UpdateComponent(ctx, token, realm, *existing); err != nil {
-			return toolError(fmt.Sprintf("failed to update component %q: %v", args.ComponentID, err))
+			return kcError(fmt.Sprintf("failed to update component %q", args.ComponentID), err)
 		}
 		return toolSuccess(fmt.Sprintf("Component %q updated successfully", args.ComponentID))
 	})
 
 	// 5. delete_component
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_component",
-		Description: "Delete a component from a realm",
+		Name:         "delete_component",
+		Description:  "Delete a component from a realm",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete component", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args deleteComponentArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
 		if err := kc.GC.DeleteComponent(ctx, token, realm, args.ComponentID); err != nil {
-			return toolError(fmt.Sprintf("failed to delete component %q: %v", args.ComponentID, err))
+			return kcError(fmt.Sprintf("failed to delete component %q", args.ComponentID), err)
 		}
 		return toolSuccess(fmt.Sprintf("Component %q deleted successfully", args.ComponentID))
 	})
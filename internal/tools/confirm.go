@@ -0,0 +1,514 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/config"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
+)
+
+// Through MCP elicitation the server asks the user, not the model, to confirm
+// destructive calls, to pick the realm of a change when no default realm is
+// configured and to pick one object when an identifier matches several.
+// KEYCLOAK_CONFIRM decides what happens with clients that cannot be asked.
+
+const (
+	confirmAuto   = "auto"   // ask when the client supports elicitation, otherwise run
+	confirmAlways = "always" // refuse destructive calls when the client cannot be asked
+	confirmOff    = "off"    // never ask
+)
+
+// maxPicks bounds how often one call asks which of several objects was meant.
+const maxPicks = 3
+
+// confirmTool reports whether a call deletes, removes, revokes, logs out or
+// regenerates something, or impersonates a user, and so needs the user's
+// confirmation. update_user with totp false deletes the user's OTP
+// credentials.
+func confirmTool(name string, args map[string]any) bool {
+	switch name {
+	case "update_user":
+		totp, ok := args["totp"].(bool)
+		return ok && !totp
+	case "admin_api_request":
+		method, _ := args["method"].(string)
+		return strings.EqualFold(method, http.MethodDelete)
+	case "bulk_update_users":
+		preview, _ := args["preview"].(bool)
+		return !preview
+	case "impersonate_user":
+		return true
+	}
+	for _, prefix := range []string{"delete_", "remove_", "revoke_", "logout_", "regenerate_"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// impact describes what a confirmed call will do, looking up the objects it
+// affects. Tools without one get a summary of their arguments.
+type impact func(ctx context.Context, kc *keycloak.Client, token, realm string, args map[string]any) (string, error)
+
+var impacts = map[string]impact{
+	"bulk_update_users": bulkImpact,
+	"delete_user": func(ctx context.Context, kc *keycloak.Client, token, realm string, args map[string]any) (string, error) {
+		ref, _ := args["user_id"].(string)
+		id, err := kc.ResolveUserID(ctx, token, realm, ref)
+		if err != nil {
+			return "", err
+		}
+		user, err := kc.GC.GetUserByID(ctx, token, realm, id)
+		if err != nil {
+			return "", err
+		}
+		sessions, err := kc.GC.GetUserSessions(ctx, token, realm, id)
+		if err != nil {
+			return "", err
+		}
+		groups, err := kc.GC.GetUserGroups(ctx, token, realm, id, gocloak.GetGroupsParams{})
+		if err != nil {
+			return "", err
+		}
+		name := gocloak.PString(user.Username)
+		if email := gocloak.PString(user.Email); email != "" {
+			name += " <" + email + ">"
+		}
+		return fmt.Sprintf("User %s (%s) in realm %s will be permanently deleted, with its credentials, %d active sessions and membership of %d groups.",
+			name, id, realm, len(sessions), len(groups)), nil
+	},
+	"impersonate_user": func(ctx context.Context, kc *keycloak.Client, token, realm string, args map[string]any) (string, error) {
+		ref, _ := args["user_id"].(string)
+		id, err := kc.ResolveUserID(ctx, token, realm, ref)
+		if err != nil {
+			return "", err
+		}
+		user, err := kc.GC.GetUserByID(ctx, token, realm, id)
+		if err != nil {
+			return "", err
+		}
+		name := gocloak.PString(user.Username)
+		if email := gocloak.PString(user.Email); email != "" {
+			name += " <" + email + ">"
+		}
+		reason, _ := args["reason"].(string)
+		return fmt.Sprintf("A session will be opened as user %s (%s) in realm %s, with everything they can access, and logged out right away. Reason given: %q.",
+			name, id, realm, reason), nil
+	},
+	"update_user": func(ctx context.Context, kc *keycloak.Client, token, realm string, args map[string]any) (string, error) {
+		ref, _ := args["user_id"].(string)
+		id, err := kc.ResolveUserID(ctx, token, realm, ref)
+		if err != nil {
+			return "", err
+		}
+		user, err := kc.GC.GetUserByID(ctx, token, realm, id)
+		if err != nil {
+			return "", err
+		}
+		creds, err := kc.GC.GetCredentials(ctx, token, realm, id)
+		if err != nil {
+			return "", err
+		}
+		var otp []string
+		for _, c := range creds {
+			if gocloak.PString(c.Type) != "otp" {
+				continue
+			}
+			desc := gocloak.PString(c.ID)
+			if label := gocloak.PString(c.UserLabel); label != "" {
+				desc = fmt.Sprintf("%q (%s)", label, desc)
+			}
+			if c.CreatedDate != nil {
+				desc += ", created " + time.UnixMilli(*c.CreatedDate).UTC().Format(time.DateOnly)
+			}
+			otp = append(otp, desc)
+		}
+		name := gocloak.PString(user.Username)
+		if len(otp) == 0 {
+			return fmt.Sprintf("User %s (%s) in realm %s has no OTP credentials; CONFIGURE_TOTP will be removed from its required actions.",
+				name, id, realm), nil
+		}
+		return fmt.Sprintf("The %d OTP credential(s) of user %s (%s) in realm %s will be deleted: %s. The user can no longer log in with their authenticator app until they set up a new one.",
+			len(otp), name, id, realm, strings.Join(otp, "; ")), nil
+	},
+	"delete_client": func(ctx context.Context, kc *keycloak.Client, token, realm string, args map[string]any) (string, error) {
+		client, err := impactClient(ctx, kc, token, realm, args)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Client %s (%s) in realm %s will be permanently deleted, with its roles, protocol mappers and service account. Applications using it can no longer log in.",
+			gocloak.PString(client.ClientID), gocloak.PString(client.ID), realm), nil
+	},
+	"regenerate_client_secret": func(ctx context.Context, kc *keycloak.Client, token, realm string, args map[string]any) (string, error) {
+		client, err := impactClient(ctx, kc, token, realm, args)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("The secret of client %s (%s) in realm %s will be replaced. Applications using the current secret fail to authenticate until they are given the new one.",
+			gocloak.PString(client.ClientID), gocloak.PString(client.ID), realm), nil
+	},
+	"delete_group": func(ctx context.Context, kc *keycloak.Client, token, realm string, args map[string]any) (string, error) {
+		ref, _ := args["group_id"].(string)
+		id, err := kc.ResolveGroupID(ctx, token, realm, ref)
+		if err != nil {
+			return "", err
+		}
+		group, err := kc.GC.GetGroup(ctx, token, realm, id)
+		if err != nil {
+			return "", err
+		}
+		members, err := kc.GC.GetGroupMembers(ctx, token, realm, id, gocloak.GetGroupsParams{Max: gocloak.IntP(kc.MaxResults())})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Group %s (%s) in realm %s will be permanently deleted with its subgroups. Its %d members lose the roles it grants.",
+			gocloak.PString(group.Path), id, realm, len(members)), nil
+	},
+	"delete_realm": func(ctx context.Context, kc *keycloak.Client, token, realm string, _ map[string]any) (string, error) {
+		users, err := kc.GC.GetUserCount(ctx, token, realm, gocloak.GetUsersParams{})
+		if err != nil {
+			return "", err
+		}
+		clients, err := kc.GetClients(ctx, token, realm, gocloak.GetClientsParams{})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Realm %s will be permanently deleted with all of its %d users, %d clients, groups, roles and settings.",
+			realm, users, len(clients)), nil
+	},
+}
+
+// confirmedKey carries a confirmedSelection from the confirmation to the
+// tool call it allowed.
+type confirmedKey struct{}
+
+// confirmedSelection holds the IDs of the objects an impact showed the user,
+// so that the tool acts on what was confirmed and not on a selection that
+// changed in between.
+type confirmedSelection struct {
+	ids []string
+	set bool
+}
+
+// withConfirmedSelection returns a context through which an impact can record
+// the objects it showed.
+func withConfirmedSelection(ctx context.Context) context.Context {
+	return context.WithValue(ctx, confirmedKey{}, &confirmedSelection{})
+}
+
+// recordConfirmed is called by an impact with the IDs of the objects it lists.
+func recordConfirmed(ctx context.Context, ids []string) {
+	if sel, ok := ctx.Value(confirmedKey{}).(*confirmedSelection); ok {
+		sel.ids, sel.set = ids, true
+	}
+}
+
+// confirmedIDs returns the IDs the user confirmed for this call, if any.
+func confirmedIDs(ctx context.Context) ([]string, bool) {
+	sel, ok := ctx.Value(confirmedKey{}).(*confirmedSelection)
+	if !ok || !sel.set {
+		return nil, false
+	}
+	return sel.ids, true
+}
+
+func impactClient(ctx context.Context, kc *keycloak.Client, token, realm string, args map[string]any) (*gocloak.Client, error) {
+	ref, _ := args["id"].(string)
+	id, err := kc.ResolveClientID(ctx, token, realm, ref)
+	if err != nil {
+		return nil, err
+	}
+	return kc.GC.GetClient(ctx, token, realm, id)
+}
+
+// callSummary describes a call by its arguments, for tools without an impact.
+func callSummary(name, realm string, args map[string]any) string {
+	var parts []string
+	for k, v := range args {
+		if k != "realm" {
+			parts = append(parts, fmt.Sprintf("%s=%v", k, v))
+		}
+	}
+	sort.Strings(parts)
+	msg := fmt.Sprintf("%s will run in realm %s", name, realm)
+	if len(parts) > 0 {
+		msg += " with " + strings.Join(parts, ", ")
+	}
+	return msg + ". This cannot be undone."
+}
+
+// askUser sends a confirmation or a question to the user for one tool call,
+// editing the call's arguments with the answers.
+func askUser(kc *keycloak.Client, cfg *config.Config) mcp.Middleware {
+	tools := &toolIndex{}
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		if cfg.Confirm == confirmOff {
+			return next
+		}
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || call.Session == nil {
+				return next(ctx, method, req)
+			}
+			name := call.Params.Name
+			var args map[string]any
+			_ = json.Unmarshal(call.Params.Arguments, &args)
+			if args == nil {
+				args = map[string]any{}
+			}
+
+			if !canElicit(call.Session) {
+				if cfg.Confirm == confirmAlways && confirmTool(name, args) {
+					res, _, _ := toolError(&toolErr{
+						Code:    codeForbidden,
+						Message: fmt.Sprintf("%s needs the user's confirmation, but the client does not support elicitation", name),
+						Hint:    "Use an MCP client that supports elicitation, or set KEYCLOAK_CONFIRM=auto to run destructive tools without confirmation.",
+					})
+					return res, nil
+				}
+				return next(ctx, method, req)
+			}
+
+			ctx = withConfirmedSelection(ctx)
+			a := &asker{ctx: ctx, kc: kc, session: call.Session, name: name, args: args}
+			realm, _ := args["realm"].(string)
+			if realm == "" && cfg.DefaultRealm == "" && !readOnlyTool(name) && tools.takesRealm(ctx, next, call.Session, name) {
+				if res := a.pickRealm(); res != nil {
+					return res, nil
+				}
+			}
+			if confirmTool(name, args) {
+				if res := a.confirm(); res != nil {
+					return res, nil
+				}
+			}
+			a.apply(call)
+
+			res, err := next(ctx, method, req)
+			for range maxPicks {
+				r, ok := res.(*mcp.CallToolResult)
+				if err != nil || !ok || !r.IsError {
+					break
+				}
+				e, ok := r.StructuredContent.(*toolErr)
+				if !ok || e.ambiguous == nil {
+					break
+				}
+				picked, declined := a.pick(e.ambiguous)
+				if declined != nil {
+					return declined, nil
+				}
+				if !picked {
+					break
+				}
+				a.apply(call)
+				res, err = next(ctx, method, req)
+			}
+			return res, err
+		}
+	}
+}
+
+func canElicit(ss *mcp.ServerSession) bool {
+	p := ss.InitializeParams()
+	return p != nil && p.Capabilities != nil && p.Capabilities.Elicitation != nil
+}
+
+// asker asks the user about one tool call.
+type asker struct {
+	ctx     context.Context
+	kc      *keycloak.Client
+	session *mcp.ServerSession
+	name    string
+	args    map[string]any
+	changed bool // args were edited with the user's answers
+}
+
+func (a *asker) realm() string {
+	realm, _ := a.args["realm"].(string)
+	return a.kc.ResolveRealm(realm)
+}
+
+// apply replaces the arguments of call once the user's answers changed them.
+func (a *asker) apply(call *mcp.CallToolRequest) {
+	if !a.changed {
+		return
+	}
+	if b, err := json.Marshal(a.args); err == nil {
+		call.Params.Arguments = b
+	}
+	a.changed = false
+}
+
+// pickRealm asks which realm a change applies to when several exist.
+func (a *asker) pickRealm() *mcp.CallToolResult {
+	token, err := a.kc.Token(a.ctx)
+	if err != nil {
+		res, _, _ := tokenError(err)
+		return res
+	}
+	realms, err := a.kc.GetRealms(a.ctx, token)
+	if err != nil || len(realms) < 2 {
+		// The tool reports the failure, or there is nothing to choose.
+		return nil
+	}
+	names := make([]any, 0, len(realms))
+	for _, r := range realms {
+		names = append(names, gocloak.PString(r.Realm))
+	}
+	res, err := a.session.Elicit(a.ctx, &mcp.ElicitParams{
+		Message: fmt.Sprintf("%s was called without a realm and no default realm is configured. Which realm should it change?", a.name),
+		RequestedSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"realm": {Type: "string", Title: "Realm", Enum: names, Default: mustJSON(a.realm())},
+			},
+			Required: []string{"realm"},
+		},
+	})
+	if r := a.declined(res, err); r != nil {
+		return r
+	}
+	a.args["realm"] = res.Content["realm"]
+	a.changed = true
+	return nil
+}
+
+// confirm shows the impact of the call and asks the user to allow it.
+// Identifiers that match several objects are resolved first, so that the
+// impact names the object that will be changed.
+func (a *asker) confirm() *mcp.CallToolResult {
+	token, err := a.kc.Token(a.ctx)
+	if err != nil {
+		res, _, _ := tokenError(err)
+		return res
+	}
+	summary := callSummary(a.name, a.realm(), a.args)
+	if describe, ok := impacts[a.name]; ok {
+		for range maxPicks {
+			s, err := describe(a.ctx, a.kc, token, a.realm(), a.args)
+			var ambErr *keycloak.AmbiguousError
+			if errors.As(err, &ambErr) {
+				picked, res := a.pick(ambErr)
+				if res != nil {
+					return res
+				}
+				if picked {
+					continue
+				}
+			}
+			if err == nil {
+				summary = s
+			}
+			// Otherwise the tool fails the same way and reports the error.
+			break
+		}
+	}
+	res, err := a.session.Elicit(a.ctx, &mcp.ElicitParams{
+		Message:         summary + "\n\nAllow " + a.name + "?",
+		RequestedSchema: &jsonschema.Schema{Type: "object"},
+	})
+	return a.declined(res, err)
+}
+
+// pick asks which candidate an ambiguous identifier means and substitutes its
+// ID in the arguments. It reports false, with a result for the declined call,
+// if the user did not choose.
+func (a *asker) pick(ambErr *keycloak.AmbiguousError) (bool, *mcp.CallToolResult) {
+	key := ""
+	for k, v := range a.args {
+		if s, ok := v.(string); ok && s == ambErr.Ref {
+			key = k
+			break
+		}
+	}
+	if key == "" {
+		return false, nil
+	}
+	ids := make([]any, 0, len(ambErr.Candidates))
+	names := make([]any, 0, len(ambErr.Candidates))
+	for _, c := range ambErr.Candidates {
+		ids = append(ids, c.ID)
+		names = append(names, c.Name)
+	}
+	res, err := a.session.Elicit(a.ctx, &mcp.ElicitParams{
+		Message: fmt.Sprintf("%s %q matches %d objects in realm %s. Which one did you mean?", ambErr.Kind, ambErr.Ref, len(ids), a.realm()),
+		RequestedSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"id": {Type: "string", Title: strings.ToUpper(ambErr.Kind[:1]) + ambErr.Kind[1:], Enum: ids, Extra: map[string]any{"enumNames": names}},
+			},
+			Required: []string{"id"},
+		},
+	})
+	if r := a.declined(res, err); r != nil {
+		return false, r
+	}
+	a.args[key] = res.Content["id"]
+	a.changed = true
+	return true, nil
+}
+
+// declined turns an elicitation that failed or was not accepted into the
+// result of the tool call.
+func (a *asker) declined(res *mcp.ElicitResult, err error) *mcp.CallToolResult {
+	var r *mcp.CallToolResult
+	switch {
+	case err != nil:
+		r, _, _ = internalError(fmt.Sprintf("failed to ask the user about %s: %v", a.name, err))
+	case res.Action != "accept":
+		r, _, _ = toolError(&toolErr{
+			Code:    codeCancelled,
+			Message: fmt.Sprintf("%s was not run: the user chose %q", a.name, res.Action),
+			Hint:    "The user did not allow this call. Do not retry it unless they ask again.",
+		})
+	}
+	return r
+}
+
+func mustJSON(v any) json.RawMessage {
+	b, _ := json.Marshal(v)
+	return b
+}
+
+// toolIndex records which registered tools take a realm argument. It lists
+// the tools through the rest of the middleware chain on first use.
+type toolIndex struct {
+	mu    sync.Mutex
+	realm map[string]bool
+}
+
+func (t *toolIndex) takesRealm(ctx context.Context, next mcp.MethodHandler, ss *mcp.ServerSession, name string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.realm == nil {
+		res, err := next(ctx, "tools/list", &mcp.ListToolsRequest{Session: ss, Params: &mcp.ListToolsParams{}})
+		list, ok := res.(*mcp.ListToolsResult)
+		if err != nil || !ok {
+			return false
+		}
+		t.realm = map[string]bool{}
+		for _, tool := range list.Tools {
+			var schema struct {
+				Properties map[string]any `json:"properties"`
+			}
+			if b, err := json.Marshal(tool.InputSchema); err == nil && json.Unmarshal(b, &schema) == nil {
+				_, t.realm[tool.Name] = schema.Properties["realm"]
+			}
+		}
+	}
+	return t.realm[name]
+}
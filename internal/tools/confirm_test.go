@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/config"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloaktest"
+)
+
+// newElicitingHarness is a harness whose client answers elicitation requests
+// with answer, recording their messages in asked.
+func newElicitingHarness(t *testing.T, mutate func(*config.Config), answer func(*mcp.ElicitParams) *mcp.ElicitResult) (*harness, *[]string) {
+	t.Helper()
+	fake := keycloaktest.NewServer()
+	t.Cleanup(fake.Close)
+	cfg := fake.Config()
+	if mutate != nil {
+		mutate(cfg)
+	}
+	var asked []string
+	cs := connect(context.Background(), cfg, nil, &mcp.ClientOptions{
+		ElicitationHandler: func(_ context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			asked = append(asked, req.Params.Message)
+			return answer(req.Params), nil
+		},
+	})
+	t.Cleanup(func() { cs.Close() })
+	return &harness{t: t, fake: fake, cs: cs}, &asked
+}
+
+func TestConfirmDestructiveCalls(t *testing.T) {
+	allow := false
+	h, asked := newElicitingHarness(t, nil, func(*mcp.ElicitParams) *mcp.ElicitResult {
+		if allow {
+			return &mcp.ElicitResult{Action: "accept"}
+		}
+		return &mcp.ElicitResult{Action: "decline"}
+	})
+	h.fake.AddUser("acme", "alice", "alice@example.com")
+
+	h.fail("delete_user", map[string]any{"user_id": "alice"}, codeCancelled)
+	h.ok("get_user", map[string]any{"user_id": "alice"})
+	if len(*asked) != 1 || !strings.Contains((*asked)[0], "alice <alice@example.com>") || !strings.Contains((*asked)[0], "realm acme") {
+		t.Fatalf("confirmation = %q, want the user and realm named", *asked)
+	}
+
+	allow = true
+	h.ok("delete_user", map[string]any{"user_id": "alice"})
+	h.fail("get_user", map[string]any{"user_id": "alice"}, codeNotFound)
+	if len(*asked) != 2 {
+		t.Fatalf("asked %d times, want 2", len(*asked))
+	}
+}
+
+func TestConfirmRequiresElicitation(t *testing.T) {
+	h := newHarnessWithConfig(t, func(cfg *config.Config) { cfg.Confirm = confirmAlways })
+	h.fake.AddUser("acme", "alice", "")
+
+	h.fail("delete_user", map[string]any{"user_id": "alice"}, codeForbidden)
+	h.ok("update_user", map[string]any{"user_id": "alice", "first_name": "Alice"})
+}
+
+func TestPickAmbiguousIdentifier(t *testing.T) {
+	var want string
+	h, asked := newElicitingHarness(t, nil, func(p *mcp.ElicitParams) *mcp.ElicitResult {
+		return &mcp.ElicitResult{Action: "accept", Content: map[string]any{"id": want}}
+	})
+	h.fake.AddUser("acme", "dave", "shared@example.com")
+	want = h.fake.AddUser("acme", "dave2", "shared@example.com")
+
+	var user struct {
+		Username string `json:"username"`
+	}
+	h.okJSON("get_user", map[string]any{"user_id": "shared@example.com"}, &user)
+	if user.Username != "dave2" || len(*asked) != 1 {
+		t.Fatalf("got %q after %d questions, want dave2 after 1", user.Username, len(*asked))
+	}
+}
+
+func TestPickRealmWithoutDefault(t *testing.T) {
+	h, asked := newElicitingHarness(t, func(cfg *config.Config) { cfg.DefaultRealm = "" }, func(p *mcp.ElicitParams) *mcp.ElicitResult {
+		return &mcp.ElicitResult{Action: "accept", Content: map[string]any{"realm": "acme"}}
+	})
+
+	h.ok("create_group", map[string]any{"name": "eng"})
+	h.ok("get_group", map[string]any{"realm": "acme", "group_id": "/eng"})
+	// Reads use the fallback realm without asking.
+	h.ok("list_groups", nil)
+	if len(*asked) != 1 {
+		t.Fatalf("asked %d times, want 1", len(*asked))
+	}
+}
+
+func TestConfirmBulkUpdate(t *testing.T) {
+	h, asked := newElicitingHarness(t, nil, func(*mcp.ElicitParams) *mcp.ElicitResult {
+		return &mcp.ElicitResult{Action: "decline"}
+	})
+	h.fake.AddUser("acme", "alice", "alice@contractor.com")
+	h.fake.AddUser("acme", "bob", "bob@contractor.com")
+
+	h.ok("bulk_update_users", map[string]any{"email": "@contractor.com", "action": "disable", "preview": true})
+	h.fail("bulk_update_users", map[string]any{"email": "@contractor.com", "action": "disable"}, codeCancelled)
+	if len(*asked) != 1 || !strings.Contains((*asked)[0], "2 users in realm acme: alice, bob") {
+		t.Fatalf("confirmation = %q, want the selected users named", *asked)
+	}
+}
+
+func TestConfirmTOTPRemoval(t *testing.T) {
+	h, asked := newElicitingHarness(t, nil, func(*mcp.ElicitParams) *mcp.ElicitResult {
+		return &mcp.ElicitResult{Action: "decline"}
+	})
+	id := h.fake.AddUser("acme", "alice", "")
+	otp := h.fake.AddCredential("acme", id, "otp")
+
+	h.fail("update_user", map[string]any{"user_id": "alice", "totp": false}, codeCancelled)
+	if len(*asked) != 1 || !strings.Contains((*asked)[0], "1 OTP credential(s) of user alice") || !strings.Contains((*asked)[0], otp) {
+		t.Fatalf("confirmation = %q, want the OTP credential listed", *asked)
+	}
+	var creds []map[string]any
+	h.okJSON("get_user_credentials", map[string]any{"user_id": "alice"}, &creds)
+	assertNames(t, "credentials", creds, "type", "otp")
+
+	// Adding TOTP removes nothing and is not confirmed.
+	h.ok("update_user", map[string]any{"user_id": "alice", "totp": true})
+	if len(*asked) != 1 {
+		t.Fatalf("asked %d times, want 1", len(*asked))
+	}
+}
@@ -0,0 +1,219 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
+)
+
+// Stable error codes returned in the "code" field of every tool error. Clients
+// and models can branch on these without parsing the message text.
+const (
+	codeNotFound            = "not_found"
+	codeConflict            = "conflict"
+	codeForbidden           = "forbidden"
+	codeUnauthorized        = "unauthorized"
+	codeValidation          = "validation"
+	codeUpstreamUnavailable = "upstream_unavailable"
+	codeUnsupported         = "unsupported"
+	codeCancelled           = "cancelled"
+	codeInternal            = "internal"
+)
+
+// remediation hints keyed by error code.
+var errorHints = map[string]string{
+	codeNotFound:            "Check the identifier and realm; list the collection first to find valid IDs or names.",
+	codeConflict:            "An object with the same unique name already exists; fetch it instead or choose a different name.",
+	codeForbidden:           "The admin account lacks permission for this operation; grant the matching realm-management role (e.g. manage-users, view-clients) in this realm.",
+	codeUnauthorized:        "The admin token was rejected or could not be obtained; check KEYCLOAK_AUTH_MODE and the configured credentials.",
+	codeValidation:          "Keycloak rejected the request arguments; correct them using keycloak_error and retry.",
+	codeUpstreamUnavailable: "Keycloak could not be reached or returned a server error; check KEYCLOAK_URL and retry later.",
+	codeUnsupported:         "The connected Keycloak version does not support this operation; upgrade Keycloak or enable the required feature (see get_capabilities).",
+	codeCancelled:           "The call was cancelled or timed out before it finished; the message says what was completed. Retry, resuming from the cursor if one is given.",
+	codeInternal:            "Unexpected server-side failure; retry, and report the message if it persists.",
+}
+
+// toolErr is the structured payload returned for every failed tool call.
+type toolErr struct {
+	Code          string               `json:"code"`
+	Status        int                  `json:"status,omitempty"`
+	Message       string               `json:"message"`
+	KeycloakError string               `json:"keycloak_error,omitempty"`
+	Candidates    []keycloak.Candidate `json:"candidates,omitempty"`
+	Hint          string               `json:"hint,omitempty"`
+
+	ambiguous *keycloak.AmbiguousError // lets askUser ask which candidate was meant
+}
+
+// toolError renders a structured error as an MCP error result. The payload is
+// returned both as indented JSON text and as structured content.
+func toolError(e *toolErr) (*mcp.CallToolResult, any, error) {
+	if e.Hint == "" {
+		e.Hint = errorHints[e.Code]
+	}
+	b, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		b = []byte(e.Message)
+	}
+	return &mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: string(b)}},
+		StructuredContent: e,
+		IsError:           true,
+	}, nil, nil
+}
+
+// kcError classifies an error returned by a Keycloak call. action describes
+// what was attempted, e.g. "failed to get user".
+func kcError(action string, err error) (*mcp.CallToolResult, any, error) {
+	return toolError(classifyError(action, err))
+}
+
+// tokenError reports a failure to obtain an admin access token.
+func tokenError(err error) (*mcp.CallToolResult, any, error) {
+	e := classifyError("failed to get token", err)
+	if e.Code != codeUpstreamUnavailable {
+		e.Code = codeUnauthorized
+	}
+	return toolError(e)
+}
+
+// validationError reports invalid tool arguments detected before calling Keycloak.
+func validationError(msg string) (*mcp.CallToolResult, any, error) {
+	return toolError(&toolErr{Code: codeValidation, Message: msg})
+}
+
+// notFoundError reports an object that could not be located by the tool itself.
+func notFoundError(msg string) (*mcp.CallToolResult, any, error) {
+	return toolError(&toolErr{Code: codeNotFound, Status: http.StatusNotFound, Message: msg})
+}
+
+// readOnlyError reports an attempt to modify Keycloak while read-only mode is on.
+func readOnlyError(op string) (*mcp.CallToolResult, any, error) {
+	return toolError(&toolErr{
+		Code:    codeForbidden,
+		Message: fmt.Sprintf("%s is not allowed: the server is running in read-only mode", op),
+		Hint:    "Unset KEYCLOAK_READ_ONLY to allow changes.",
+	})
+}
+
+// internalError reports a failure inside the MCP server itself.
+func internalError(msg string) (*mcp.CallToolResult, any, error) {
+	return toolError(&toolErr{Code: codeInternal, Message: msg})
+}
+
+// validationErr is an argument problem found after a tool started talking to
+// Keycloak, such as a name that does not exist in the realm. classifyError
+// reports it as a validation error.
+type validationErr string
+
+func (e validationErr) Error() string { return string(e) }
+
+func classifyError(action string, err error) *toolErr {
+	e := &toolErr{Code: codeInternal, Message: action}
+
+	var valErr validationErr
+	if errors.As(err, &valErr) {
+		e.Code = codeValidation
+		e.Message = fmt.Sprintf("%s: %v", action, err)
+		return e
+	}
+
+	var ambErr *keycloak.AmbiguousError
+	if errors.As(err, &ambErr) {
+		e.Code = codeValidation
+		e.Message = fmt.Sprintf("%s: %v", action, err)
+		e.Candidates = ambErr.Candidates
+		e.ambiguous = ambErr
+		e.Hint = "The identifier matches several objects; retry with one of the candidate IDs or a more specific identifier (full group path, exact email)."
+		return e
+	}
+
+	var intErr *interruptedError
+	if errors.As(err, &intErr) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		e.Code = codeCancelled
+		e.Message = fmt.Sprintf("%s: %v", action, err)
+		return e
+	}
+
+	var unsupErr *keycloak.UnsupportedError
+	if errors.As(err, &unsupErr) {
+		e.Code = codeUnsupported
+		e.Message = fmt.Sprintf("%s: %v", action, err)
+		return e
+	}
+
+	var apiErr *gocloak.APIError
+	if !errors.As(err, &apiErr) {
+		var netErr net.Error
+		if errors.As(err, &netErr) {
+			e.Code = codeUpstreamUnavailable
+		}
+		if err != nil {
+			e.Message = fmt.Sprintf("%s: %v", action, err)
+		}
+		return e
+	}
+
+	e.Status = apiErr.Code
+	e.Code = codeForStatus(apiErr.Code)
+	if apiErr.Code == 0 {
+		// Transport failure: gocloak puts the wrapped network error in Message.
+		e.KeycloakError = apiErr.Message
+		return e
+	}
+	e.KeycloakError = keycloakMessage(apiErr)
+	return e
+}
+
+func codeForStatus(status int) string {
+	switch {
+	case status == 0, status >= 500:
+		return codeUpstreamUnavailable
+	case status == http.StatusUnauthorized:
+		return codeUnauthorized
+	case status == http.StatusForbidden:
+		return codeForbidden
+	case status == http.StatusNotFound:
+		return codeNotFound
+	case status == http.StatusConflict:
+		return codeConflict
+	case status >= 400:
+		return codeValidation
+	default:
+		return codeInternal
+	}
+}
+
+// keycloakMessage extracts the Keycloak error body from a gocloak error
+// message of the form "404 Not Found: <errorMessage>".
+func keycloakMessage(apiErr *gocloak.APIError) string {
+	msg := apiErr.Message
+	if i := strings.Index(msg, ": "); i >= 0 && strings.HasPrefix(msg, fmt.Sprint(apiErr.Code)) {
+		return msg[i+2:]
+	}
+	if strings.HasPrefix(msg, fmt.Sprint(apiErr.Code)) {
+		// Status line only, no body.
+		return ""
+	}
+	return msg
+}
+
+// apiError builds a gocloak-compatible error from a raw admin API response so
+// that hand-rolled requests are classified the same way as gocloak calls.
+func apiError(status int, statusText string, body []byte) error {
+	var errBody gocloak.HTTPErrorResponse
+	msg := statusText
+	if err := json.Unmarshal(body, &errBody); err == nil && errBody.NotEmpty() {
+		msg = fmt.Sprintf("%s: %s", statusText, errBody)
+	}
+	return &gocloak.APIError{Code: status, Message: msg}
+}
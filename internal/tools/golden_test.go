@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/config"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloaktest"
+)
+
+var (
+	record = flag.Bool("record", false, "record cassettes against the Keycloak at KEYCLOAK_URL instead of replaying them")
+	update = flag.Bool("update", false, "rewrite golden files from the replayed output")
+)
+
+// goldenRealm is created and deleted by every scenario so that recording
+// against a real Keycloak leaves it as it was found.
+const goldenRealm = "golden"
+
+type goldenStep struct {
+	tool string
+	args map[string]any
+}
+
+// goldenScenarios are replayed from testdata/cassettes/<name>.json and their
+// tool output compared with testdata/golden/<name>.golden.
+var goldenScenarios = map[string][]goldenStep{
+	"users": {
+		{"create_realm", map[string]any{"realm": goldenRealm}},
+		{"create_user", map[string]any{"username": "alice", "email": "alice@example.com", "first_name": "Alice", "password": "s3cret"}},
+		{"get_user", map[string]any{"user_id": "alice"}},
+		{"update_user", map[string]any{"user_id": "alice", "last_name": "Liddell"}},
+		{"set_user_password", map[string]any{"user_id": "alice", "password": "n3w-s3cret", "temporary": true}},
+		{"list_users", map[string]any{"search": "ali"}},
+		{"delete_user", map[string]any{"user_id": "alice"}},
+		{"get_user", map[string]any{"user_id": "alice"}},
+		{"delete_realm", map[string]any{"realm": goldenRealm}},
+	},
+	"clients": {
+		{"create_realm", map[string]any{"realm": goldenRealm}},
+		{"create_client", map[string]any{"client_id": "web", "redirect_uris": []string{"https://app.example.com/*"}}},
+		{"get_client_secret", map[string]any{"id": "web"}},
+		{"regenerate_client_secret", map[string]any{"id": "web"}},
+		{"create_client_role", map[string]any{"client_id": "web", "name": "viewer"}},
+		{"list_client_roles", map[string]any{"client_id": "web"}},
+		{"create_client", map[string]any{"client_id": "web"}},
+		{"delete_realm", map[string]any{"realm": goldenRealm}},
+	},
+	"groups": {
+		{"create_realm", map[string]any{"realm": goldenRealm}},
+		{"create_group", map[string]any{"name": "eng"}},
+		{"create_child_group", map[string]any{"parent_group_id": "/eng", "name": "platform"}},
+		{"create_realm_role", map[string]any{"name": "deployer"}},
+		{"add_group_realm_roles", map[string]any{"group_id": "/eng/platform", "roles": []string{"deployer"}}},
+		{"get_group_realm_roles", map[string]any{"group_id": "/eng/platform"}},
+		{"list_groups", nil},
+		{"delete_realm", map[string]any{"realm": goldenRealm}},
+	},
+}
+
+// TestGolden replays recorded Keycloak traffic through the tools and compares
+// their output with golden files. Run with -record to re-record the cassettes
+// against the Keycloak at KEYCLOAK_URL; golden files are always produced from
+// the replay so that they only contain scrubbed values.
+func TestGolden(t *testing.T) {
+	for name, steps := range goldenScenarios {
+		t.Run(name, func(t *testing.T) {
+			cassette := filepath.Join("testdata", "cassettes", name+".json")
+			golden := filepath.Join("testdata", "golden", name+".golden")
+
+			if *record {
+				rec, err := keycloaktest.NewRecorder(cassette, keycloaktest.Record)
+				if err != nil {
+					t.Fatal(err)
+				}
+				runGolden(t, recordConfig(t), rec, steps)
+				if err := rec.Save(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			rec, err := keycloaktest.NewRecorder(cassette, keycloaktest.Replay)
+			if err != nil {
+				t.Fatalf("%v (run with -record to create it)", err)
+			}
+			got := runGolden(t, replayConfig(), rec, steps)
+			if misses := rec.Misses(); len(misses) > 0 {
+				t.Fatalf("requests missing from %s (re-record with -record):\n%s", cassette, strings.Join(misses, "\n"))
+			}
+
+			if *record || *update {
+				if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("output differs from %s (run with -update if intended):\n%s", golden, got)
+			}
+		})
+	}
+}
+
+// runGolden runs steps through the tools with rec as gocloak's transport and
+// returns the transcript.
+func runGolden(t *testing.T, cfg *config.Config, rec *keycloaktest.Recorder, steps []goldenStep) string {
+	t.Helper()
+	cs := connect(context.Background(), cfg, rec, nil)
+	defer cs.Close()
+	h := &harness{t: t, cs: cs}
+
+	var out strings.Builder
+	for _, step := range steps {
+		args, _ := json.Marshal(step.args)
+		res := h.call(step.tool, step.args)
+		status := "ok"
+		if res.IsError {
+			status = "error"
+		}
+		out.WriteString(">>> " + step.tool + " " + string(args) + " (" + status + ")\n")
+		out.WriteString(resultText(res) + "\n\n")
+	}
+	return out.String()
+}
+
+// replayConfig points the tools at an unresolvable host: every request must
+// be answered from the cassette.
+func replayConfig() *config.Config {
+	return &config.Config{
+		KeycloakURL:       "http://keycloak.invalid",
+		KeycloakRealm:     "master",
+		AuthMode:          "password",
+		AdminUser:         keycloaktest.AdminUser,
+		AdminPassword:     keycloaktest.AdminPassword,
+		DefaultRealm:      goldenRealm,
+		MaxResults:        1000,
+		AuditLog:          "off",
+		AdminAPIAllowlist: []string{"GET /**"},
+	}
+}
+
+// recordConfig loads the environment. Cassettes must come from a real
+// Keycloak: recorded against the in-process fake they would only replay the
+// fake against itself.
+func recordConfig(t *testing.T) *config.Config {
+	t.Helper()
+	if os.Getenv("KEYCLOAK_URL") == "" {
+		t.Fatal("-record needs KEYCLOAK_URL and admin credentials of a real Keycloak")
+	}
+	cfg := config.Load()
+	cfg.DefaultRealm = goldenRealm
+	cfg.AuditLog = "off"
+	return cfg
+}
@@ -18,11 +18,14 @@ type listGroupsArgs struct {
 	Search string `json:"search,omitempty" jsonschema:"Search string for group name"`
 	First  *int   `json:"first,omitempty"  jsonschema:"Pagination offset"`
 	Max    *int   `json:"max,omitempty"    jsonschema:"Maximum number of results"`
+	pageArgs
+	viewArgs
 }
 
 type getGroupArgs struct {
 	Realm   string `json:"realm,omitempty"    jsonschema:"Realm name (uses default if omitted)"`
-	GroupID string `json:"group_id"           jsonschema:"Group ID"`
+	GroupID string `json:"group_id"           jsonschema:"Group ID, path (e.g. /eng/platform) or unique name"`
+	viewArgs
 }
 
 type createGroupArgs struct {
@@ -32,26 +35,28 @@ type createGroupArgs struct {
 
 type createChildGroupArgs struct {
 	Realm         string `json:"realm,omitempty"    jsonschema:"Realm name (uses default if omitted)"`
-	ParentGroupID string `json:"parent_group_id"   jsonschema:"Parent group ID"`
+	ParentGroupID string `json:"parent_group_id"   jsonschema:"Parent group ID, path or unique name"`
 	Name          string `json:"name"              jsonschema:"Child group name"`
 }
 
 type updateGroupArgs struct {
 	Realm   string `json:"realm,omitempty"    jsonschema:"Realm name (uses default if omitted)"`
-	GroupID string `json:"group_id"           jsonschema:"Group ID"`
+	GroupID string `json:"group_id"           jsonschema:"Group ID, path (e.g. /eng/platform) or unique name"`
 	Name    string `json:"name"              jsonschema:"New group name"`
 }
 
 type deleteGroupArgs struct {
 	Realm   string `json:"realm,omitempty"    jsonschema:"Realm name (uses default if omitted)"`
-	GroupID string `json:"group_id"           jsonschema:"Group ID"`
+	GroupID string `json:"group_id"           jsonschema:"Group ID, path (e.g. /eng/platform) or unique name"`
 }
 
 type getGroupMembersArgs struct {
 	Realm   string `json:"realm,omitempty"    jsonschema:"Realm name (uses default if omitted)"`
-	GroupID string `json:"group_id"           jsonschema:"Group ID"`
+	GroupID string `json:"group_id"           jsonschema:"Group ID, path (e.g. /eng/platform) or unique name"`
 	First   *int   `json:"first,omitempty"    jsonschema:"Pagination offset"`
 	Max     *int   `json:"max,omitempty"      jsonschema:"Maximum number of results"`
+	pageArgs
+	viewArgs
 }
 
 type countGroupsArgs struct {
@@ -61,25 +66,27 @@ type countGroupsArgs struct {
 
 type getGroupRealmRolesArgs struct {
 	Realm   string `json:"realm,omitempty"    jsonschema:"Realm name (uses default if omitted)"`
-	GroupID string `json:"group_id"           jsonschema:"Group ID"`
+	GroupID string `json:"group_id"           jsonschema:"Group ID, path (e.g. /eng/platform) or unique name"`
+	viewArgs
 }
 
 type addGroupRealmRolesArgs struct {
 	Realm   string   `json:"realm,omitempty"    jsonschema:"Realm name (uses default if omitted)"`
-	GroupID string   `json:"group_id"           jsonschema:"Group ID"`
-	Roles   []string `json:"roles"             jsonschema:"List of realm role names to add"`
+	GroupID string   `json:"group_id"           jsonschema:"Group ID, path (e.g. /eng/platform) or unique name"`
+	Roles   []string `json:"roles"             jsonschema:"List of realm role names or IDs to add"`
 }
 
 type removeGroupRealmRolesArgs struct {
 	Realm   string   `json:"realm,omitempty"    jsonschema:"Realm name (uses default if omitted)"`
-	GroupID string   `json:"group_id"           jsonschema:"Group ID"`
-	Roles   []string `json:"roles"             jsonschema:"List of realm role names to remove"`
+	GroupID string   `json:"group_id"           jsonschema:"Group ID, path (e.g. /eng/platform) or unique name"`
+	Roles   []string `json:"roles"             jsonschema:"List of realm role names or IDs to remove"`
 }
 
 type getGroupClientRolesArgs struct {
 	Realm    string `json:"realm,omitempty"    jsonschema:"Realm name (uses default if omitted)"`
-	GroupID  string `json:"group_id"           jsonschema:"Group ID"`
-	ClientID string `json:"client_id"         jsonschema:"Client UUID (id of the client)"`
+	GroupID  string `json:"group_id"           jsonschema:"Group ID, path (e.g. /eng/platform) or unique name"`
+	ClientID string `json:"client_id"         jsonschema:"Client UUID or clientId"`
+	viewArgs
 }
 
 // ---------------------------------------------------------------------------
@@ -89,12 +96,14 @@ type getGroupClientRolesArgs struct {
 func registerGroupTools(s *mcp.Server, kc *keycloak.Client) {
 	// 1. list_groups
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "list_groups",
-		Description: "List groups in a Keycloak realm with optional search and pagination",
+		Name:         "list_groups",
+		Description:  "List groups in a Keycloak realm with optional search and pagination",
+		OutputSchema: listSchema[*gocloak.Group](),
+		Annotations:  readOnly("List groups"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args listGroupsArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
@@ -106,39 +115,63 @@ func registerGroupTools(s *mcp.Server, kc *keycloak.Client) {
 			params.Search = gocloak.StringP(args.Search)
 		}
 
+		if args.paginated() {
+			res, err := paginate(ctx, newPageQuery("list_groups", realm, args.Search), args.pageArgs, args.First, resultLimit(args.Max, kc.MaxResults()), func(first, max int) ([]*gocloak.Group, error) {
+				p := params
+				p.First, p.Max = &first, &max
+				return kc.GC.GetGroups(ctx, token, realm, p)
+			})
+			if err != nil {
+				return pageError("failed to list groups", err)
+			}
+			params.First, params.Max = nil, nil
+			if total, err := kc.GC.GetGroupsCount(ctx, token, realm, params); err == nil {
+				res.Total = &total
+			}
+			return toolResult(res)
+		}
+
 		groups, err := kc.GC.GetGroups(ctx, token, realm, params)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to list groups: %v", err))
+			return kcError("failed to list groups", err)
 		}
-		return toolResult(groups)
+		return listResult(groups)
 	})
 
 	// 2. get_group
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_group",
-		Description: "Get a Keycloak group by its ID",
+		Name:         "get_group",
+		Description:  "Get a Keycloak group by its ID, path or unique name",
+		OutputSchema: outputSchema[gocloak.Group](),
+		Annotations:  readOnly("Get group"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getGroupArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		groupID, err := kc.ResolveGroupID(ctx, token, realm, args.GroupID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve group %q", args.GroupID), err)
+		}
 
-		group, err := kc.GC.GetGroup(ctx, token, realm, args.GroupID)
+		group, err := kc.GC.GetGroup(ctx, token, realm, groupID)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get group: %v", err))
+			return kcError("failed to get group", err)
 		}
 		return toolResult(group)
 	})
 
 	// 3. create_group
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_group",
-		Description: "Create a new top-level group in a Keycloak realm",
+		Name:         "create_group",
+		Description:  "Create a new top-level group in a Keycloak realm",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Create group", false),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args createGroupArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
@@ -146,105 +179,143 @@ func registerGroupTools(s *mcp.Server, kc *keycloak.Client) {
 			Name: gocloak.StringP(args.Name),
 		})
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to create group: %v", err))
+			return kcError("failed to create group", err)
 		}
-		return toolSuccess(fmt.Sprintf("Group created with ID: %s", groupID))
+		return toolCreated(groupID, fmt.Sprintf("Group created with ID: %s", groupID))
 	})
 
 	// 4. create_child_group
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_child_group",
-		Description: "Create a child group under an existing parent group",
+		Name:         "create_child_group",
+		Description:  "Create a child group under an existing parent group",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Create child group", false),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args createChildGroupArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		parentID, err := kc.ResolveGroupID(ctx, token, realm, args.ParentGroupID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve group %q", args.ParentGroupID), err)
+		}
 
-		childID, err := kc.GC.CreateChildGroup(ctx, token, realm, args.ParentGroupID, gocloak.Group{
+		childID, err := kc.GC.CreateChildGroup(ctx, token, realm, parentID, gocloak.Group{
 			Name: gocloak.StringP(args.Name),
 		})
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to create child group: %v", err))
+			return kcError("failed to create child group", err)
 		}
-		return toolSuccess(fmt.Sprintf("Child group created with ID: %s", childID))
+		return toolCreated(childID, fmt.Sprintf("Child group created with ID: %s", childID))
 	})
 
 	// 5. update_group
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "update_group",
-		Description: "Update a Keycloak group (rename)",
+		Name:         "update_group",
+		Description:  "Update a Keycloak group (rename)",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Update group", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args updateGroupArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		groupID, err := kc.ResolveGroupID(ctx, token, realm, args.GroupID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve group %q", args.GroupID), err)
+		}
 
-		group, err := kc.GC.GetGroup(ctx, token, realm, args.GroupID)
+		group, err := kc.GC.GetGroup(ctx, token, realm, groupID)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get group for update: %v", err))
+			return kcError("failed to get group for update", err)
 		}
 
 		group.Name = gocloak.StringP(args.Name)
 
 		err = kc.GC.UpdateGroup(ctx, token, realm, *group)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to update group: %v", err))
+			return kcError("failed to update group", err)
 		}
-		return toolSuccess(fmt.Sprintf("Group %s updated successfully", args.GroupID))
+		return toolSuccess(fmt.Sprintf("Group %s updated successfully", groupID))
 	})
 
 	// 6. delete_group
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_group",
-		Description: "Delete a Keycloak group by its ID",
+		Name:         "delete_group",
+		Description:  "Delete a Keycloak group by its ID",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete group", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args deleteGroupArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		groupID, err := kc.ResolveGroupID(ctx, token, realm, args.GroupID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve group %q", args.GroupID), err)
+		}
 
-		err = kc.GC.DeleteGroup(ctx, token, realm, args.GroupID)
+		err = kc.GC.DeleteGroup(ctx, token, realm, groupID)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to delete group: %v", err))
+			return kcError("failed to delete group", err)
 		}
-		return toolSuccess(fmt.Sprintf("Group %s deleted successfully", args.GroupID))
+		return toolSuccess(fmt.Sprintf("Group %s deleted successfully", groupID))
 	})
 
 	// 7. get_group_members
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_group_members",
-		Description: "Get the members of a Keycloak group",
+		Name:         "get_group_members",
+		Description:  "Get the members of a Keycloak group",
+		OutputSchema: listSchema[*gocloak.User](),
+		Annotations:  readOnly("Get group members"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getGroupMembersArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		groupID, err := kc.ResolveGroupID(ctx, token, realm, args.GroupID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve group %q", args.GroupID), err)
+		}
 
 		params := gocloak.GetGroupsParams{
 			First: args.First,
 			Max:   args.Max,
 		}
 
-		members, err := kc.GC.GetGroupMembers(ctx, token, realm, args.GroupID, params)
+		if args.paginated() {
+			res, err := paginate(ctx, newPageQuery("get_group_members", realm, groupID), args.pageArgs, args.First, resultLimit(args.Max, kc.MaxResults()), func(first, max int) ([]*gocloak.User, error) {
+				p := params
+				p.First, p.Max = &first, &max
+				return kc.GC.GetGroupMembers(ctx, token, realm, groupID, p)
+			})
+			if err != nil {
+				return pageError("failed to get group members", err)
+			}
+			return toolResult(res)
+		}
+
+		members, err := kc.GC.GetGroupMembers(ctx, token, realm, groupID, params)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get group members: %v", err))
+			return kcError("failed to get group members", err)
 		}
-		return toolResult(members)
+		return listResult(members)
 	})
 
 	// 8. count_groups
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "count_groups",
-		Description: "Count the number of groups in a Keycloak realm",
+		Name:         "count_groups",
+		Description:  "Count the number of groups in a Keycloak realm",
+		OutputSchema: outputSchema[map[string]int](),
+		Annotations:  readOnly("Count groups"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args countGroupsArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
@@ -255,98 +326,126 @@ func registerGroupTools(s *mcp.Server, kc *keycloak.Client) {
 
 		count, err := kc.GC.GetGroupsCount(ctx, token, realm, params)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to count groups: %v", err))
+			return kcError("failed to count groups", err)
 		}
 		return toolResult(map[string]int{"count": count})
 	})
 
 	// 9. get_group_realm_roles
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_group_realm_roles",
-		Description: "Get realm roles assigned to a Keycloak group",
+		Name:         "get_group_realm_roles",
+		Description:  "Get realm roles assigned to a Keycloak group",
+		OutputSchema: listSchema[*gocloak.Role](),
+		Annotations:  readOnly("Get group realm roles"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getGroupRealmRolesArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		groupID, err := kc.ResolveGroupID(ctx, token, realm, args.GroupID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve group %q", args.GroupID), err)
+		}
 
-		roles, err := kc.GC.GetRealmRolesByGroupID(ctx, token, realm, args.GroupID)
+		roles, err := kc.GC.GetRealmRolesByGroupID(ctx, token, realm, groupID)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get group realm roles: %v", err))
+			return kcError("failed to get group realm roles", err)
 		}
-		return toolResult(roles)
+		return listResult(roles)
 	})
 
 	// 10. add_group_realm_roles
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "add_group_realm_roles",
-		Description: "Add realm roles to a Keycloak group",
+		Name:         "add_group_realm_roles",
+		Description:  "Add realm roles to a Keycloak group",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Add group realm roles", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args addGroupRealmRolesArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		groupID, err := kc.ResolveGroupID(ctx, token, realm, args.GroupID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve group %q", args.GroupID), err)
+		}
 
 		var rolesToAdd []gocloak.Role
 		for _, roleName := range args.Roles {
-			role, err := kc.GC.GetRealmRole(ctx, token, realm, roleName)
+			role, err := kc.ResolveRealmRole(ctx, token, realm, roleName)
 			if err != nil {
-				return toolError(fmt.Sprintf("failed to get realm role %q: %v", roleName, err))
+				return kcError(fmt.Sprintf("failed to get realm role %q", roleName), err)
 			}
 			rolesToAdd = append(rolesToAdd, *role)
 		}
 
-		err = kc.GC.AddRealmRoleToGroup(ctx, token, realm, args.GroupID, rolesToAdd)
+		err = kc.GC.AddRealmRoleToGroup(ctx, token, realm, groupID, rolesToAdd)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to add realm roles to group: %v", err))
+			return kcError("failed to add realm roles to group", err)
 		}
-		return toolSuccess(fmt.Sprintf("Added %d realm role(s) to group %s", len(rolesToAdd), args.GroupID))
+		return toolSuccess(fmt.Sprintf("Added %d realm role(s) to group %s", len(rolesToAdd), groupID))
 	})
 
 	// 11. remove_group_realm_roles
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "remove_group_realm_roles",
-		Description: "Remove realm roles from a Keycloak group",
+		Name:         "remove_group_realm_roles",
+		Description:  "Remove realm roles from a Keycloak group",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Remove group realm roles", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args removeGroupRealmRolesArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		groupID, err := kc.ResolveGroupID(ctx, token, realm, args.GroupID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve group %q", args.GroupID), err)
+		}
 
 		var rolesToRemove []gocloak.Role
 		for _, roleName := range args.Roles {
-			role, err := kc.GC.GetRealmRole(ctx, token, realm, roleName)
+			role, err := kc.ResolveRealmRole(ctx, token, realm, roleName)
 			if err != nil {
-				return toolError(fmt.Sprintf("failed to get realm role %q: %v", roleName, err))
+				return kcError(fmt.Sprintf("failed to get realm role %q", roleName), err)
 			}
 			rolesToRemove = append(rolesToRemove, *role)
 		}
 
-		err = kc.GC.DeleteRealmRoleFromGroup(ctx, token, realm, args.GroupID, rolesToRemove)
+		err = kc.GC.DeleteRealmRoleFromGroup(ctx, token, realm, groupID, rolesToRemove)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to remove realm roles from group: %v", err))
+			return kcError("failed to remove realm roles from group", err)
 		}
-		return toolSuccess(fmt.Sprintf("Removed %d realm role(s) from group %s", len(rolesToRemove), args.GroupID))
+		return toolSuccess(fmt.Sprintf("Removed %d realm role(s) from group %s", len(rolesToRemove), groupID))
 	})
 
 	// 12. get_group_client_roles
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_group_client_roles",
-		Description: "Get client roles assigned to a Keycloak group",
+		Name:         "get_group_client_roles",
+		Description:  "Get client roles assigned to a Keycloak group",
+		OutputSchema: listSchema[*gocloak.Role](),
+		Annotations:  readOnly("Get group client roles"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getGroupClientRolesArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
+		groupID, err := kc.ResolveGroupID(ctx, token, realm, args.GroupID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve group %q", args.GroupID), err)
+		}
 
-		roles, err := kc.GC.GetClientRolesByGroupID(ctx, token, realm, args.ClientID, args.GroupID)
+		roles, err := kc.GC.GetClientRolesByGroupID(ctx, token, realm, idOfClient, groupID)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get group client roles: %v", err))
+			return kcError("failed to get group client roles", err)
 		}
-		return toolResult(roles)
+		return listResult(roles)
 	})
 }
@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"testing"
+)
+
+func TestGroupTools(t *testing.T) {
+	h := newHarness(t)
+
+	engID := idFrom(t, h.ok("create_group", map[string]any{"name": "eng"}))
+	h.ok("create_group", map[string]any{"name": "sales"})
+	h.fail("create_group", map[string]any{"name": "eng"}, codeConflict)
+	platformID := idFrom(t, h.ok("create_child_group", map[string]any{"parent_group_id": "/eng", "name": "platform"}))
+
+	var groups []map[string]any
+	h.okJSON("list_groups", nil, &groups)
+	assertNames(t, "groups", groups, "name", "eng", "sales")
+	h.okJSON("list_groups", map[string]any{"search": "plat"}, &groups)
+	assertNames(t, "search plat", groups, "name", "eng")
+
+	var count map[string]int
+	h.okJSON("count_groups", nil, &count)
+	if count["count"] != 2 {
+		t.Fatalf("count_groups = %v", count)
+	}
+
+	// Groups can be addressed by ID, path or unique name.
+	for _, ref := range []string{platformID, "/eng/platform", "platform"} {
+		var g map[string]any
+		h.okJSON("get_group", map[string]any{"group_id": ref}, &g)
+		if g["id"] != platformID {
+			t.Fatalf("get_group(%q) returned %v", ref, g["id"])
+		}
+	}
+	h.fail("get_group", map[string]any{"group_id": "/eng/missing"}, codeNotFound)
+
+	h.ok("update_group", map[string]any{"group_id": platformID, "name": "infra"})
+	var g map[string]any
+	h.okJSON("get_group", map[string]any{"group_id": "/eng/infra"}, &g)
+
+	userID := h.fake.AddUser("acme", "erin", "")
+	h.ok("add_user_to_group", map[string]any{"user_id": userID, "group_id": engID})
+	var members []map[string]any
+	h.okJSON("get_group_members", map[string]any{"group_id": "eng"}, &members)
+	assertNames(t, "members", members, "username", "erin")
+	var paged pagedResult[map[string]any]
+	h.okJSON("get_group_members", map[string]any{"group_id": "eng", "all": true}, &paged)
+	if paged.Count != 1 {
+		t.Fatalf("paged members = %+v", paged)
+	}
+
+	h.fake.AddRealmRole("acme", "deployer")
+	h.ok("add_group_realm_roles", map[string]any{"group_id": "eng", "roles": []string{"deployer"}})
+	var roles []map[string]any
+	h.okJSON("get_group_realm_roles", map[string]any{"group_id": engID}, &roles)
+	assertNames(t, "group realm roles", roles, "name", "deployer")
+	h.ok("remove_group_realm_roles", map[string]any{"group_id": engID, "roles": []string{"deployer"}})
+	h.okJSON("get_group_realm_roles", map[string]any{"group_id": engID}, &roles)
+	assertNames(t, "group realm roles after removal", roles, "name")
+
+	h.fake.AddClient("acme", "portal")
+	h.okJSON("get_group_client_roles", map[string]any{"group_id": engID, "client_id": "portal"}, &roles)
+	assertNames(t, "group client roles", roles, "name")
+
+	h.ok("delete_group", map[string]any{"group_id": "/eng"})
+	h.fail("get_group", map[string]any{"group_id": engID}, codeNotFound)
+}
+
+func TestAmbiguousGroupNameReturnsCandidates(t *testing.T) {
+	h := newHarness(t)
+	eng := h.fake.AddGroup("acme", "", "eng")
+	ops := h.fake.AddGroup("acme", "", "ops")
+	h.fake.AddGroup("acme", eng, "oncall")
+	h.fake.AddGroup("acme", ops, "oncall")
+
+	e := h.fail("get_group", map[string]any{"group_id": "oncall"}, codeValidation)
+	if len(e.Candidates) != 2 || e.Candidates[0].Name != "/eng/oncall" {
+		t.Fatalf("candidates = %v", e.Candidates)
+	}
+}
@@ -3,33 +3,139 @@ package tools
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-// toolError returns an MCP error result for domain-level errors from Keycloak.
-func toolError(msg string) (*mcp.CallToolResult, any, error) {
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{&mcp.TextContent{Text: msg}},
-		IsError: true,
-	}, nil, nil
+// messageOutput is the structured result of tools that make a change.
+type messageOutput struct {
+	Message string `json:"message" jsonschema:"What the tool did"`
+	ID      string `json:"id,omitempty" jsonschema:"ID of the object the tool created"`
 }
 
-// toolResult marshals the given data as indented JSON and returns it as a
-// successful MCP tool result.
-func toolResult(data any) (*mcp.CallToolResult, any, error) {
-	b, err := json.MarshalIndent(data, "", "  ")
+// valueOutput is the structured result of tools that return a single string.
+type valueOutput struct {
+	Value string `json:"value"`
+}
+
+// schemaOverrides breaks the reference cycles in gocloak's representations
+// (groups nest groups; resources and scopes nest each other), which schema
+// inference cannot follow. Nested entries are described as plain objects.
+var schemaOverrides = map[reflect.Type]*jsonschema.Schema{
+	reflect.TypeFor[[]gocloak.Group]():                  {Types: []string{"null", "array"}, Items: &jsonschema.Schema{Type: "object"}},
+	reflect.TypeFor[[]gocloak.ResourceRepresentation](): {Types: []string{"null", "array"}, Items: &jsonschema.Schema{Type: "object"}},
+}
+
+// outputSchema infers a tool's output schema from T, which must be a struct
+// or map type. It panics on types that cannot be described, which is a
+// programming error caught at registration.
+func outputSchema[T any]() *jsonschema.Schema {
+	s, err := jsonschema.For[T](&jsonschema.ForOptions{TypeSchemas: schemaOverrides})
+	if err != nil {
+		panic(fmt.Sprintf("output schema: %v", err))
+	}
+	optional(s)
+	return s
+}
+
+// optional drops "required" throughout s: results omit null and empty
+// fields, so no property is guaranteed to be present.
+func optional(s *jsonschema.Schema) {
+	if s == nil {
+		return
+	}
+	s.Required = nil
+	for _, p := range s.Properties {
+		optional(p)
+	}
+	optional(s.Items)
+	optional(s.AdditionalProperties)
+}
+
+// openSchema lets the objects described by s carry properties it doesn't
+// list, for results that pass through fields the Go types don't model.
+func openSchema(s *jsonschema.Schema) *jsonschema.Schema {
+	if s == nil {
+		return nil
+	}
+	if s.Properties != nil {
+		// A struct: inference forbids additional properties.
+		s.AdditionalProperties = nil
+	}
+	for _, p := range s.Properties {
+		openSchema(p)
+	}
+	openSchema(s.Items)
+	openSchema(s.AdditionalProperties)
+	return s
+}
+
+// listSchema is the output schema of list tools, whose structured result is a
+// pagedResult whether or not pagination was requested.
+func listSchema[T any]() *jsonschema.Schema {
+	return outputSchema[pagedResult[T]]()
+}
+
+// messageSchema is the output schema of tools that return toolSuccess or
+// toolCreated.
+var messageSchema = outputSchema[messageOutput]()
+
+// structuredResult returns out as the structured content of a successful tool
+// result, with text rendered as indented JSON for clients that only read
+// text content. Null and empty fields are omitted from both, and secrets are
+// masked (see redactSecrets).
+func structuredResult(text, out any) (*mcp.CallToolResult, any, error) {
+	text, err := compactResult(text)
+	if err == nil {
+		out, err = compactResult(out)
+	}
+	if err != nil {
+		return internalError(fmt.Sprintf("failed to marshal response: %v", err))
+	}
+	redactSecrets(text)
+	redactSecrets(out)
+	b, err := json.MarshalIndent(text, "", "  ")
 	if err != nil {
-		return toolError(fmt.Sprintf("failed to marshal response: %v", err))
+		return internalError(fmt.Sprintf("failed to marshal response: %v", err))
 	}
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{&mcp.TextContent{Text: string(b)}},
-	}, nil, nil
+	}, out, nil
+}
+
+// toolResult returns data, an object, as a successful MCP tool result.
+func toolResult(data any) (*mcp.CallToolResult, any, error) {
+	return structuredResult(data, data)
+}
+
+// listResult returns a single page of items. The text content is the bare
+// array; the structured content has the same shape as a paginated result.
+func listResult[T any](items []T) (*mcp.CallToolResult, any, error) {
+	if items == nil {
+		items = []T{}
+	}
+	return structuredResult(items, pagedResult[T]{Items: items, Count: len(items)})
+}
+
+// valueResult returns a single string value as a successful MCP tool result.
+// The value is never redacted: callers gate it themselves.
+func valueResult(v *string) (*mcp.CallToolResult, any, error) {
+	return structuredResult(v, valueOutput{Value: gocloak.PString(v)})
 }
 
 // toolSuccess returns a plain text success message as an MCP tool result.
 func toolSuccess(msg string) (*mcp.CallToolResult, any, error) {
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{&mcp.TextContent{Text: msg}},
-	}, nil, nil
+	}, messageOutput{Message: msg}, nil
+}
+
+// toolCreated is toolSuccess for tools that create an object, carrying its
+// ID in the structured result.
+func toolCreated(id, msg string) (*mcp.CallToolResult, any, error) {
+	res, _, _ := toolSuccess(msg)
+	return res, messageOutput{Message: msg, ID: id}, nil
 }
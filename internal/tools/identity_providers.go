@@ -15,24 +15,27 @@ import (
 
 type listIdentityProvidersArgs struct {
 	Realm string `json:"realm,omitempty" jsonschema:"target realm, uses default if omitted"`
+	viewArgs
 }
 
 func registerListIdentityProviders(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "list_identity_providers",
-		Description: "List all identity providers configured in a realm",
+		Name:         "list_identity_providers",
+		Description:  "List all identity providers configured in a realm",
+		OutputSchema: listSchema[*gocloak.IdentityProviderRepresentation](),
+		Annotations:  readOnly("List identity providers"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args listIdentityProvidersArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("authentication failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
 		idps, err := kc.GC.GetIdentityProviders(ctx, token, realm)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to list identity providers: %v", err))
+			return kcError("failed to list identity providers", err)
 		}
-		return toolResult(idps)
+		return listResult(idps)
 	})
 }
 
@@ -43,22 +46,25 @@ func registerListIdentityProviders(s *mcp.Server, kc *keycloak.Client) {
 type getIdentityProviderArgs struct {
 	Realm string `json:"realm,omitempty" jsonschema:"target realm, uses default if omitted"`
 	Alias string `json:"alias" jsonschema:"alias of the identity provider to retrieve"`
+	viewArgs
 }
 
 func registerGetIdentityProvider(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_identity_provider",
-		Description: "Get a specific identity provider by alias",
+		Name:         "get_identity_provider",
+		Description:  "Get a specific identity provider by alias",
+		OutputSchema: outputSchema[gocloak.IdentityProviderRepresentation](),
+		Annotations:  readOnly("Get identity provider"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getIdentityProviderArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("authentication failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
 		idp, err := kc.GC.GetIdentityProvider(ctx, token, realm, args.Alias)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get identity provider %q: %v", args.Alias, err))
+			return kcError(fmt.Sprintf("failed to get identity provider %q", args.Alias), err)
 		}
 		return toolResult(idp)
 	})
@@ -79,12 +85,14 @@ type createIdentityProviderArgs struct {
 
 func registerCreateIdentityProvider(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_identity_provider",
-		Description: "Create a new identity provider in a realm",
+		Name:         "create_identity_provider",
+		Description:  "Create a new identity provider in a realm",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Create identity provider", false),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args createIdentityProviderArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("authentication failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
@@ -103,9 +111,10 @@ func registerCreateIdentityProvider(s *mcp.Server, kc *keycloak.Client) {
 
 		id, err := kc.GC.CreateIdentityProvider(ctx, token, realm, idpRep)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to create identity provider %q: %v", args.Alias, err))
+			return kcError(fmt.Sprintf("failed to create identity provider %q", args.Alias), err)
 		}
-		return toolSuccess(fmt.Sprintf("Identity provider %q created successfully (id: %s)", args.Alias, id))
+		kc.Invalidate(realm, keycloak.ResourceRealm)
+		return toolCreated(id, fmt.Sprintf("Identity provider %q created successfully (id: %s)", args.Alias, id))
 	})
 }
 
@@ -123,19 +132,21 @@ type updateIdentityProviderArgs struct {
 
 func registerUpdateIdentityProvider(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "update_identity_provider",
-		Description: "Update an existing identity provider",
+		Name:         "update_identity_provider",
+		Description:  "Update an existing identity provider",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Update identity provider", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args updateIdentityProviderArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("authentication failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
 		// Fetch the existing identity provider to preserve unmodified fields.
 		idp, err := kc.GC.GetIdentityProvider(ctx, token, realm, args.Alias)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get identity provider %q: %v", args.Alias, err))
+			return kcError(fmt.Sprintf("failed to get identity provider %q", args.Alias), err)
 		}
 
 		if args.DisplayName != nil {
@@ -150,8 +161,9 @@ func registerUpdateIdentityProvider(s *mcp.Server, kc *keycloak.Client) {
 
 		err = kc.GC.UpdateIdentityProvider(ctx, token, realm, args.Alias, *idp)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to update identity provider %q: %v", args.Alias, err))
+			return kcError(fmt.Sprintf("failed to update identity provider %q", args.Alias), err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceRealm)
 		return toolSuccess(fmt.Sprintf("Identity provider %q updated successfully", args.Alias))
 	})
 }
@@ -167,19 +179,22 @@ type deleteIdentityProviderArgs struct {
 
 func registerDeleteIdentityProvider(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_identity_provider",
-		Description: "Delete an identity provider from a realm",
+		Name:         "delete_identity_provider",
+		Description:  "Delete an identity provider from a realm",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete identity provider", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args deleteIdentityProviderArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("authentication failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
 		err = kc.GC.DeleteIdentityProvider(ctx, token, realm, args.Alias)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to delete identity provider %q: %v", args.Alias, err))
+			return kcError(fmt.Sprintf("failed to delete identity provider %q", args.Alias), err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceRealm)
 		return toolSuccess(fmt.Sprintf("Identity provider %q deleted successfully", args.Alias))
 	})
 }
@@ -191,24 +206,27 @@ func registerDeleteIdentityProvider(s *mcp.Server, kc *keycloak.Client) {
 type listIdentityProviderMappersArgs struct {
 	Realm string `json:"realm,omitempty" jsonschema:"target realm, uses default if omitted"`
 	Alias string `json:"alias" jsonschema:"alias of the identity provider"`
+	viewArgs
 }
 
 func registerListIdentityProviderMappers(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "list_identity_provider_mappers",
-		Description: "List all mappers for an identity provider",
+		Name:         "list_identity_provider_mappers",
+		Description:  "List all mappers for an identity provider",
+		OutputSchema: listSchema[*gocloak.IdentityProviderMapper](),
+		Annotations:  readOnly("List identity provider mappers"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args listIdentityProviderMappersArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("authentication failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
 		mappers, err := kc.GC.GetIdentityProviderMappers(ctx, token, realm, args.Alias)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to list mappers for identity provider %q: %v", args.Alias, err))
+			return kcError(fmt.Sprintf("failed to list mappers for identity provider %q", args.Alias), err)
 		}
-		return toolResult(mappers)
+		return listResult(mappers)
 	})
 }
 
@@ -226,12 +244,14 @@ type createIdentityProviderMapperArgs struct {
 
 func registerCreateIdentityProviderMapper(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_identity_provider_mapper",
-		Description: "Create a mapper for an identity provider",
+		Name:         "create_identity_provider_mapper",
+		Description:  "Create a mapper for an identity provider",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Create identity provider mapper", false),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args createIdentityProviderMapperArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("authentication failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
@@ -244,9 +264,10 @@ func registerCreateIdentityProviderMapper(s *mcp.Server, kc *keycloak.Client) {
 
 		id, err := kc.GC.CreateIdentityProviderMapper(ctx, token, realm, args.Alias, mapper)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to create mapper %q for identity provider %q: %v", args.Name, args.Alias, err))
+			return kcError(fmt.Sprintf("failed to create mapper %q for identity provider %q", args.Name, args.Alias), err)
 		}
-		return toolSuccess(fmt.Sprintf("Mapper %q created successfully for identity provider %q (id: %s)", args.Name, args.Alias, id))
+		kc.Invalidate(realm, keycloak.ResourceRealm)
+		return toolCreated(id, fmt.Sprintf("Mapper %q created successfully for identity provider %q (id: %s)", args.Name, args.Alias, id))
 	})
 }
 
@@ -262,19 +283,22 @@ type deleteIdentityProviderMapperArgs struct {
 
 func registerDeleteIdentityProviderMapper(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_identity_provider_mapper",
-		Description: "Delete a mapper from an identity provider",
+		Name:         "delete_identity_provider_mapper",
+		Description:  "Delete a mapper from an identity provider",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete identity provider mapper", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args deleteIdentityProviderMapperArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("authentication failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
 		err = kc.GC.DeleteIdentityProviderMapper(ctx, token, realm, args.Alias, args.MapperID)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to delete mapper %q from identity provider %q: %v", args.MapperID, args.Alias, err))
+			return kcError(fmt.Sprintf("failed to delete mapper %q from identity provider %q", args.MapperID, args.Alias), err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceRealm)
 		return toolSuccess(fmt.Sprintf("Mapper %q deleted successfully from identity provider %q", args.MapperID, args.Alias))
 	})
 }
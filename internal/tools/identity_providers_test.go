@@ -0,0 +1,26 @@
+package tools
+
+import "testing"
+
+func TestIdentityProviderLifecycle(t *testing.T) {
+	h := newHarness(t)
+	args := map[string]any{"alias": "github", "provider_id": "github", "display_name": "GitHub", "config": map[string]string{"clientId": "x"}}
+
+	if msg := h.ok("create_identity_provider", args); idFrom(t, msg) != "github" {
+		t.Fatalf("create_identity_provider = %s, want the alias as id", msg)
+	}
+	h.fail("create_identity_provider", args, codeConflict)
+	h.ok("update_identity_provider", map[string]any{"alias": "github", "display_name": "GitHub SSO"})
+
+	var idp map[string]any
+	h.okJSON("get_identity_provider", map[string]any{"alias": "github"}, &idp)
+	if idp["displayName"] != "GitHub SSO" || idp["providerId"] != "github" {
+		t.Fatalf("identity provider = %v", idp)
+	}
+	var idps []map[string]any
+	h.okJSON("list_identity_providers", nil, &idps)
+	assertNames(t, "identity providers", idps, "alias", "github")
+
+	h.ok("delete_identity_provider", map[string]any{"alias": "github"})
+	h.fail("get_identity_provider", map[string]any{"alias": "github"}, codeNotFound)
+}
@@ -0,0 +1,165 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/config"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
+)
+
+type impersonateUserArgs struct {
+	Realm  string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
+	UserID string `json:"user_id"         jsonschema:"User ID, username or email"`
+	Reason string `json:"reason"          jsonschema:"Why the user is impersonated, e.g. a support ticket; recorded in the logs and audit trail"`
+}
+
+// impersonation is the session Keycloak opened as the user. The session is
+// ended before the result is returned: its cookies cannot be handed to the
+// client, and the server keeps none, so nothing could use it.
+type impersonation struct {
+	Realm        string                `json:"realm"`
+	UserID       string                `json:"user_id"`
+	Username     string                `json:"username"`
+	Redirect     string                `json:"redirect"      jsonschema:"Where Keycloak would have sent the impersonating browser, usually the account console"`
+	SameRealm    bool                  `json:"same_realm"    jsonschema:"Whether the admin account is in the user's realm, in which case Keycloak ended the admin's own session"`
+	Cookies      []impersonationCookie `json:"cookies"       jsonschema:"Session cookies Keycloak set; their values are never returned"`
+	SessionEnded bool                  `json:"session_ended" jsonschema:"Whether the impersonated session was logged out again"`
+	Note         string                `json:"note"`
+}
+
+type impersonationCookie struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Path    string `json:"path,omitempty"`
+	Expires string `json:"expires,omitempty"`
+}
+
+// minReasonLength keeps reasons from being a placeholder such as "x".
+const minReasonLength = 10
+
+// impersonationNote tells the caller how to actually act as the user.
+const impersonationNote = "The impersonated session was ended because its cookies cannot be handed to the client. " +
+	"To browse as the user, use Impersonate on the user in the Keycloak admin console."
+
+func registerImpersonateUser(s *mcp.Server, kc *keycloak.Client, cfg *config.Config) {
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "impersonate_user",
+		Description: "Impersonate a user through Keycloak's impersonation endpoint, checking that the admin may act as them, " +
+			"and return the redirect and session details. The session cannot be used from here and is logged out right away; " +
+			"to browse as the user, use Impersonate in the admin console. " +
+			"Requires a reason, which is logged and audited. Only available when KEYCLOAK_ALLOW_IMPERSONATION is enabled",
+		OutputSchema: outputSchema[impersonation](),
+		Annotations:  destructive("Impersonate user", false),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args impersonateUserArgs) (*mcp.CallToolResult, any, error) {
+		if !cfg.AllowImpersonation {
+			return toolError(&toolErr{
+				Code:    codeForbidden,
+				Message: "impersonation is disabled on this server",
+				Hint:    "Set KEYCLOAK_ALLOW_IMPERSONATION=true to allow impersonate_user.",
+			})
+		}
+		if len(strings.TrimSpace(args.Reason)) < minReasonLength {
+			return validationError(fmt.Sprintf("reason must explain the impersonation in at least %d characters, e.g. a support ticket", minReasonLength))
+		}
+		token, err := kc.Token(ctx)
+		if err != nil {
+			return tokenError(err)
+		}
+		realm := kc.ResolveRealm(args.Realm)
+		userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+		}
+		user, err := kc.GC.GetUserByID(ctx, token, realm, userID)
+		if err != nil {
+			return kcError("failed to get user", err)
+		}
+
+		before, err := kc.GC.GetUserSessions(ctx, token, realm, userID)
+		if err != nil {
+			return kcError("failed to list user sessions", err)
+		}
+
+		// gocloak doesn't expose impersonation, so use raw POST.
+		var body struct {
+			SameRealm bool   `json:"sameRealm"`
+			Redirect  string `json:"redirect"`
+		}
+		resp, err := kc.GC.GetRequestWithBearerAuth(ctx, token).
+			SetResult(&body).
+			Post(kc.AdminURL(realm, "/users/"+userID+"/impersonation"))
+		if err != nil {
+			return kcError("failed to impersonate user", err)
+		}
+		if resp.IsError() {
+			return kcError("failed to impersonate user", apiError(resp.StatusCode(), resp.Status(), resp.Body()))
+		}
+
+		log.Ctx(ctx).Warn().
+			Bool("impersonation", true).
+			Str("realm", realm).
+			Str("user_id", userID).
+			Str("username", gocloak.PString(user.Username)).
+			Str("reason", args.Reason).
+			Msg("impersonating user")
+
+		if body.SameRealm {
+			// Keycloak logged the admin out along with the session the token
+			// belongs to.
+			kc.InvalidateToken()
+			if token, err = kc.Token(ctx); err != nil {
+				return tokenError(err)
+			}
+		}
+
+		out := impersonation{
+			Realm:     realm,
+			UserID:    userID,
+			Username:  gocloak.PString(user.Username),
+			Redirect:  body.Redirect,
+			SameRealm: body.SameRealm,
+			Cookies:   []impersonationCookie{},
+			Note:      impersonationNote,
+		}
+		if err := endNewSessions(ctx, kc, token, realm, userID, before); err != nil {
+			return kcError("user impersonated but failed to end the impersonated session", err)
+		}
+		out.SessionEnded = true
+		for _, c := range resp.Cookies() {
+			cookie := impersonationCookie{Name: c.Name, Value: redactedValue, Path: c.Path}
+			if !c.Expires.IsZero() {
+				cookie.Expires = c.Expires.UTC().Format(time.RFC3339)
+			}
+			out.Cookies = append(out.Cookies, cookie)
+		}
+		return toolResult(out)
+	})
+}
+
+// endNewSessions logs out the user's sessions that are not in before, which
+// is the session impersonation opened.
+func endNewSessions(ctx context.Context, kc *keycloak.Client, token, realm, userID string, before []*gocloak.UserSessionRepresentation) error {
+	known := map[string]bool{}
+	for _, sess := range before {
+		known[gocloak.PString(sess.ID)] = true
+	}
+	after, err := kc.GC.GetUserSessions(ctx, token, realm, userID)
+	if err != nil {
+		return err
+	}
+	for _, sess := range after {
+		if id := gocloak.PString(sess.ID); !known[id] {
+			if err := kc.GC.LogoutUserSession(ctx, token, realm, id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/config"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloaktest"
+)
+
+func TestImpersonateUser(t *testing.T) {
+	h := newHarness(t)
+	h.fake.AddUser("acme", "alice", "alice@example.com")
+	args := map[string]any{"user_id": "alice", "reason": "Reproducing ticket SUP-1234"}
+
+	e := h.fail("impersonate_user", args, codeForbidden)
+	if !strings.Contains(e.Hint, "KEYCLOAK_ALLOW_IMPERSONATION") {
+		t.Fatalf("hint = %q", e.Hint)
+	}
+
+	h = newHarnessWithConfig(t, func(cfg *config.Config) { cfg.AllowImpersonation = true })
+	id := h.fake.AddUser("acme", "alice", "alice@example.com")
+	h.fail("impersonate_user", map[string]any{"user_id": "alice", "reason": "debug"}, codeValidation)
+	h.fail("impersonate_user", map[string]any{"user_id": "nobody", "reason": "Reproducing ticket SUP-1234"}, codeNotFound)
+
+	existing := h.fake.AddUserSession("acme", id)
+
+	var out impersonation
+	h.okJSON("impersonate_user", args, &out)
+	if out.UserID != id || out.Username != "alice" || !strings.HasSuffix(out.Redirect, "/realms/acme/account") || len(out.Cookies) != 2 {
+		t.Fatalf("impersonation = %+v", out)
+	}
+	// The impersonated session is ended; the user's own session is kept.
+	var sessions []map[string]any
+	h.okJSON("get_user_sessions", map[string]any{"user_id": id}, &sessions)
+	if !out.SessionEnded || !strings.Contains(out.Note, "admin console") || len(sessions) != 1 || sessions[0]["id"] != existing {
+		t.Fatalf("session_ended = %v, note = %q, sessions = %v", out.SessionEnded, out.Note, sessions)
+	}
+	// Not even KEYCLOAK_REVEAL_SECRETS reveals the session cookies.
+	h = newHarnessWithConfig(t, func(cfg *config.Config) { cfg.AllowImpersonation, cfg.RevealSecrets = true, true })
+	h.fake.AddUser("acme", "alice", "alice@example.com")
+	h.okJSON("impersonate_user", args, &out)
+	for _, c := range out.Cookies {
+		if c.Value != redactedValue {
+			t.Fatalf("cookie %s = %q, want it redacted", c.Name, c.Value)
+		}
+	}
+}
+
+// cookieSniffer records the Cookie headers of the requests it forwards.
+type cookieSniffer struct {
+	mu      sync.Mutex
+	cookies []string
+}
+
+func (c *cookieSniffer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if v := req.Header.Get("Cookie"); v != "" {
+		c.mu.Lock()
+		c.cookies = append(c.cookies, req.Method+" "+req.URL.Path+": "+v)
+		c.mu.Unlock()
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestImpersonationCookiesNotReplayed(t *testing.T) {
+	fake := keycloaktest.NewServer()
+	t.Cleanup(fake.Close)
+	cfg := fake.Config()
+	cfg.AllowImpersonation = true
+	sniffer := &cookieSniffer{}
+	cs := connect(context.Background(), cfg, sniffer, nil)
+	t.Cleanup(func() { cs.Close() })
+	h := &harness{t: t, fake: fake, cs: cs}
+	fake.AddUser("master", "alice", "")
+
+	// The user is in the admin's realm, so the server logs in again under
+	// /realms/master, the path of the impersonated session's cookies.
+	var out impersonation
+	h.okJSON("impersonate_user", map[string]any{"realm": "master", "user_id": "alice", "reason": "Reproducing ticket SUP-1234"}, &out)
+	if !out.SameRealm {
+		t.Fatalf("impersonation = %+v, want same_realm", out)
+	}
+	h.ok("get_user", map[string]any{"realm": "master", "user_id": "alice"})
+	if len(sniffer.cookies) != 0 {
+		t.Fatalf("requests sent cookies: %q", sniffer.cookies)
+	}
+}
+
+func TestImpersonationIsDestructive(t *testing.T) {
+	h := newHarness(t)
+	res, err := h.cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range res.Tools {
+		if tool.Name == "impersonate_user" {
+			if a := tool.Annotations; a == nil || a.DestructiveHint == nil || !*a.DestructiveHint {
+				t.Fatalf("impersonate_user annotations = %+v, want destructive", a)
+			}
+			return
+		}
+	}
+	t.Fatal("impersonate_user is not registered")
+}
+
+func TestConfirmImpersonation(t *testing.T) {
+	h, asked := newElicitingHarness(t, func(cfg *config.Config) { cfg.AllowImpersonation = true }, func(*mcp.ElicitParams) *mcp.ElicitResult {
+		return &mcp.ElicitResult{Action: "decline"}
+	})
+	h.fake.AddUser("acme", "alice", "alice@example.com")
+
+	h.fail("impersonate_user", map[string]any{"user_id": "alice", "reason": "Reproducing ticket SUP-1234"}, codeCancelled)
+	if len(*asked) != 1 || !strings.Contains((*asked)[0], "alice <alice@example.com>") || !strings.Contains((*asked)[0], "SUP-1234") {
+		t.Fatalf("confirmation = %q, want the user and reason named", *asked)
+	}
+}
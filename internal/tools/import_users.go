@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/userimport"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type importUsersArgs struct {
+	Realm       string           `json:"realm,omitempty"       jsonschema:"Realm name (uses default if omitted)"`
+	Rows        []userimport.Row `json:"rows,omitempty"        jsonschema:"Users to import"`
+	CSV         string           `json:"csv,omitempty"         jsonschema:"Users to import as CSV with a header row, instead of rows. Columns are named like the row fields, plus attr.<name> for attributes; lists are separated by ';' and client roles are written clientId:role"`
+	DryRun      bool             `json:"dry_run,omitempty"     jsonschema:"Only validate the rows"`
+	Concurrency int              `json:"concurrency,omitempty" jsonschema:"Rows imported at once (default 4, max 16)"`
+}
+
+func registerUserImportTools(s *mcp.Server, kc *keycloak.Client) {
+	mcp.AddTool(s, &mcp.Tool{
+		Name: "import_users",
+		Description: "Create users in bulk, with attributes, groups, realm and client roles, required actions and a temporary password. " +
+			"Every row is validated first and nothing is created if one is invalid. Existing usernames are skipped, so the import can be re-run. " +
+			"Returns a report with the status of every row",
+		OutputSchema: outputSchema[userimport.Report](),
+		Annotations:  additive("Import users", true),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args importUsersArgs) (*mcp.CallToolResult, any, error) {
+		rows := args.Rows
+		switch {
+		case args.CSV != "" && len(rows) > 0:
+			return validationError("pass either rows or csv, not both")
+		case args.CSV != "":
+			var err error
+			if rows, err = userimport.Parse(strings.NewReader(args.CSV), userimport.FormatCSV); err != nil {
+				return validationError(err.Error())
+			}
+		}
+		if len(rows) == 0 {
+			return validationError("no users to import: pass rows or csv")
+		}
+
+		report, err := userimport.Import(ctx, kc, rows, userimport.Options{
+			Realm:       args.Realm,
+			DryRun:      args.DryRun,
+			Concurrency: args.Concurrency,
+			Progress: func(done, total int) {
+				reportProgress(ctx, done, total, "")
+			},
+		})
+		if err != nil {
+			return kcError("failed to import users", err)
+		}
+		return toolResult(report)
+	})
+}
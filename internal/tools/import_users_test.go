@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/userimport"
+)
+
+func TestImportUsers(t *testing.T) {
+	h := newHarness(t)
+	h.fake.AddGroup("acme", h.fake.AddGroup("acme", "", "eng"), "platform")
+	h.fake.AddRealmRole("acme", "deployer")
+	h.fake.AddClient("acme", "billing")
+	h.ok("create_client_role", map[string]any{"client_id": "billing", "name": "invoice-reader"})
+	h.fake.AddUser("acme", "alice", "alice@example.com")
+
+	var actions []map[string]any
+	h.okJSON("list_required_actions", nil, &actions)
+	if len(actions) == 0 {
+		t.Fatal("no required actions")
+	}
+
+	csv := "username,email,first_name,groups,realm_roles,client_roles,required_actions,temporary_password,attr.department\n" +
+		"alice,alice@example.com,Alice,,,,,,\n" +
+		"bob,bob@example.com,Bob,/eng/platform,deployer,billing:invoice-reader,UPDATE_PROFILE;VERIFY_EMAIL,Welcome-1,finance\n" +
+		"carol,,Carol,eng/platform,,,,,\n"
+
+	// A dry run validates without creating anyone.
+	var report userimport.Report
+	h.okJSON("import_users", map[string]any{"csv": csv, "dry_run": true}, &report)
+	if report.Total != 3 || report.Invalid != 0 || report.Rows[1].Status != userimport.StatusValid {
+		t.Fatalf("dry run report = %+v", report)
+	}
+	h.fail("get_user", map[string]any{"user_id": "bob"}, codeNotFound)
+
+	h.okJSON("import_users", map[string]any{"csv": csv, "concurrency": 2}, &report)
+	if report.Created != 2 || report.Skipped != 1 || report.Rows[0].Status != userimport.StatusSkipped {
+		t.Fatalf("report = %+v", report)
+	}
+
+	var bob struct {
+		ID              string              `json:"id"`
+		Attributes      map[string][]string `json:"attributes"`
+		RequiredActions []string            `json:"requiredActions"`
+	}
+	h.okJSON("get_user", map[string]any{"user_id": "bob"}, &bob)
+	if bob.ID != report.Rows[1].UserID || bob.Attributes["department"][0] != "finance" || len(bob.RequiredActions) != 2 {
+		t.Fatalf("bob = %+v", bob)
+	}
+	var items []map[string]any
+	h.okJSON("get_user_groups", map[string]any{"user_id": "bob"}, &items)
+	assertNames(t, "bob's groups", items, "path", "/eng/platform")
+	h.okJSON("get_user_realm_roles", map[string]any{"user_id": "bob"}, &items)
+	assertNames(t, "bob's realm roles", items, "name", "deployer")
+	h.okJSON("get_user_client_roles", map[string]any{"user_id": "bob", "client_id": "billing"}, &items)
+	assertNames(t, "bob's client roles", items, "name", "invoice-reader")
+	h.okJSON("get_user_credentials", map[string]any{"user_id": "bob"}, &items)
+	if len(items) != 1 || items[0]["temporary"] != true {
+		t.Fatalf("bob's credentials = %v", items)
+	}
+
+	// Running the import again changes nothing.
+	h.okJSON("import_users", map[string]any{"csv": csv}, &report)
+	if report.Skipped != 3 || report.Created != 0 {
+		t.Fatalf("re-run report = %+v", report)
+	}
+}
+
+func TestImportUsersValidatesFirst(t *testing.T) {
+	h := newHarness(t)
+	rows := []map[string]any{
+		{"username": "dave", "groups": []string{"/eng"}},
+		{"username": "erin", "email": "not-an-address", "required_actions": []string{"TERMS_AND_CONDITIONS"}},
+		{"username": "Dave"},
+	}
+
+	var report userimport.Report
+	h.okJSON("import_users", map[string]any{"rows": rows}, &report)
+	if report.Invalid != 3 || report.Created != 0 {
+		t.Fatalf("report = %+v", report)
+	}
+	for _, r := range report.Rows {
+		if r.Status != userimport.StatusInvalid || r.Error == "" {
+			t.Errorf("row %d = %+v, want invalid with a reason", r.Row, r)
+		}
+	}
+	h.fail("get_user", map[string]any{"user_id": "erin"}, codeNotFound)
+
+	h.fail("import_users", map[string]any{}, codeValidation)
+	h.fail("import_users", map[string]any{"csv": "username,shoe_size\nfrank,44\n"}, codeValidation)
+}
@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// pageSize is the number of items requested from Keycloak per page when
+// following pages automatically.
+const pageSize = 100
+
+var (
+	errBadCursor   = errors.New("invalid cursor")
+	errCursorScope = errors.New("cursor belongs to a different realm or filter")
+)
+
+// pageArgs are embedded in list tool arguments to enable automatic pagination.
+type pageArgs struct {
+	All    bool   `json:"all,omitempty"    jsonschema:"Follow pages automatically up to the server's result cap"`
+	Cursor string `json:"cursor,omitempty" jsonschema:"Opaque continuation cursor from a previous truncated response"`
+}
+
+func (p pageArgs) paginated() bool {
+	return p.All || p.Cursor != ""
+}
+
+// pagedResult is returned by list tools when pagination is requested.
+type pagedResult[T any] struct {
+	Items      []T    `json:"items"`
+	Count      int    `json:"count"`
+	Total      *int   `json:"total,omitempty"`
+	Truncated  bool   `json:"truncated"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// pageQuery identifies the listing a cursor continues: the tool, and a hash
+// of the realm and filter arguments so that a cursor is not replayed against
+// a different query.
+type pageQuery struct {
+	tool  string
+	scope string
+}
+
+// newPageQuery returns the query of tool in realm with the given filters,
+// which must not include the page position (first, max, cursor).
+func newPageQuery(tool, realm string, filters ...any) pageQuery {
+	b, _ := json.Marshal(append([]any{realm}, filters...))
+	sum := sha256.Sum256(b)
+	return pageQuery{tool: tool, scope: base64.RawURLEncoding.EncodeToString(sum[:8])}
+}
+
+type cursor struct {
+	Tool   string `json:"t"`
+	Scope  string `json:"s"`
+	Offset int    `json:"o"`
+}
+
+func encodeCursor(q pageQuery, offset int) string {
+	b, _ := json.Marshal(cursor{Tool: q.tool, Scope: q.scope, Offset: offset})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(q pageQuery, s string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, errBadCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Tool != q.tool || c.Offset < 0 {
+		return 0, errBadCursor
+	}
+	if c.Scope != q.scope {
+		return 0, errCursorScope
+	}
+	return c.Offset, nil
+}
+
+// paginate follows pages via fetch, starting at the cursor offset (or first
+// when no cursor is given), until Keycloak runs out of results or limit items
+// have been collected. The last page asks for one extra item so that a result
+// of exactly limit items is not reported as truncated. Progress is reported
+// after every page. The context is checked between pages; when it ends,
+// paginate returns an interruptedError with a cursor that resumes at the
+// first page not fetched.
+func paginate[T any](ctx context.Context, q pageQuery, args pageArgs, first *int, limit int, fetch func(first, max int) ([]T, error)) (*pagedResult[T], error) {
+	offset := 0
+	if first != nil {
+		offset = *first
+	}
+	if args.Cursor != "" {
+		o, err := decodeCursor(q, args.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		offset = o
+	}
+
+	res := &pagedResult[T]{Items: []T{}}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, &interruptedError{Done: len(res.Items), Cursor: encodeCursor(q, offset), Err: err}
+		}
+		remaining := limit - len(res.Items)
+		n := min(pageSize, remaining)
+		last := n == remaining
+		want := n
+		if last {
+			// One item past the limit tells whether anything is left.
+			want++
+		}
+		items, err := fetch(offset, want)
+		if err != nil {
+			// gocloak does not wrap context errors, so check the context
+			// to tell a cancelled request from a failed one.
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, &interruptedError{Done: len(res.Items), Cursor: encodeCursor(q, offset), Err: ctxErr}
+			}
+			return nil, err
+		}
+		if last && len(items) > n {
+			items = items[:n]
+			res.Truncated = true
+		}
+		res.Items = append(res.Items, items...)
+		offset += len(items)
+		reportProgress(ctx, len(res.Items), 0, fmt.Sprintf("%s: fetched %d items", q.tool, len(res.Items)))
+		if res.Truncated {
+			res.NextCursor = encodeCursor(q, offset)
+			break
+		}
+		if last || len(items) < n {
+			break
+		}
+	}
+	res.Count = len(res.Items)
+	return res, nil
+}
+
+// resultLimit caps an optional caller-supplied max at the server's hard cap.
+func resultLimit(max *int, hardCap int) int {
+	if max != nil && *max > 0 && *max < hardCap {
+		return *max
+	}
+	return hardCap
+}
+
+// pageError maps a pagination failure to a tool error.
+func pageError(action string, err error) (*mcp.CallToolResult, any, error) {
+	if errors.Is(err, errBadCursor) || errors.Is(err, errCursorScope) {
+		return validationError(fmt.Sprintf("%s: %v; pass the next_cursor value from the previous response unchanged", action, err))
+	}
+	return kcError(action, err)
+}
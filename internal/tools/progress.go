@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type progressKey struct{}
+
+// progress sends notifications/progress for one tool call.
+type progress struct {
+	session *mcp.ServerSession
+	token   any
+}
+
+// trackProgress makes the progress token of each tool call available to
+// reportProgress. Calls without a token report nothing.
+func trackProgress() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if call, ok := req.(*mcp.CallToolRequest); ok && call.Session != nil {
+				if token := call.Params.GetProgressToken(); token != nil {
+					ctx = context.WithValue(ctx, progressKey{}, &progress{session: call.Session, token: token})
+				}
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// reportProgress tells the client that done of total units of work are
+// complete; total is 0 when unknown. Notification failures are ignored: they
+// must not abort the work.
+func reportProgress(ctx context.Context, done, total int, msg string) {
+	p, ok := ctx.Value(progressKey{}).(*progress)
+	if !ok {
+		return
+	}
+	_ = p.session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+		ProgressToken: p.token,
+		Progress:      float64(done),
+		Total:         float64(total),
+		Message:       msg,
+	})
+}
+
+// interruptedError reports work that stopped part way through because the
+// call's context ended, typically because the client cancelled it.
+type interruptedError struct {
+	Done   int    // units of work completed before stopping
+	Cursor string // resumes where the work stopped, if the tool supports it
+	Err    error
+}
+
+func (e *interruptedError) Error() string {
+	msg := fmt.Sprintf("stopped after %d items: %v", e.Done, e.Err)
+	if e.Cursor != "" {
+		msg += fmt.Sprintf("; pass cursor %q to resume", e.Cursor)
+	}
+	return msg
+}
+
+func (e *interruptedError) Unwrap() error {
+	return e.Err
+}
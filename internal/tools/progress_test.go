@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloaktest"
+)
+
+func TestProgressNotifications(t *testing.T) {
+	fake := keycloaktest.NewServer()
+	t.Cleanup(fake.Close)
+	for i := range 250 {
+		fake.AddUser("acme", fmt.Sprintf("user%03d", i), "")
+	}
+
+	var mu sync.Mutex
+	var got []float64
+	cs := connect(context.Background(), fake.Config(), nil, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			if req.Params.ProgressToken == "users" {
+				got = append(got, req.Params.Progress)
+			}
+		},
+	})
+	t.Cleanup(func() { cs.Close() })
+
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{
+		Meta:      mcp.Meta{"progressToken": "users"},
+		Name:      "list_users",
+		Arguments: map[string]any{"all": true},
+	})
+	if err != nil || res.IsError {
+		t.Fatalf("list_users: %v %s", err, resultText(res))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(got) != "[100 200 250]" {
+		t.Fatalf("progress = %v, want one notification per page", got)
+	}
+}
+
+func TestPaginateInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fetch := func(first, max int) ([]int, error) {
+		if first > 0 {
+			cancel()
+			return nil, errors.New("request aborted")
+		}
+		return make([]int, max), nil
+	}
+
+	_, err := paginate(ctx, newPageQuery("list_users", "acme"), pageArgs{All: true}, nil, 1000, fetch)
+	var intErr *interruptedError
+	if !errors.As(err, &intErr) || intErr.Done != pageSize || !errors.Is(err, context.Canceled) {
+		t.Fatalf("paginate error = %v", err)
+	}
+	if offset, err := decodeCursor(newPageQuery("list_users", "acme"), intErr.Cursor); err != nil || offset != pageSize {
+		t.Fatalf("resume cursor offset = %d, %v", offset, err)
+	}
+	if e := classifyError("failed to list users", err); e.Code != codeCancelled {
+		t.Fatalf("classified as %s, want %s", e.Code, codeCancelled)
+	}
+}
+
+func TestPaginateLastPageAtLimit(t *testing.T) {
+	items := make([]int, 2*pageSize)
+	fetch := func(first, max int) ([]int, error) {
+		return items[min(first, len(items)):min(first+max, len(items))], nil
+	}
+
+	res, err := paginate(context.Background(), newPageQuery("list_users", "acme"), pageArgs{All: true}, nil, 2*pageSize, fetch)
+	if err != nil || res.Count != 2*pageSize || res.Truncated || res.NextCursor != "" {
+		t.Fatalf("exact limit: %+v, %v", res, err)
+	}
+	res, err = paginate(context.Background(), newPageQuery("list_users", "acme"), pageArgs{All: true}, nil, pageSize, fetch)
+	if err != nil || res.Count != pageSize || !res.Truncated {
+		t.Fatalf("below total: %+v, %v", res, err)
+	}
+	if offset, _ := decodeCursor(newPageQuery("list_users", "acme"), res.NextCursor); offset != pageSize {
+		t.Fatalf("next cursor offset = %d, want %d", offset, pageSize)
+	}
+}
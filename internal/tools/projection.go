@@ -0,0 +1,194 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// viewArgs is embedded in the argument structs of read tools that return
+// Keycloak representations. The arguments are applied to the tool's result by
+// the projectResults middleware, so handlers do not read them.
+type viewArgs struct {
+	Fields []string `json:"fields,omitempty" jsonschema:"Return only these top-level fields of each object (e.g. [\"id\",\"username\"])"`
+	View   string   `json:"view,omitempty"   jsonschema:"summary returns only identifying fields of each object; full (default) returns everything"`
+}
+
+const (
+	viewFull    = "full"
+	viewSummary = "summary"
+)
+
+// summaryFields are the fields kept by view=summary. They identify an object
+// and its state across representations: users, clients, roles, groups,
+// realms, scopes, identity providers, components, sessions and events.
+var summaryFields = map[string]bool{
+	"id": true, "name": true, "alias": true, "description": true, "enabled": true, "type": true,
+	// users
+	"username": true, "email": true, "emailVerified": true, "firstName": true, "lastName": true,
+	// clients and scopes
+	"clientId": true, "protocol": true, "publicClient": true,
+	// roles and groups
+	"composite": true, "clientRole": true, "path": true, "subGroupCount": true,
+	// realms, identity providers and components
+	"realm": true, "displayName": true, "providerId": true, "parentId": true,
+	// sessions and events
+	"userId": true, "ipAddress": true, "start": true, "lastAccess": true, "time": true,
+}
+
+// eachRecord calls fn for every object in a tool result: the elements of an
+// array, the items of a paginated result, or the result itself.
+func eachRecord(v any, fn func(map[string]any)) {
+	switch t := v.(type) {
+	case []any:
+		for _, e := range t {
+			if m, ok := e.(map[string]any); ok {
+				fn(m)
+			}
+		}
+	case map[string]any:
+		if items, ok := t["items"].([]any); ok {
+			if _, paged := t["count"]; paged {
+				eachRecord(items, fn)
+				return
+			}
+		}
+		fn(t)
+	}
+}
+
+// compact removes null and empty values from obj, recursively. false and 0
+// are kept: they are meaningful in Keycloak representations.
+func compact(obj map[string]any) {
+	for k, v := range obj {
+		if empty(compactValue(v)) {
+			delete(obj, k)
+		}
+	}
+}
+
+func compactValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		compact(t)
+	case []any:
+		for _, e := range t {
+			compactValue(e)
+		}
+	}
+	return v
+}
+
+func empty(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case []any:
+		return len(t) == 0
+	case map[string]any:
+		return len(t) == 0
+	}
+	return false
+}
+
+// generic round-trips v through JSON so that it can be walked as maps and
+// slices.
+func generic(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	err = json.Unmarshal(b, &out)
+	return out, err
+}
+
+// compactResult returns v as generic JSON with null and empty fields removed
+// from every record.
+func compactResult(v any) (any, error) {
+	g, err := generic(v)
+	if err != nil {
+		return nil, err
+	}
+	eachRecord(g, compact)
+	return g, nil
+}
+
+// projection is the fields/view selection requested for a tool call.
+type projection struct {
+	Fields []string `json:"fields"`
+	View   string   `json:"view"`
+}
+
+func (p projection) empty() bool {
+	return len(p.Fields) == 0 && (p.View == "" || p.View == viewFull)
+}
+
+// apply keeps only the requested fields of obj.
+func (p projection) apply(obj map[string]any) {
+	keep := summaryFields
+	if len(p.Fields) > 0 {
+		keep = make(map[string]bool, len(p.Fields))
+		for _, f := range p.Fields {
+			keep[f] = true
+		}
+	}
+	for k := range obj {
+		if !keep[k] {
+			delete(obj, k)
+		}
+	}
+}
+
+// projectResults applies the fields and view arguments of read tools to both
+// the text and the structured content of successful results.
+func projectResults() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok {
+				return next(ctx, method, req)
+			}
+			var p projection
+			_ = json.Unmarshal(call.Params.Arguments, &p)
+			if p.empty() {
+				return next(ctx, method, req)
+			}
+			if p.View != "" && p.View != viewFull && p.View != viewSummary {
+				res, _, _ := validationError(fmt.Sprintf("view must be %q or %q, got %q", viewSummary, viewFull, p.View))
+				return res, nil
+			}
+
+			res, err := next(ctx, method, req)
+			r, ok := res.(*mcp.CallToolResult)
+			if err != nil || !ok || r.IsError {
+				return res, err
+			}
+			if r.StructuredContent != nil {
+				if g, err := generic(r.StructuredContent); err == nil {
+					eachRecord(g, p.apply)
+					r.StructuredContent = g
+				}
+			}
+			for _, c := range r.Content {
+				t, ok := c.(*mcp.TextContent)
+				if !ok {
+					continue
+				}
+				var g any
+				if json.Unmarshal([]byte(t.Text), &g) != nil {
+					continue
+				}
+				eachRecord(g, p.apply)
+				if b, err := json.MarshalIndent(g, "", "  "); err == nil {
+					t.Text = string(b)
+				}
+			}
+			return r, nil
+		}
+	}
+}
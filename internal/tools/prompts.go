@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// runbook is an MCP prompt that expands into step-by-step instructions for a
+// recurring admin procedure. Steps name the tools to call, so the text must
+// be kept in line with the tool names in this package.
+type runbook struct {
+	prompt *mcp.Prompt
+	steps  func(args map[string]string) string
+}
+
+var realmArgument = &mcp.PromptArgument{
+	Name:        "realm",
+	Description: "Keycloak realm (uses the server's default realm if omitted)",
+}
+
+var runbooks = []runbook{
+	{
+		prompt: &mcp.Prompt{
+			Name:        "onboard_oidc_application",
+			Title:       "Onboard an OIDC application",
+			Description: "Create and configure a confidential OpenID Connect client",
+			Arguments: []*mcp.PromptArgument{
+				realmArgument,
+				{Name: "client_id", Description: "clientId of the new application", Required: true},
+				{Name: "redirect_uri", Description: "Redirect URI, e.g. https://app.example.com/callback"},
+			},
+		},
+		steps: func(a map[string]string) string {
+			redirect := "<ask the requester for the exact redirect URI; never use a bare *>"
+			if a["redirect_uri"] != "" {
+				redirect = fmt.Sprintf("[%q]", a["redirect_uri"])
+			}
+			return fmt.Sprintf(`Onboard the OIDC application %[1]q in %[2]s.
+
+1. Call `+"`get_realm`"+`%[3]s to confirm the realm exists and note its token lifespans.
+2. Call `+"`list_clients`"+` with client_id %[1]q%[4]s. If a client already exists, stop and report it instead of creating a duplicate.
+3. Call `+"`create_client`"+` with client_id %[1]q%[4]s, protocol "openid-connect", public_client false and redirect_uris %[5]s. Set web_origins to the origin of the redirect URI.
+4. Call `+"`get_client`"+` with id %[1]q%[4]s and check that standardFlowEnabled is true, directAccessGrantsEnabled is false and the redirect URIs contain no wildcards beyond a trailing path *.
+5. Call `+"`get_client_default_scopes`"+` and `+"`get_client_optional_scopes`"+` with id %[1]q%[4]s. Add only the scopes the application needs with `+"`add_client_default_scope`"+` or `+"`add_client_optional_scope`"+`, and remove the rest with `+"`remove_client_default_scope`"+`.
+6. If the application authorises by role, create its roles with `+"`create_client_role`"+` (client_id %[1]q%[4]s) and list them with `+"`list_client_roles`"+`.
+7. Hand over the client secret: call `+"`get_client_secret`"+` with id %[1]q%[4]s. If it is refused, the server does not reveal secrets; tell the requester to copy it from the admin console. Never paste the secret into chat, tickets or logs.
+8. Summarise what was created: client UUID, redirect URIs, scopes and roles.`,
+				a["client_id"], realmName(a), realmCall(a, "with"), realmCall(a, "and"), redirect)
+		},
+	},
+	{
+		prompt: &mcp.Prompt{
+			Name:        "offboard_employee",
+			Title:       "Offboard an employee",
+			Description: "Disable a departing user and revoke their access without deleting the account",
+			Arguments: []*mcp.PromptArgument{
+				realmArgument,
+				{Name: "username", Description: "Username or email of the departing employee", Required: true},
+			},
+		},
+		steps: func(a map[string]string) string {
+			return fmt.Sprintf(`Offboard %[1]q in %[2]s. Do not delete the account: it is kept for audit.
+
+1. Call `+"`get_user`"+` with user_id %[1]q%[3]s. Confirm the name and email with the requester before changing anything; stop if more than one user matches.
+2. Record current access for the offboarding ticket: `+"`get_user_groups`"+`, `+"`get_user_realm_roles`"+`, `+"`get_user_sessions`"+` and `+"`get_user_federated_identities`"+`, each with user_id %[1]q%[3]s.
+3. Call `+"`update_user`"+` with user_id %[1]q and enabled false%[3]s.
+4. Call `+"`logout_user_all_sessions`"+` with user_id %[1]q%[3]s to end every active session.
+5. For each client the user has granted consent to, call `+"`revoke_user_consents`"+` with user_id %[1]q and that client_id%[3]s; this also revokes offline tokens.
+6. Remove group memberships with `+"`remove_user_from_group`"+` and directly assigned realm roles with `+"`remove_user_realm_roles`"+`%[3]s.
+7. Unlink external identities with `+"`delete_user_federated_identity`"+` for each provider listed in step 2.
+8. Call `+"`get_user`"+` again and confirm enabled is false, then summarise the access removed.`,
+				a["username"], realmName(a), realmCall(a, "and"))
+		},
+	},
+	{
+		prompt: &mcp.Prompt{
+			Name:        "investigate_lockout",
+			Title:       "Investigate a lockout",
+			Description: "Find out why a user cannot sign in and unlock them if appropriate",
+			Arguments: []*mcp.PromptArgument{
+				realmArgument,
+				{Name: "username", Description: "Username or email of the locked-out user", Required: true},
+			},
+		},
+		steps: func(a map[string]string) string {
+			return fmt.Sprintf(`Investigate why %[1]q cannot sign in to %[2]s. Read first; change nothing until the cause is clear.
+
+1. Call `+"`get_user`"+` with user_id %[1]q%[3]s. Check enabled, emailVerified and requiredActions: a pending action such as UPDATE_PASSWORD or CONFIGURE_TOTP blocks sign-in until completed.
+2. Call `+"`get_brute_force_status`"+` with user_id %[1]q%[3]s. Note disabled, numFailures and lastIPFailure.
+3. Call `+"`get_events`"+` with type "LOGIN_ERROR" and user set to the user's ID%[3]s. Look at the error (invalid_user_credentials, user_temporarily_disabled, invalid_totp), the client and the IP addresses. Many failures from unfamiliar addresses suggest an attack, not a forgotten password: escalate to security and do not unlock.
+4. Call `+"`get_user_credentials`"+` with user_id %[1]q%[3]s to check that a password and, if required, an OTP credential exist.
+5. Call `+"`get_realm`"+`%[4]s and read bruteForceProtected, failureFactor, waitIncrementSeconds and maxFailureWaitSeconds to explain how long the lockout lasts.
+6. Only once the user's identity has been verified out of band:
+   - call `+"`clear_brute_force_status`"+` with user_id %[1]q%[3]s to lift a brute-force lockout;
+   - call `+"`update_user`"+` with enabled true if an admin had disabled the account;
+   - call `+"`execute_actions_email`"+` with actions ["UPDATE_PASSWORD"] rather than setting a password yourself.
+7. Summarise the cause, the evidence and what was changed.`,
+				a["username"], realmName(a), realmCall(a, "and"), realmCall(a, "with"))
+		},
+	},
+	{
+		prompt: &mcp.Prompt{
+			Name:        "rotate_idp_certificate",
+			Title:       "Rotate an identity provider certificate",
+			Description: "Replace the signing certificate Keycloak trusts for a SAML or OIDC identity provider",
+			Arguments: []*mcp.PromptArgument{
+				realmArgument,
+				{Name: "alias", Description: "Alias of the identity provider", Required: true},
+			},
+		},
+		steps: func(a map[string]string) string {
+			return fmt.Sprintf(`Rotate the signing certificate of identity provider %[1]q in %[2]s.
+
+1. Call `+"`get_identity_provider`"+` with alias %[1]q%[3]s. Record the current config (signingCertificate, validateSignature, useJwksUrl, jwksUrl) so the change can be rolled back. Secrets are redacted in the output; leave them untouched.
+2. Obtain the new certificate from the provider's metadata. If the provider publishes a JWKS URL and useJwksUrl is "true", keys rotate automatically: stop and report that no change is needed.
+3. If the provider is rolling keys over, keep both certificates valid during the overlap: set signingCertificate to the old and new PEM bodies separated by a comma.
+4. Call `+"`update_identity_provider`"+` with alias %[1]q and config {"signingCertificate": "<new certificate>", "validateSignature": "true"}%[3]s. Change no other fields.
+5. Call `+"`get_identity_provider`"+` again and compare it with step 1: only signingCertificate should differ.
+6. Ask the requester to test a login through the provider, then call `+"`get_events`"+` with type "IDENTITY_PROVIDER_LOGIN_ERROR"%[3]s to check for signature failures.
+7. Once the provider has retired the old key, repeat step 4 with only the new certificate. Roll back with the config from step 1 if logins fail.`,
+				a["alias"], realmName(a), realmCall(a, "and"))
+		},
+	},
+}
+
+// realmName describes the prompt's realm in prose.
+func realmName(args map[string]string) string {
+	if args["realm"] == "" {
+		return "the default realm"
+	}
+	return fmt.Sprintf("realm %q", args["realm"])
+}
+
+// realmCall is the realm argument clause appended to tool calls, joined with
+// conj; it is empty when the default realm is used.
+func realmCall(args map[string]string, conj string) string {
+	if args["realm"] == "" {
+		return ""
+	}
+	return fmt.Sprintf(" %s realm %q", conj, args["realm"])
+}
+
+// registerPrompts adds the runbooks as MCP prompts.
+func registerPrompts(s *mcp.Server) {
+	for _, rb := range runbooks {
+		s.AddPrompt(rb.prompt, func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			args := req.Params.Arguments
+			var missing []string
+			for _, a := range rb.prompt.Arguments {
+				if a.Required && strings.TrimSpace(args[a.Name]) == "" {
+					missing = append(missing, a.Name)
+				}
+			}
+			if len(missing) > 0 {
+				return nil, &jsonrpc.Error{
+					Code:    jsonrpc.CodeInvalidParams,
+					Message: fmt.Sprintf("prompt %s requires %s", rb.prompt.Name, strings.Join(missing, ", ")),
+				}
+			}
+			return &mcp.GetPromptResult{
+				Description: rb.prompt.Description,
+				Messages: []*mcp.PromptMessage{{
+					Role:    "user",
+					Content: &mcp.TextContent{Text: rb.steps(args)},
+				}},
+			}, nil
+		})
+	}
+}
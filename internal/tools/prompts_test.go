@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// toolReference matches tool names quoted in runbook steps.
+var toolReference = regexp.MustCompile("`([a-z_]+)`")
+
+func TestPrompts(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+
+	tools, err := h.cs.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registered := map[string]bool{}
+	for _, tool := range tools.Tools {
+		registered[tool.Name] = true
+	}
+
+	prompts, err := h.cs.ListPrompts(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prompts.Prompts) != len(runbooks) {
+		t.Fatalf("got %d prompts, want %d", len(prompts.Prompts), len(runbooks))
+	}
+	args := map[string]string{"realm": "acme", "client_id": "web", "username": "alice", "alias": "okta"}
+	for _, p := range prompts.Prompts {
+		res, err := h.cs.GetPrompt(ctx, &mcp.GetPromptParams{Name: p.Name, Arguments: args})
+		if err != nil {
+			t.Fatalf("%s: %v", p.Name, err)
+		}
+		text := res.Messages[0].Content.(*mcp.TextContent).Text
+		if !strings.Contains(text, `realm "acme"`) {
+			t.Errorf("%s does not mention the realm:\n%s", p.Name, text)
+		}
+		refs := toolReference.FindAllStringSubmatch(text, -1)
+		if len(refs) == 0 {
+			t.Errorf("%s references no tools", p.Name)
+		}
+		for _, ref := range refs {
+			if !registered[ref[1]] {
+				t.Errorf("%s references unknown tool %s", p.Name, ref[1])
+			}
+		}
+
+		if _, err := h.cs.GetPrompt(ctx, &mcp.GetPromptParams{Name: p.Name}); err == nil {
+			t.Errorf("%s accepted missing required arguments", p.Name)
+		}
+	}
+}
@@ -2,7 +2,13 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/Nerzal/gocloak/v13"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -14,10 +20,15 @@ import (
 // Arg structs
 // ---------------------------------------------------------------------------
 
-type listRealmsArgs struct{}
+type listRealmsArgs struct {
+	NoCache bool `json:"no_cache,omitempty" jsonschema:"Bypass the lookup cache and read directly from Keycloak"`
+	viewArgs
+}
 
 type getRealmArgs struct {
-	Realm string `json:"realm" jsonschema:"The realm name"`
+	Realm   string `json:"realm" jsonschema:"The realm name"`
+	NoCache bool   `json:"no_cache,omitempty" jsonschema:"Bypass the lookup cache and read directly from Keycloak"`
+	viewArgs
 }
 
 type createRealmArgs struct {
@@ -55,6 +66,88 @@ type clearKeysCacheArgs struct {
 	Realm string `json:"realm,omitempty" jsonschema:"The realm name (uses default realm if omitted)"`
 }
 
+type getUserProfileArgs struct {
+	Realm string `json:"realm,omitempty" jsonschema:"The realm name (uses default realm if omitted)"`
+}
+
+// userProfileAttributeArgs describes an attribute for create_ and
+// update_user_profile_attribute. Omitted fields are left unchanged by updates.
+type userProfileAttributeArgs struct {
+	Realm          string                    `json:"realm,omitempty"           jsonschema:"The realm name (uses default realm if omitted)"`
+	Name           string                    `json:"name"                      jsonschema:"Attribute name, e.g. tenant_id"`
+	DisplayName    *string                   `json:"display_name,omitempty"    jsonschema:"Label shown in forms; may be a ${message} key"`
+	Validations    map[string]map[string]any `json:"validations,omitempty"     jsonschema:"Validators by name with their config, e.g. {\"length\": {\"min\": 3, \"max\": 64}, \"pattern\": {\"pattern\": \"^t-[0-9]+$\"}, \"options\": {\"options\": [\"a\", \"b\"]}}. Replaces all validators"`
+	Annotations    map[string]any            `json:"annotations,omitempty"     jsonschema:"Form hints such as inputType or inputHelperTextBefore. Replaces all annotations"`
+	Required       *bool                     `json:"required,omitempty"        jsonschema:"Whether the attribute must have a value"`
+	RequiredRoles  []string                  `json:"required_roles,omitempty"  jsonschema:"Who must fill it in when required: admin, user or both (default both)"`
+	RequiredScopes []string                  `json:"required_scopes,omitempty" jsonschema:"Only require it when these client scopes are requested"`
+	View           []string                  `json:"view,omitempty"            jsonschema:"Who can see the attribute: admin and/or user"`
+	Edit           []string                  `json:"edit,omitempty"            jsonschema:"Who can change the attribute: admin and/or user"`
+	Group          *string                   `json:"group,omitempty"           jsonschema:"Attribute group to show it in; empty for none"`
+	Multivalued    *bool                     `json:"multivalued,omitempty"     jsonschema:"Whether the attribute may hold several values"`
+}
+
+type deleteUserProfileAttributeArgs struct {
+	Realm string `json:"realm,omitempty" jsonschema:"The realm name (uses default realm if omitted)"`
+	Name  string `json:"name"            jsonschema:"Attribute name"`
+}
+
+// userProfileConfig is a realm's declarative user profile (Keycloak's
+// UPConfig), which gocloak does not model. Each type keeps the fields it
+// doesn't model in unknown, so that writing a profile back after changing it
+// loses nothing a newer Keycloak added.
+type userProfileConfig struct {
+	Attributes               []*userProfileAttribute `json:"attributes"`
+	Groups                   []*userProfileGroup     `json:"groups,omitempty"`
+	UnmanagedAttributePolicy string                  `json:"unmanagedAttributePolicy,omitempty"`
+
+	unknown unknownFields
+}
+
+type userProfileAttribute struct {
+	Name         string                    `json:"name"`
+	DisplayName  string                    `json:"displayName,omitempty"`
+	Validations  map[string]map[string]any `json:"validations,omitempty"`
+	Annotations  map[string]any            `json:"annotations,omitempty"`
+	Required     *userProfileRequired      `json:"required,omitempty"`
+	Permissions  *userProfilePermissions   `json:"permissions,omitempty"`
+	Selector     *userProfileSelector      `json:"selector,omitempty"`
+	Group        string                    `json:"group,omitempty"`
+	Multivalued  bool                      `json:"multivalued,omitempty"`
+	DefaultValue string                    `json:"defaultValue,omitempty"`
+
+	unknown unknownFields
+}
+
+type userProfileRequired struct {
+	Roles  []string `json:"roles,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+
+	unknown unknownFields
+}
+
+type userProfilePermissions struct {
+	View []string `json:"view"`
+	Edit []string `json:"edit"`
+
+	unknown unknownFields
+}
+
+type userProfileSelector struct {
+	Scopes []string `json:"scopes,omitempty"`
+
+	unknown unknownFields
+}
+
+type userProfileGroup struct {
+	Name               string         `json:"name"`
+	DisplayHeader      string         `json:"displayHeader,omitempty"`
+	DisplayDescription string         `json:"displayDescription,omitempty"`
+	Annotations        map[string]any `json:"annotations,omitempty"`
+
+	unknown unknownFields
+}
+
 // ---------------------------------------------------------------------------
 // Registration
 // ---------------------------------------------------------------------------
@@ -62,35 +155,39 @@ type clearKeysCacheArgs struct {
 func registerRealmTools(s *mcp.Server, kc *keycloak.Client) {
 	// 1. list_realms
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "list_realms",
-		Description: "List all Keycloak realms",
+		Name:         "list_realms",
+		Description:  "List all Keycloak realms",
+		OutputSchema: listSchema[*gocloak.RealmRepresentation](),
+		Annotations:  readOnly("List realms"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args listRealmsArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
-		realms, err := kc.GC.GetRealms(ctx, token)
+		realms, err := kc.GetRealms(keycloak.WithNoCache(ctx, args.NoCache), token)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to list realms: %v", err))
+			return kcError("failed to list realms", err)
 		}
 
-		return toolResult(realms)
+		return listResult(realms)
 	})
 
 	// 2. get_realm
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_realm",
-		Description: "Get a Keycloak realm by name",
+		Name:         "get_realm",
+		Description:  "Get a Keycloak realm by name",
+		OutputSchema: outputSchema[gocloak.RealmRepresentation](),
+		Annotations:  readOnly("Get realm"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getRealmArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
-		realm, err := kc.GC.GetRealm(ctx, token, args.Realm)
+		realm, err := kc.GetRealm(keycloak.WithNoCache(ctx, args.NoCache), token, args.Realm)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get realm %q: %v", args.Realm, err))
+			return kcError(fmt.Sprintf("failed to get realm %q", args.Realm), err)
 		}
 
 		return toolResult(realm)
@@ -98,12 +195,14 @@ func registerRealmTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 3. create_realm
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_realm",
-		Description: "Create a new Keycloak realm",
+		Name:         "create_realm",
+		Description:  "Create a new Keycloak realm",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Create realm", false),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args createRealmArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		enabled := true
@@ -121,26 +220,30 @@ func registerRealmTools(s *mcp.Server, kc *keycloak.Client) {
 
 		createdID, err := kc.GC.CreateRealm(ctx, token, realmRep)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to create realm %q: %v", args.Realm, err))
+			return kcError(fmt.Sprintf("failed to create realm %q", args.Realm), err)
 		}
 
-		return toolSuccess(fmt.Sprintf("Realm %q created successfully (id: %s)", args.Realm, createdID))
+		kc.Invalidate("", keycloak.ResourceRealms)
+
+		return toolCreated(createdID, fmt.Sprintf("Realm %q created successfully (id: %s)", args.Realm, createdID))
 	})
 
 	// 4. update_realm
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "update_realm",
-		Description: "Update settings on an existing Keycloak realm",
+		Name:         "update_realm",
+		Description:  "Update settings on an existing Keycloak realm",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Update realm", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args updateRealmArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		// Fetch the current representation so we only override supplied fields.
 		existing, err := kc.GC.GetRealm(ctx, token, args.Realm)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get realm %q for update: %v", args.Realm, err))
+			return kcError(fmt.Sprintf("failed to get realm %q for update", args.Realm), err)
 		}
 
 		if args.Enabled != nil {
@@ -172,60 +275,74 @@ func registerRealmTools(s *mcp.Server, kc *keycloak.Client) {
 		}
 
 		if err := kc.GC.UpdateRealm(ctx, token, *existing); err != nil {
-			return toolError(fmt.Sprintf("Error: failed to update realm %q: %v", args.Realm, err))
+			return kcError(fmt.Sprintf("failed to update realm %q", args.Realm), err)
 		}
 
+		kc.Invalidate("", keycloak.ResourceRealms)
+		kc.Invalidate(args.Realm, keycloak.ResourceRealm)
+
 		return toolSuccess(fmt.Sprintf("Realm %q updated successfully", args.Realm))
 	})
 
 	// 5. delete_realm
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_realm",
-		Description: "Delete a Keycloak realm",
+		Name:         "delete_realm",
+		Description:  "Delete a Keycloak realm",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete realm", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args deleteRealmArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		if err := kc.GC.DeleteRealm(ctx, token, args.Realm); err != nil {
-			return toolError(fmt.Sprintf("Error: failed to delete realm %q: %v", args.Realm, err))
+			return kcError(fmt.Sprintf("failed to delete realm %q", args.Realm), err)
 		}
 
+		kc.Invalidate("", keycloak.ResourceRealms)
+		kc.Invalidate(args.Realm)
+
 		return toolSuccess(fmt.Sprintf("Realm %q deleted successfully", args.Realm))
 	})
 
 	// 6. clear_realm_cache
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "clear_realm_cache",
-		Description: "Clear the realm cache in Keycloak",
+		Name:         "clear_realm_cache",
+		Description:  "Clear the realm cache in Keycloak",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Clear realm cache", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args clearRealmCacheArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
 		if err := kc.GC.ClearRealmCache(ctx, token, realm); err != nil {
-			return toolError(fmt.Sprintf("Error: failed to clear realm cache for %q: %v", realm, err))
+			return kcError(fmt.Sprintf("failed to clear realm cache for %q", realm), err)
 		}
 
+		kc.Invalidate(realm)
+
 		return toolSuccess(fmt.Sprintf("Realm cache cleared for %q", realm))
 	})
 
 	// 7. clear_user_cache
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "clear_user_cache",
-		Description: "Clear the user cache in Keycloak",
+		Name:         "clear_user_cache",
+		Description:  "Clear the user cache in Keycloak",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Clear user cache", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args clearUserCacheArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
 		if err := kc.GC.ClearUserCache(ctx, token, realm); err != nil {
-			return toolError(fmt.Sprintf("Error: failed to clear user cache for %q: %v", realm, err))
+			return kcError(fmt.Sprintf("failed to clear user cache for %q", realm), err)
 		}
 
 		return toolSuccess(fmt.Sprintf("User cache cleared for %q", realm))
@@ -233,19 +350,419 @@ func registerRealmTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 8. clear_keys_cache
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "clear_keys_cache",
-		Description: "Clear the keys cache in Keycloak",
+		Name:         "clear_keys_cache",
+		Description:  "Clear the keys cache in Keycloak",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Clear keys cache", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args clearKeysCacheArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("Error: failed to get token: %v", err))
+			return tokenError(err)
 		}
 
 		realm := kc.ResolveRealm(args.Realm)
 		if err := kc.GC.ClearKeysCache(ctx, token, realm); err != nil {
-			return toolError(fmt.Sprintf("Error: failed to clear keys cache for %q: %v", realm, err))
+			return kcError(fmt.Sprintf("failed to clear keys cache for %q", realm), err)
 		}
 
 		return toolSuccess(fmt.Sprintf("Keys cache cleared for %q", realm))
 	})
+	// 9. get_user_profile
+	mcp.AddTool(s, &mcp.Tool{
+		Name:         "get_user_profile",
+		Description:  "Get the realm's declarative user profile: its attributes with validators, permissions and annotations, and the attribute groups",
+		OutputSchema: openSchema(outputSchema[userProfileConfig]()),
+		Annotations:  readOnly("Get user profile"),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args getUserProfileArgs) (*mcp.CallToolResult, any, error) {
+		token, err := kc.Token(ctx)
+		if err != nil {
+			return tokenError(err)
+		}
+
+		realm := kc.ResolveRealm(args.Realm)
+		profile, err := getUserProfile(ctx, kc, token, realm)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to get user profile of %q", realm), err)
+		}
+		return toolResult(profile)
+	})
+
+	// 10. create_user_profile_attribute
+	mcp.AddTool(s, &mcp.Tool{
+		Name:         "create_user_profile_attribute",
+		Description:  "Add an attribute to the realm's user profile. Users can see and edit it unless view and edit say otherwise",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Create user profile attribute", false),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args userProfileAttributeArgs) (*mcp.CallToolResult, any, error) {
+		return changeUserProfile(ctx, kc, args.Realm, func(profile *userProfileConfig) (string, *toolErr) {
+			if profile.attribute(args.Name) != nil {
+				return "", &toolErr{Code: codeConflict, Message: fmt.Sprintf("user profile attribute %q already exists", args.Name), Hint: "Use update_user_profile_attribute to change it."}
+			}
+			attr := &userProfileAttribute{
+				Name:        args.Name,
+				Permissions: &userProfilePermissions{View: []string{"admin", "user"}, Edit: []string{"admin", "user"}},
+			}
+			args.apply(attr)
+			profile.Attributes = append(profile.Attributes, attr)
+			return fmt.Sprintf("User profile attribute %q created", args.Name), nil
+		})
+	})
+
+	// 11. update_user_profile_attribute
+	mcp.AddTool(s, &mcp.Tool{
+		Name:         "update_user_profile_attribute",
+		Description:  "Change an attribute of the realm's user profile; omitted fields are kept",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Update user profile attribute", true),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args userProfileAttributeArgs) (*mcp.CallToolResult, any, error) {
+		return changeUserProfile(ctx, kc, args.Realm, func(profile *userProfileConfig) (string, *toolErr) {
+			attr := profile.attribute(args.Name)
+			if attr == nil {
+				return "", &toolErr{Code: codeNotFound, Message: fmt.Sprintf("user profile attribute %q not found", args.Name)}
+			}
+			args.apply(attr)
+			return fmt.Sprintf("User profile attribute %q updated", args.Name), nil
+		})
+	})
+
+	// 12. delete_user_profile_attribute
+	mcp.AddTool(s, &mcp.Tool{
+		Name:         "delete_user_profile_attribute",
+		Description:  "Remove an attribute from the realm's user profile. Users keep their values, which become unmanaged attributes",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete user profile attribute", true),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args deleteUserProfileAttributeArgs) (*mcp.CallToolResult, any, error) {
+		return changeUserProfile(ctx, kc, args.Realm, func(profile *userProfileConfig) (string, *toolErr) {
+			if profile.attribute(args.Name) == nil {
+				return "", &toolErr{Code: codeNotFound, Message: fmt.Sprintf("user profile attribute %q not found", args.Name)}
+			}
+			profile.Attributes = slices.DeleteFunc(profile.Attributes, func(a *userProfileAttribute) bool { return a.Name == args.Name })
+			return fmt.Sprintf("User profile attribute %q deleted", args.Name), nil
+		})
+	})
+}
+
+// ---------------------------------------------------------------------------
+// User profile
+// ---------------------------------------------------------------------------
+
+// unknownFields holds the members of a JSON object that its Go type doesn't
+// model.
+type unknownFields map[string]json.RawMessage
+
+// decodeKeeping decodes data into v, a pointer to a struct without JSON
+// methods of its own, and returns the members that none of its fields took.
+func decodeKeeping(data []byte, v any) (unknownFields, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	var all unknownFields
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	t := reflect.TypeOf(v).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		delete(all, name)
+	}
+	if len(all) == 0 {
+		return nil, nil
+	}
+	return all, nil
+}
+
+// encodeKeeping encodes v with the unknown members added back.
+func encodeKeeping(v any, unknown unknownFields) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(unknown) == 0 {
+		return b, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+	for name, raw := range unknown {
+		if _, ok := all[name]; !ok {
+			all[name] = raw
+		}
+	}
+	return json.Marshal(all)
+}
+
+func (v *userProfileConfig) UnmarshalJSON(data []byte) (err error) {
+	type plain userProfileConfig
+	v.unknown, err = decodeKeeping(data, (*plain)(v))
+	return err
+}
+
+func (v userProfileConfig) MarshalJSON() ([]byte, error) {
+	type plain userProfileConfig
+	return encodeKeeping(plain(v), v.unknown)
+}
+
+func (v *userProfileAttribute) UnmarshalJSON(data []byte) (err error) {
+	type plain userProfileAttribute
+	v.unknown, err = decodeKeeping(data, (*plain)(v))
+	return err
+}
+
+func (v userProfileAttribute) MarshalJSON() ([]byte, error) {
+	type plain userProfileAttribute
+	return encodeKeeping(plain(v), v.unknown)
+}
+
+func (v *userProfileRequired) UnmarshalJSON(data []byte) (err error) {
+	type plain userProfileRequired
+	v.unknown, err = decodeKeeping(data, (*plain)(v))
+	return err
+}
+
+func (v userProfileRequired) MarshalJSON() ([]byte, error) {
+	type plain userProfileRequired
+	return encodeKeeping(plain(v), v.unknown)
+}
+
+func (v *userProfilePermissions) UnmarshalJSON(data []byte) (err error) {
+	type plain userProfilePermissions
+	v.unknown, err = decodeKeeping(data, (*plain)(v))
+	return err
+}
+
+func (v userProfilePermissions) MarshalJSON() ([]byte, error) {
+	type plain userProfilePermissions
+	return encodeKeeping(plain(v), v.unknown)
+}
+
+func (v *userProfileSelector) UnmarshalJSON(data []byte) (err error) {
+	type plain userProfileSelector
+	v.unknown, err = decodeKeeping(data, (*plain)(v))
+	return err
+}
+
+func (v userProfileSelector) MarshalJSON() ([]byte, error) {
+	type plain userProfileSelector
+	return encodeKeeping(plain(v), v.unknown)
+}
+
+func (v *userProfileGroup) UnmarshalJSON(data []byte) (err error) {
+	type plain userProfileGroup
+	v.unknown, err = decodeKeeping(data, (*plain)(v))
+	return err
+}
+
+func (v userProfileGroup) MarshalJSON() ([]byte, error) {
+	type plain userProfileGroup
+	return encodeKeeping(plain(v), v.unknown)
+}
+
+func (p *userProfileConfig) attribute(name string) *userProfileAttribute {
+	for _, a := range p.Attributes {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// apply copies the supplied fields onto attr.
+func (args *userProfileAttributeArgs) apply(attr *userProfileAttribute) {
+	if args.DisplayName != nil {
+		attr.DisplayName = *args.DisplayName
+	}
+	if args.Validations != nil {
+		attr.Validations = args.Validations
+	}
+	if args.Annotations != nil {
+		attr.Annotations = args.Annotations
+	}
+	if args.Required != nil {
+		attr.Required = nil
+		if *args.Required {
+			attr.Required = &userProfileRequired{Roles: []string{"admin", "user"}}
+		}
+	}
+	if attr.Required != nil && args.RequiredRoles != nil {
+		attr.Required.Roles = args.RequiredRoles
+	}
+	if attr.Required != nil && args.RequiredScopes != nil {
+		attr.Required.Scopes = args.RequiredScopes
+	}
+	if args.View != nil || args.Edit != nil {
+		if attr.Permissions == nil {
+			attr.Permissions = &userProfilePermissions{View: []string{}, Edit: []string{}}
+		}
+		if args.View != nil {
+			attr.Permissions.View = args.View
+		}
+		if args.Edit != nil {
+			attr.Permissions.Edit = args.Edit
+		}
+	}
+	if args.Group != nil {
+		attr.Group = *args.Group
+	}
+	if args.Multivalued != nil {
+		attr.Multivalued = *args.Multivalued
+	}
+}
+
+// changeUserProfile reads the realm's user profile, lets change edit it, and
+// writes it back only if the whole document is still valid.
+func changeUserProfile(ctx context.Context, kc *keycloak.Client, realmArg string, change func(*userProfileConfig) (string, *toolErr)) (*mcp.CallToolResult, any, error) {
+	token, err := kc.Token(ctx)
+	if err != nil {
+		return tokenError(err)
+	}
+
+	realm := kc.ResolveRealm(realmArg)
+	profile, err := getUserProfile(ctx, kc, token, realm)
+	if err != nil {
+		return kcError(fmt.Sprintf("failed to get user profile of %q", realm), err)
+	}
+	msg, terr := change(profile)
+	if terr != nil {
+		return toolError(terr)
+	}
+	if problems := validateUserProfile(profile); len(problems) > 0 {
+		return validationError("the user profile would be invalid: " + strings.Join(problems, "; "))
+	}
+
+	resp, err := kc.GC.GetRequestWithBearerAuth(ctx, token).
+		SetBody(profile).
+		Put(kc.AdminURL(realm, "/users/profile"))
+	if err != nil {
+		return kcError(fmt.Sprintf("failed to update user profile of %q", realm), err)
+	}
+	if resp.IsError() {
+		return kcError(fmt.Sprintf("failed to update user profile of %q", realm), apiError(resp.StatusCode(), resp.Status(), resp.Body()))
+	}
+	return toolSuccess(fmt.Sprintf("%s in realm %q", msg, realm))
+}
+
+func getUserProfile(ctx context.Context, kc *keycloak.Client, token, realm string) (*userProfileConfig, error) {
+	var profile userProfileConfig
+	resp, err := kc.GC.GetRequestWithBearerAuth(ctx, token).
+		SetResult(&profile).
+		Get(kc.AdminURL(realm, "/users/profile"))
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, apiError(resp.StatusCode(), resp.Status(), resp.Body())
+	}
+	return &profile, nil
+}
+
+var userProfileAttributeName = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+// validateUserProfile checks the rules Keycloak enforces on a user profile,
+// plus the config of the built-in validators, and returns every problem found.
+// Validators it does not know may come from custom providers and are allowed.
+func validateUserProfile(p *userProfileConfig) []string {
+	var problems []string
+	groups := map[string]bool{}
+	for _, g := range p.Groups {
+		if g.Name == "" {
+			problems = append(problems, "an attribute group has no name")
+		} else if groups[g.Name] {
+			problems = append(problems, fmt.Sprintf("attribute group %q is defined twice", g.Name))
+		}
+		groups[g.Name] = true
+	}
+
+	seen := map[string]bool{}
+	for _, a := range p.Attributes {
+		switch {
+		case a.Name == "":
+			problems = append(problems, "an attribute has no name")
+			continue
+		case !userProfileAttributeName.MatchString(a.Name):
+			problems = append(problems, fmt.Sprintf("attribute %q: names may only contain letters, digits, '.', '_' and '-'", a.Name))
+		case seen[a.Name]:
+			problems = append(problems, fmt.Sprintf("attribute %q is defined twice", a.Name))
+		}
+		seen[a.Name] = true
+
+		if a.Group != "" && !groups[a.Group] {
+			problems = append(problems, fmt.Sprintf("attribute %q: group %q does not exist", a.Name, a.Group))
+		}
+		if a.Permissions != nil {
+			for _, who := range slices.Concat(a.Permissions.View, a.Permissions.Edit) {
+				if who != "admin" && who != "user" {
+					problems = append(problems, fmt.Sprintf("attribute %q: permissions may only name admin or user, not %q", a.Name, who))
+				}
+			}
+		}
+		if a.Required != nil {
+			for _, who := range a.Required.Roles {
+				if who != "admin" && who != "user" {
+					problems = append(problems, fmt.Sprintf("attribute %q: required roles may only be admin or user, not %q", a.Name, who))
+				}
+			}
+		}
+		for name, config := range a.Validations {
+			if problem := checkValidator(name, config); problem != "" {
+				problems = append(problems, fmt.Sprintf("attribute %q: validator %s: %s", a.Name, name, problem))
+			}
+		}
+	}
+	for _, name := range []string{"username", "email"} {
+		if !seen[name] {
+			problems = append(problems, fmt.Sprintf("attribute %q is required by Keycloak and cannot be removed", name))
+		}
+	}
+
+	switch p.UnmanagedAttributePolicy {
+	case "", "ENABLED", "ADMIN_VIEW", "ADMIN_EDIT":
+	default:
+		problems = append(problems, fmt.Sprintf("unmanagedAttributePolicy %q is not ENABLED, ADMIN_VIEW or ADMIN_EDIT", p.UnmanagedAttributePolicy))
+	}
+	return problems
+}
+
+// checkValidator checks the config of a built-in validator.
+func checkValidator(name string, config map[string]any) string {
+	number := func(key string) (float64, bool, string) {
+		v, ok := config[key]
+		if !ok {
+			return 0, false, ""
+		}
+		switch n := v.(type) {
+		case float64:
+			return n, true, ""
+		case string:
+			f, err := strconv.ParseFloat(n, 64)
+			if err == nil {
+				return f, true, ""
+			}
+		}
+		return 0, false, fmt.Sprintf("%s must be a number", key)
+	}
+
+	switch name {
+	case "length", "integer", "double":
+		min, hasMin, problem := number("min")
+		if problem != "" {
+			return problem
+		}
+		max, hasMax, problem := number("max")
+		if problem != "" {
+			return problem
+		}
+		if name == "length" && (min < 0 || max < 0) {
+			return "min and max cannot be negative"
+		}
+		if hasMin && hasMax && min > max {
+			return "min is greater than max"
+		}
+	case "pattern":
+		if p, _ := config["pattern"].(string); p == "" {
+			return "pattern is missing"
+		}
+	case "options":
+		if opts, _ := config["options"].([]any); len(opts) == 0 {
+			return "options must list at least one value"
+		}
+	}
+	return ""
 }
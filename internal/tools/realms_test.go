@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/config"
+)
+
+func TestRealmTools(t *testing.T) {
+	h := newHarness(t)
+
+	var realms []map[string]any
+	h.okJSON("list_realms", nil, &realms)
+	assertNames(t, "realms", realms, "realm", "acme", "master")
+
+	h.ok("create_realm", map[string]any{"realm": "beta", "display_name": "Beta"})
+	h.fail("create_realm", map[string]any{"realm": "beta"}, codeConflict)
+
+	h.ok("update_realm", map[string]any{"realm": "beta", "display_name": "Beta Corp", "registration_allowed": true})
+	var realm map[string]any
+	h.okJSON("get_realm", map[string]any{"realm": "beta"}, &realm)
+	if realm["displayName"] != "Beta Corp" || realm["registrationAllowed"] != true {
+		t.Fatalf("update_realm not applied: %v", realm)
+	}
+
+	for _, tool := range []string{"clear_realm_cache", "clear_user_cache", "clear_keys_cache"} {
+		if msg := h.ok(tool, map[string]any{"realm": "beta"}); !strings.Contains(msg, `"beta"`) {
+			t.Fatalf("%s: %s", tool, msg)
+		}
+	}
+
+	h.ok("delete_realm", map[string]any{"realm": "beta"})
+	e := h.fail("get_realm", map[string]any{"realm": "beta"}, codeNotFound)
+	if e.Status != 404 || e.Hint == "" {
+		t.Fatalf("not_found payload incomplete: %+v", e)
+	}
+}
+
+func TestUserProfileTools(t *testing.T) {
+	h := newHarness(t)
+
+	var profile userProfileConfig
+	h.okJSON("get_user_profile", nil, &profile)
+	if profile.attribute("username") == nil || len(profile.Groups) != 1 {
+		t.Fatalf("default profile = %+v", profile)
+	}
+
+	h.ok("create_user_profile_attribute", map[string]any{
+		"name": "tenant_id", "display_name": "Tenant", "group": "user-metadata",
+		"validations": map[string]any{"pattern": map[string]any{"pattern": "^t-[0-9]+$"}},
+		"annotations": map[string]any{"inputType": "text"},
+		"required":    true, "required_roles": []string{"admin"}, "edit": []string{"admin"},
+	})
+	h.fail("create_user_profile_attribute", map[string]any{"name": "tenant_id"}, codeConflict)
+
+	h.ok("update_user_profile_attribute", map[string]any{"name": "tenant_id", "multivalued": true, "display_name": "Tenants"})
+	profile = userProfileConfig{}
+	h.okJSON("get_user_profile", nil, &profile)
+	attr := profile.attribute("tenant_id")
+	if attr == nil || attr.DisplayName != "Tenants" || !attr.Multivalued || attr.Validations["pattern"] == nil ||
+		attr.Required == nil || attr.Required.Roles[0] != "admin" || attr.Permissions.Edit[0] != "admin" || attr.Permissions.View[1] != "user" {
+		t.Fatalf("tenant_id = %+v", attr)
+	}
+
+	// The whole document is checked before anything is written.
+	for _, args := range []map[string]any{
+		{"name": "plan", "group": "missing"},
+		{"name": "plan", "validations": map[string]any{"length": map[string]any{"min": 10, "max": 2}}},
+		{"name": "plan", "validations": map[string]any{"options": map[string]any{}}},
+		{"name": "plan", "view": []string{"everyone"}},
+		{"name": "has space"},
+	} {
+		h.fail("create_user_profile_attribute", args, codeValidation)
+	}
+	h.fail("delete_user_profile_attribute", map[string]any{"name": "email"}, codeValidation)
+	h.fail("update_user_profile_attribute", map[string]any{"name": "plan"}, codeNotFound)
+
+	h.ok("delete_user_profile_attribute", map[string]any{"name": "tenant_id"})
+	profile = userProfileConfig{}
+	h.okJSON("get_user_profile", nil, &profile)
+	if profile.attribute("tenant_id") != nil || profile.attribute("plan") != nil {
+		t.Fatalf("attributes = %+v", profile.Attributes)
+	}
+}
+
+func TestUserProfileKeepsUnknownFields(t *testing.T) {
+	h := newHarnessWithConfig(t, func(cfg *config.Config) {
+		cfg.AdminAPIAllowlist = append(cfg.AdminAPIAllowlist, "PUT /users/profile")
+	})
+	attr := func(name string) map[string]any {
+		return map[string]any{
+			"name": name, "permissions": map[string]any{"view": []string{"admin"}, "edit": []string{"admin"}, "futurePerm": true},
+			"selector": map[string]any{"scopes": []string{"profile"}, "futureSelector": "x"}, "futureAttr": map[string]any{"k": "v"},
+		}
+	}
+	h.ok("admin_api_request", map[string]any{"method": "PUT", "path": "/users/profile", "body": map[string]any{
+		"attributes":   []any{attr("username"), attr("email"), attr("tenant_id")},
+		"groups":       []any{map[string]any{"name": "user-metadata", "futureGroup": 1}},
+		"futureConfig": []string{"a"},
+	}})
+
+	h.ok("update_user_profile_attribute", map[string]any{"name": "tenant_id", "display_name": "Tenant"})
+
+	var resp struct {
+		Body map[string]any `json:"body"`
+	}
+	h.okJSON("admin_api_request", map[string]any{"method": "GET", "path": "/users/profile"}, &resp)
+	profile := resp.Body
+	attrs, _ := profile["attributes"].([]any)
+	groups, _ := profile["groups"].([]any)
+	if profile["futureConfig"] == nil || len(attrs) != 3 || len(groups) != 1 || groups[0].(map[string]any)["futureGroup"] == nil {
+		t.Fatalf("profile lost fields: %v", profile)
+	}
+	tenant := attrs[2].(map[string]any)
+	perms, _ := tenant["permissions"].(map[string]any)
+	selector, _ := tenant["selector"].(map[string]any)
+	if tenant["displayName"] != "Tenant" || tenant["futureAttr"] == nil || perms["futurePerm"] != true || selector["futureSelector"] != "x" {
+		t.Fatalf("tenant_id lost fields: %v", tenant)
+	}
+
+	var got map[string]any
+	h.okJSON("get_user_profile", nil, &got)
+	if got["futureConfig"] == nil {
+		t.Fatalf("get_user_profile dropped unknown fields: %v", got)
+	}
+}
+
+func TestUserProfileNeedsKeycloak24(t *testing.T) {
+	h := newHarness(t)
+	h.fake.Version = "23.0.7"
+
+	h.fail("get_user_profile", nil, codeUnsupported)
+}
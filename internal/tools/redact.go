@@ -0,0 +1,50 @@
+package tools
+
+import "strings"
+
+// redactedValue replaces secrets in tool output. It is the mask Keycloak
+// itself uses for identity provider secrets.
+const redactedValue = "**********"
+
+// secretKeys are the fields of Keycloak representations that hold secrets:
+// client secrets and registration tokens, identity provider client secrets,
+// LDAP bind credentials, SMTP passwords and stored credential data. Matching
+// is case-insensitive on the whole key.
+var secretKeys = map[string]bool{
+	"secret":                  true,
+	"clientsecret":            true,
+	"client_secret":           true,
+	"registrationaccesstoken": true,
+	"bindcredential":          true,
+	"password":                true,
+	"secretdata":              true,
+	"credentialdata":          true,
+}
+
+// redactSecrets masks secret values in v, a generic JSON value, in place.
+// Strings are replaced by redactedValue and string arrays (component config)
+// by a single redactedValue, so results still match their output schema.
+// Credential representations carry the secret in "value" next to a "type".
+func redactSecrets(v any) {
+	switch t := v.(type) {
+	case map[string]any:
+		_, typed := t["type"]
+		for k, val := range t {
+			if secretKeys[strings.ToLower(k)] || (typed && k == "value") {
+				switch val.(type) {
+				case string:
+					t[k] = redactedValue
+					continue
+				case []any:
+					t[k] = []any{redactedValue}
+					continue
+				}
+			}
+			redactSecrets(val)
+		}
+	case []any:
+		for _, e := range t {
+			redactSecrets(e)
+		}
+	}
+}
@@ -1,17 +1,53 @@
 package tools
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog/log"
 
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/audit"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/config"
 	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
 )
 
-// RegisterAll wires every tool domain to the MCP server.
-func RegisterAll(s *mcp.Server, kc *keycloak.Client) {
+// toolCapabilities lists tools that only work on some Keycloak versions.
+// Tools not listed here are available on every supported server.
+var toolCapabilities = map[string]keycloak.Capability{
+	"get_user_profile":              keycloak.CapUserProfile,
+	"create_user_profile_attribute": keycloak.CapUserProfile,
+	"update_user_profile_attribute": keycloak.CapUserProfile,
+	"delete_user_profile_attribute": keycloak.CapUserProfile,
+}
+
+// readOnlyTool reports whether a tool only reads from Keycloak.
+func readOnlyTool(name string) bool {
+	for _, prefix := range []string{"get_", "list_", "search_", "count_"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterAll wires every tool domain, the keycloak:// resources and the
+// runbook prompts to the MCP server. Tools whose capability the connected
+// server lacks are removed again; if the version could not be detected they
+// stay registered and are checked per call. In read-only mode every tool that
+// modifies Keycloak is hidden and refused. Clients that support elicitation
+// are asked to confirm destructive calls (see askUser).
+func RegisterAll(s *mcp.Server, kc *keycloak.Client, cfg *config.Config, auditLog *audit.Logger) {
 	registerRealmTools(s, kc)
 	registerUserTools(s, kc)
+	registerUserAttributeTools(s, kc)
+	registerImpersonateUser(s, kc, cfg)
+	registerUserImportTools(s, kc)
+	registerBulkUserTools(s, kc)
 	registerGroupTools(s, kc)
-	registerClientTools(s, kc)
+	registerClientTools(s, kc, cfg)
 	registerRoleTools(s, kc)
 	registerIdentityProviderTools(s, kc)
 	registerAuthFlowTools(s, kc)
@@ -21,4 +57,116 @@ func RegisterAll(s *mcp.Server, kc *keycloak.Client) {
 	registerComponentTools(s, kc)
 	registerAttackDetectionTools(s, kc)
 	registerServerInfoTools(s, kc)
+	registerAdminAPITools(s, kc, cfg)
+	registerResources(s, kc)
+	registerPrompts(s)
+
+	var unsupported []string
+	for name, cap := range toolCapabilities {
+		if !kc.Supports(cap) {
+			unsupported = append(unsupported, name)
+		}
+	}
+	if len(unsupported) > 0 {
+		log.Info().Strs("tools", unsupported).Msg("disabling tools not supported by the connected Keycloak server")
+		s.RemoveTools(unsupported...)
+	}
+
+	s.AddReceivingMiddleware(trackProgress(), enforceReadOnly(cfg.ReadOnly), requireCapabilities(kc), askUser(kc, cfg), auditCalls(kc, auditLog), projectResults(), listRealmResources(kc))
+}
+
+// enforceReadOnly hides and refuses tools that modify Keycloak when readOnly
+// is set. admin_api_request stays available and refuses non-GET methods itself.
+func enforceReadOnly(readOnly bool) mcp.Middleware {
+	writable := func(name string) bool {
+		return !readOnlyTool(name) && name != "admin_api_request"
+	}
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		if !readOnly {
+			return next
+		}
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if call, ok := req.(*mcp.CallToolRequest); ok && writable(call.Params.Name) {
+				res, _, _ := readOnlyError(call.Params.Name)
+				return res, nil
+			}
+			res, err := next(ctx, method, req)
+			if list, ok := res.(*mcp.ListToolsResult); ok && err == nil {
+				tools := list.Tools[:0:0]
+				for _, t := range list.Tools {
+					if !writable(t.Name) {
+						tools = append(tools, t)
+					}
+				}
+				list.Tools = tools
+			}
+			return res, err
+		}
+	}
+}
+
+// requireCapabilities rejects calls to version-gated tools when the server
+// turns out not to support them.
+func requireCapabilities(kc *keycloak.Client) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if call, ok := req.(*mcp.CallToolRequest); ok {
+				if cap, gated := toolCapabilities[call.Params.Name]; gated {
+					if err := kc.Require(ctx, cap); err != nil {
+						res, _, _ := kcError(call.Params.Name+" is unavailable", err)
+						return res, nil
+					}
+				}
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// auditCalls records every call to a tool that may modify Keycloak.
+func auditCalls(kc *keycloak.Client, auditLog *audit.Logger) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || auditLog == nil || readOnlyTool(call.Params.Name) {
+				return next(ctx, method, req)
+			}
+
+			var args map[string]any
+			_ = json.Unmarshal(call.Params.Arguments, &args)
+			if call.Params.Name == "admin_api_request" {
+				if m, _ := args["method"].(string); strings.EqualFold(m, "GET") {
+					return next(ctx, method, req)
+				}
+			}
+			if csv, ok := args["csv"].(string); ok && call.Params.Name == "import_users" {
+				// Rows may carry passwords, which only keyed arguments are redacted for.
+				args["csv"] = fmt.Sprintf("[%d bytes]", len(csv))
+			}
+			realm, _ := args["realm"].(string)
+			reason, _ := args["reason"].(string)
+			entry := audit.Entry{Tool: call.Params.Name, Realm: kc.ResolveRealm(realm), Reason: reason, Arguments: args}
+
+			res, err := next(ctx, method, req)
+			switch {
+			case err != nil:
+				entry.Error = err.Error()
+			case res != nil:
+				if r, ok := res.(*mcp.CallToolResult); ok && r.IsError {
+					entry.Error = resultText(r)
+				}
+			}
+			auditLog.Record(entry)
+			return res, err
+		}
+	}
+}
+
+func resultText(r *mcp.CallToolResult) string {
+	for _, c := range r.Content {
+		if t, ok := c.(*mcp.TextContent); ok {
+			return t.Text
+		}
+	}
+	return ""
 }
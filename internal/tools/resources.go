@@ -0,0 +1,273 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog/log"
+)
+
+// Resources expose Keycloak objects at keycloak://{realm}/{collection}/{key}
+// URIs so that clients can browse them and attach them as context. They are
+// read with the same calls as the matching get_* and list_* tools, and their
+// contents are compacted and redacted like tool results.
+
+const (
+	resourceScheme = "keycloak"
+	resourceMIME   = "application/json"
+	realmsURI      = resourceScheme + "://realms"
+)
+
+// resourceCollection is a collection of objects in a realm, addressed by key.
+type resourceCollection struct {
+	name        string // URI path segment
+	key         string // URI template variable naming the object
+	title       string
+	description string
+	list        func(ctx context.Context, kc *keycloak.Client, token, realm string) (any, error)
+	get         func(ctx context.Context, kc *keycloak.Client, token, realm, key string) (any, error)
+}
+
+var resourceCollections = []resourceCollection{
+	{
+		name:        "clients",
+		key:         "clientId",
+		title:       "Client",
+		description: "A client, by clientId or ID",
+		list: func(ctx context.Context, kc *keycloak.Client, token, realm string) (any, error) {
+			return kc.GetClients(ctx, token, realm, gocloak.GetClientsParams{})
+		},
+		get: func(ctx context.Context, kc *keycloak.Client, token, realm, key string) (any, error) {
+			id, err := kc.ResolveClientID(ctx, token, realm, key)
+			if err != nil {
+				return nil, err
+			}
+			return kc.GC.GetClient(ctx, token, realm, id)
+		},
+	},
+	{
+		name:        "users",
+		key:         "username",
+		title:       "User",
+		description: "A user, by username, email or ID",
+		list: func(ctx context.Context, kc *keycloak.Client, token, realm string) (any, error) {
+			return kc.GC.GetUsers(ctx, token, realm, gocloak.GetUsersParams{Max: gocloak.IntP(kc.MaxResults())})
+		},
+		get: func(ctx context.Context, kc *keycloak.Client, token, realm, key string) (any, error) {
+			id, err := kc.ResolveUserID(ctx, token, realm, key)
+			if err != nil {
+				return nil, err
+			}
+			return kc.GC.GetUserByID(ctx, token, realm, id)
+		},
+	},
+	{
+		name:        "groups",
+		key:         "path",
+		title:       "Group",
+		description: "A group, by path without the leading slash (eng/platform)",
+		list: func(ctx context.Context, kc *keycloak.Client, token, realm string) (any, error) {
+			return kc.GC.GetGroups(ctx, token, realm, gocloak.GetGroupsParams{Max: gocloak.IntP(kc.MaxResults())})
+		},
+		get: func(ctx context.Context, kc *keycloak.Client, token, realm, key string) (any, error) {
+			id, err := kc.ResolveGroupID(ctx, token, realm, "/"+key)
+			if err != nil {
+				return nil, err
+			}
+			return kc.GC.GetGroup(ctx, token, realm, id)
+		},
+	},
+	{
+		name:        "roles",
+		key:         "role",
+		title:       "Realm role",
+		description: "A realm role, by name or ID",
+		list: func(ctx context.Context, kc *keycloak.Client, token, realm string) (any, error) {
+			return kc.GetRealmRoles(ctx, token, realm, gocloak.GetRoleParams{})
+		},
+		get: func(ctx context.Context, kc *keycloak.Client, token, realm, key string) (any, error) {
+			return kc.ResolveRealmRole(ctx, token, realm, key)
+		},
+	},
+	{
+		name:        "flows",
+		key:         "alias",
+		title:       "Authentication flow",
+		description: "A top-level authentication flow, by alias",
+		list: func(ctx context.Context, kc *keycloak.Client, token, realm string) (any, error) {
+			return kc.GC.GetAuthenticationFlows(ctx, token, realm)
+		},
+		get: func(ctx context.Context, kc *keycloak.Client, token, realm, key string) (any, error) {
+			flows, err := kc.GC.GetAuthenticationFlows(ctx, token, realm)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range flows {
+				if gocloak.PString(f.Alias) == key {
+					return f, nil
+				}
+			}
+			return nil, &gocloak.APIError{Code: http.StatusNotFound, Message: fmt.Sprintf("no flow with alias %q", key)}
+		},
+	},
+}
+
+// registerResources adds the realm list resource and a template for every
+// realm, collection and object URI.
+func registerResources(s *mcp.Server, kc *keycloak.Client) {
+	read := readResource(kc)
+	s.AddResource(&mcp.Resource{
+		URI:         realmsURI,
+		Name:        "realms",
+		Title:       "Realms",
+		Description: "All realms",
+		MIMEType:    resourceMIME,
+	}, read)
+	s.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: resourceScheme + "://{realm}",
+		Name:        "realm",
+		Title:       "Realm",
+		Description: "A realm's settings",
+		MIMEType:    resourceMIME,
+	}, read)
+	for _, c := range resourceCollections {
+		s.AddResourceTemplate(&mcp.ResourceTemplate{
+			URITemplate: resourceScheme + "://{realm}/" + c.name,
+			Name:        c.name,
+			Title:       c.title + " list",
+			Description: "All " + c.name + " in a realm",
+			MIMEType:    resourceMIME,
+		}, read)
+		// Group paths contain slashes, which only reserved expansion matches.
+		variable := c.key
+		if c.name == "groups" {
+			variable = "+" + variable
+		}
+		s.AddResourceTemplate(&mcp.ResourceTemplate{
+			URITemplate: resourceScheme + "://{realm}/" + c.name + "/{" + variable + "}",
+			Name:        strings.TrimSuffix(c.name, "s"),
+			Title:       c.title,
+			Description: c.description,
+			MIMEType:    resourceMIME,
+		}, read)
+	}
+}
+
+// readResource serves every keycloak:// URI: realmsURI, a realm, a collection
+// or an object in one.
+func readResource(kc *keycloak.Client) mcp.ResourceHandler {
+	return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		uri := req.Params.URI
+		u, err := url.Parse(uri)
+		if err != nil || u.Scheme != resourceScheme || u.Host == "" {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		token, err := kc.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to obtain admin token: %w", err)
+		}
+
+		var v any
+		realm := u.Host
+		collection, key, _ := strings.Cut(strings.Trim(u.Path, "/"), "/")
+		switch {
+		case uri == realmsURI:
+			v, err = kc.GetRealms(ctx, token)
+		case collection == "":
+			v, err = kc.GetRealm(ctx, token, realm)
+		default:
+			c := findCollection(collection)
+			switch {
+			case c == nil:
+				return nil, mcp.ResourceNotFoundError(uri)
+			case key == "":
+				v, err = c.list(ctx, kc, token, realm)
+			default:
+				v, err = c.get(ctx, kc, token, realm, key)
+			}
+		}
+		if err != nil {
+			var apiErr *gocloak.APIError
+			if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+				return nil, mcp.ResourceNotFoundError(uri)
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", uri, err)
+		}
+
+		g, err := compactResult(v)
+		if err != nil {
+			return nil, err
+		}
+		redactSecrets(g)
+		b, err := json.MarshalIndent(g, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: resourceMIME, Text: string(b)}},
+		}, nil
+	}
+}
+
+func findCollection(name string) *resourceCollection {
+	for i := range resourceCollections {
+		if resourceCollections[i].name == name {
+			return &resourceCollections[i]
+		}
+	}
+	return nil
+}
+
+// listRealmResources appends every realm and its collections to the last
+// page of resources/list, so that clients can browse without knowing the
+// realm names.
+func listRealmResources(kc *keycloak.Client) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			res, err := next(ctx, method, req)
+			list, ok := res.(*mcp.ListResourcesResult)
+			if method != "resources/list" || err != nil || !ok || list.NextCursor != "" {
+				return res, err
+			}
+			// The static resources stay listed when Keycloak is unreachable.
+			token, err := kc.Token(ctx)
+			if err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("resources/list: failed to obtain admin token; listing static resources only")
+				return list, nil
+			}
+			realms, err := kc.GetRealms(ctx, token)
+			if err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("resources/list: failed to list realms; listing static resources only")
+				return list, nil
+			}
+			for _, r := range realms {
+				name := gocloak.PString(r.Realm)
+				base := resourceScheme + "://" + name
+				list.Resources = append(list.Resources, &mcp.Resource{
+					URI:         base,
+					Name:        name,
+					Title:       gocloak.PString(r.DisplayName),
+					Description: fmt.Sprintf("Realm %s", name),
+					MIMEType:    resourceMIME,
+				})
+				for _, c := range resourceCollections {
+					list.Resources = append(list.Resources, &mcp.Resource{
+						URI:         base + "/" + c.name,
+						Name:        name + "/" + c.name,
+						Description: fmt.Sprintf("All %s in realm %s", c.name, name),
+						MIMEType:    resourceMIME,
+					})
+				}
+			}
+			return list, nil
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// readResource reads uri and decodes its JSON contents into out.
+func (h *harness) readResource(uri string, out any) error {
+	h.t.Helper()
+	res, err := h.cs.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: uri})
+	if err != nil {
+		return err
+	}
+	if len(res.Contents) != 1 || res.Contents[0].MIMEType != resourceMIME {
+		h.t.Fatalf("%s: unexpected contents %+v", uri, res.Contents)
+	}
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), out); err != nil {
+		h.t.Fatalf("%s: %v", uri, err)
+	}
+	return nil
+}
+
+func TestResources(t *testing.T) {
+	h := newHarness(t)
+	h.fake.AddUser("acme", "alice", "alice@example.com")
+	h.fake.AddClient("acme", "web")
+	eng := h.fake.AddGroup("acme", "", "eng")
+	h.fake.AddGroup("acme", eng, "platform")
+	h.fake.AddRealmRole("acme", "deployer")
+
+	list, err := h.cs.ListResources(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uris := map[string]bool{}
+	for _, r := range list.Resources {
+		uris[r.URI] = true
+	}
+	for _, want := range []string{"keycloak://realms", "keycloak://acme", "keycloak://acme/users", "keycloak://master/flows"} {
+		if !uris[want] {
+			t.Errorf("resources/list lacks %s", want)
+		}
+	}
+
+	templates, err := h.cs.ListResourceTemplates(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates.ResourceTemplates) != 1+2*len(resourceCollections) {
+		t.Errorf("got %d resource templates", len(templates.ResourceTemplates))
+	}
+
+	var realms []map[string]any
+	if err := h.readResource("keycloak://realms", &realms); err != nil {
+		t.Fatal(err)
+	}
+	assertNames(t, "realms", realms, "realm", "acme", "master")
+
+	reads := []struct{ uri, key, want string }{
+		{"keycloak://acme", "realm", "acme"},
+		{"keycloak://acme/users/alice", "username", "alice"},
+		{"keycloak://acme/users/alice%40example.com", "username", "alice"},
+		{"keycloak://acme/clients/web", "clientId", "web"},
+		{"keycloak://acme/groups/eng/platform", "path", "/eng/platform"},
+		{"keycloak://acme/roles/deployer", "name", "deployer"},
+		{"keycloak://acme/flows/browser", "alias", "browser"},
+	}
+	for _, r := range reads {
+		var obj map[string]any
+		if err := h.readResource(r.uri, &obj); err != nil {
+			t.Fatalf("%s: %v", r.uri, err)
+		}
+		if obj[r.key] != r.want {
+			t.Errorf("%s: %s = %v, want %q", r.uri, r.key, obj[r.key], r.want)
+		}
+	}
+
+	var users []map[string]any
+	if err := h.readResource("keycloak://acme/users", &users); err != nil {
+		t.Fatal(err)
+	}
+	assertNames(t, "users", users, "username", "alice")
+
+	// Collections return what the matching list tool does.
+	var fromResource, fromTool []map[string]any
+	if err := h.readResource("keycloak://acme/flows", &fromResource); err != nil {
+		t.Fatal(err)
+	}
+	h.okJSON("list_auth_flows", nil, &fromTool)
+	if len(fromResource) == 0 || !reflect.DeepEqual(fromResource, fromTool) {
+		t.Errorf("flows resource = %v, list_auth_flows = %v", fromResource, fromTool)
+	}
+
+	for _, uri := range []string{"keycloak://acme/users/nobody", "keycloak://acme/flows/missing", "keycloak://nowhere", "keycloak://acme/widgets"} {
+		var obj map[string]any
+		var rpcErr *jsonrpc.Error
+		if err := h.readResource(uri, &obj); !errors.As(err, &rpcErr) || rpcErr.Code != mcp.CodeResourceNotFound {
+			t.Errorf("%s: err = %v, want resource not found", uri, err)
+		}
+	}
+}
+
+func TestResourcesListWithoutKeycloak(t *testing.T) {
+	h := newHarness(t)
+	h.fake.Close()
+
+	list, err := h.cs.ListResources(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Resources) != 1 || list.Resources[0].URI != realmsURI {
+		t.Fatalf("resources/list = %v, want only the static resources", list.Resources)
+	}
+}
@@ -14,14 +14,18 @@ import (
 // ---------------------------------------------------------------------------
 
 type listRealmRolesArgs struct {
-	Realm string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	First *int   `json:"first,omitempty" jsonschema:"Pagination offset"`
-	Max   *int   `json:"max,omitempty"   jsonschema:"Maximum number of results"`
+	Realm   string `json:"realm,omitempty"    jsonschema:"Realm name (uses default if omitted)"`
+	First   *int   `json:"first,omitempty"    jsonschema:"Pagination offset"`
+	Max     *int   `json:"max,omitempty"      jsonschema:"Maximum number of results"`
+	NoCache bool   `json:"no_cache,omitempty" jsonschema:"Bypass the lookup cache and read directly from Keycloak"`
+	pageArgs
+	viewArgs
 }
 
 type getRealmRoleArgs struct {
 	Realm    string `json:"realm,omitempty"  jsonschema:"Realm name (uses default if omitted)"`
 	RoleName string `json:"role_name"        jsonschema:"Role name"`
+	viewArgs
 }
 
 type createRealmRoleArgs struct {
@@ -45,41 +49,44 @@ type deleteRealmRoleArgs struct {
 type getRealmRoleCompositesArgs struct {
 	Realm    string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
 	RoleName string `json:"role_name"       jsonschema:"Role name"`
+	viewArgs
 }
 
 type addRealmRoleCompositesArgs struct {
 	Realm    string   `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
 	RoleName string   `json:"role_name"       jsonschema:"Role name"`
-	Roles    []string `json:"roles"           jsonschema:"List of role names to add as composites"`
+	Roles    []string `json:"roles"           jsonschema:"List of realm role names or IDs to add as composites"`
 }
 
 type removeRealmRoleCompositesArgs struct {
 	Realm    string   `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
 	RoleName string   `json:"role_name"       jsonschema:"Role name"`
-	Roles    []string `json:"roles"           jsonschema:"List of role names to remove from composites"`
+	Roles    []string `json:"roles"           jsonschema:"List of realm role names or IDs to remove from composites"`
 }
 
 type listClientRolesArgs struct {
 	Realm    string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	ClientID string `json:"client_id"       jsonschema:"Internal client UUID"`
+	ClientID string `json:"client_id"       jsonschema:"Client UUID or clientId"`
+	viewArgs
 }
 
 type getClientRoleArgs struct {
 	Realm    string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	ClientID string `json:"client_id"       jsonschema:"Internal client UUID"`
+	ClientID string `json:"client_id"       jsonschema:"Client UUID or clientId"`
 	RoleName string `json:"role_name"       jsonschema:"Role name"`
+	viewArgs
 }
 
 type createClientRoleArgs struct {
 	Realm       string `json:"realm,omitempty"       jsonschema:"Realm name (uses default if omitted)"`
-	ClientID    string `json:"client_id"             jsonschema:"Internal client UUID"`
+	ClientID    string `json:"client_id"             jsonschema:"Client UUID or clientId"`
 	Name        string `json:"name"                  jsonschema:"Role name"`
 	Description string `json:"description,omitempty" jsonschema:"Role description"`
 }
 
 type updateClientRoleArgs struct {
 	Realm       string  `json:"realm,omitempty"       jsonschema:"Realm name (uses default if omitted)"`
-	ClientID    string  `json:"client_id"             jsonschema:"Internal client UUID"`
+	ClientID    string  `json:"client_id"             jsonschema:"Client UUID or clientId"`
 	RoleName    string  `json:"role_name"             jsonschema:"Current role name"`
 	Name        *string `json:"name,omitempty"        jsonschema:"New role name"`
 	Description *string `json:"description,omitempty" jsonschema:"New role description"`
@@ -87,24 +94,27 @@ type updateClientRoleArgs struct {
 
 type deleteClientRoleArgs struct {
 	Realm    string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	ClientID string `json:"client_id"       jsonschema:"Internal client UUID"`
+	ClientID string `json:"client_id"       jsonschema:"Client UUID or clientId"`
 	RoleName string `json:"role_name"       jsonschema:"Role name"`
 }
 
 type getUsersByRealmRoleArgs struct {
 	Realm    string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
 	RoleName string `json:"role_name"       jsonschema:"Role name"`
+	viewArgs
 }
 
 type getUsersByClientRoleArgs struct {
 	Realm    string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	ClientID string `json:"client_id"       jsonschema:"Internal client UUID"`
+	ClientID string `json:"client_id"       jsonschema:"Client UUID or clientId"`
 	RoleName string `json:"role_name"       jsonschema:"Role name"`
+	viewArgs
 }
 
 type getGroupsByRealmRoleArgs struct {
 	Realm    string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
 	RoleName string `json:"role_name"       jsonschema:"Role name"`
+	viewArgs
 }
 
 // ---------------------------------------------------------------------------
@@ -114,50 +124,67 @@ type getGroupsByRealmRoleArgs struct {
 func registerRoleTools(s *mcp.Server, kc *keycloak.Client) {
 	// 1. list_realm_roles
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "list_realm_roles",
-		Description: "List all realm-level roles with optional pagination",
+		Name:         "list_realm_roles",
+		Description:  "List all realm-level roles with optional pagination",
+		OutputSchema: listSchema[*gocloak.Role](),
+		Annotations:  readOnly("List realm roles"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args listRealmRolesArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("auth failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 		params := gocloak.GetRoleParams{
 			First: args.First,
 			Max:   args.Max,
 		}
-		roles, err := kc.GC.GetRealmRoles(ctx, token, realm, params)
+		if args.paginated() {
+			res, err := paginate(ctx, newPageQuery("list_realm_roles", realm), args.pageArgs, args.First, resultLimit(args.Max, kc.MaxResults()), func(first, max int) ([]*gocloak.Role, error) {
+				p := params
+				p.First, p.Max = &first, &max
+				return kc.GetRealmRoles(keycloak.WithNoCache(ctx, args.NoCache), token, realm, p)
+			})
+			if err != nil {
+				return pageError("failed to list realm roles", err)
+			}
+			return toolResult(res)
+		}
+		roles, err := kc.GetRealmRoles(keycloak.WithNoCache(ctx, args.NoCache), token, realm, params)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to list realm roles: %v", err))
+			return kcError("failed to list realm roles", err)
 		}
-		return toolResult(roles)
+		return listResult(roles)
 	})
 
 	// 2. get_realm_role
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_realm_role",
-		Description: "Get a realm role by name",
+		Name:         "get_realm_role",
+		Description:  "Get a realm role by name",
+		OutputSchema: outputSchema[gocloak.Role](),
+		Annotations:  readOnly("Get realm role"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getRealmRoleArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("auth failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 		role, err := kc.GC.GetRealmRole(ctx, token, realm, args.RoleName)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get realm role %q: %v", args.RoleName, err))
+			return kcError(fmt.Sprintf("failed to get realm role %q", args.RoleName), err)
 		}
 		return toolResult(role)
 	})
 
 	// 3. create_realm_role
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_realm_role",
-		Description: "Create a new realm-level role",
+		Name:         "create_realm_role",
+		Description:  "Create a new realm-level role",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Create realm role", false),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args createRealmRoleArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("auth failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 		role := gocloak.Role{
@@ -166,25 +193,28 @@ func registerRoleTools(s *mcp.Server, kc *keycloak.Client) {
 		}
 		id, err := kc.GC.CreateRealmRole(ctx, token, realm, role)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to create realm role %q: %v", args.Name, err))
+			return kcError(fmt.Sprintf("failed to create realm role %q", args.Name), err)
 		}
-		return toolSuccess(fmt.Sprintf("Realm role %q created (id=%s)", args.Name, id))
+		kc.Invalidate(realm, keycloak.ResourceRealmRoles)
+		return toolCreated(id, fmt.Sprintf("Realm role %q created (id=%s)", args.Name, id))
 	})
 
 	// 4. update_realm_role
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "update_realm_role",
-		Description: "Update an existing realm role (name and/or description)",
+		Name:         "update_realm_role",
+		Description:  "Update an existing realm role (name and/or description)",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Update realm role", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args updateRealmRoleArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("auth failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
 		existing, err := kc.GC.GetRealmRole(ctx, token, realm, args.RoleName)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get realm role %q: %v", args.RoleName, err))
+			return kcError(fmt.Sprintf("failed to get realm role %q", args.RoleName), err)
 		}
 
 		if args.Name != nil {
@@ -195,165 +225,201 @@ func registerRoleTools(s *mcp.Server, kc *keycloak.Client) {
 		}
 
 		if err := kc.GC.UpdateRealmRole(ctx, token, realm, args.RoleName, *existing); err != nil {
-			return toolError(fmt.Sprintf("failed to update realm role %q: %v", args.RoleName, err))
+			return kcError(fmt.Sprintf("failed to update realm role %q", args.RoleName), err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceRealmRoles)
 		return toolSuccess(fmt.Sprintf("Realm role %q updated", args.RoleName))
 	})
 
 	// 5. delete_realm_role
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_realm_role",
-		Description: "Delete a realm role by name",
+		Name:         "delete_realm_role",
+		Description:  "Delete a realm role by name",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete realm role", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args deleteRealmRoleArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("auth failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 		if err := kc.GC.DeleteRealmRole(ctx, token, realm, args.RoleName); err != nil {
-			return toolError(fmt.Sprintf("failed to delete realm role %q: %v", args.RoleName, err))
+			return kcError(fmt.Sprintf("failed to delete realm role %q", args.RoleName), err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceRealmRoles)
 		return toolSuccess(fmt.Sprintf("Realm role %q deleted", args.RoleName))
 	})
 
 	// 6. get_realm_role_composites
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_realm_role_composites",
-		Description: "Get composite roles for a realm role",
+		Name:         "get_realm_role_composites",
+		Description:  "Get composite roles for a realm role",
+		OutputSchema: listSchema[*gocloak.Role](),
+		Annotations:  readOnly("Get realm role composites"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getRealmRoleCompositesArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("auth failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 		composites, err := kc.GC.GetCompositeRealmRoles(ctx, token, realm, args.RoleName)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get composites for role %q: %v", args.RoleName, err))
+			return kcError(fmt.Sprintf("failed to get composites for role %q", args.RoleName), err)
 		}
-		return toolResult(composites)
+		return listResult(composites)
 	})
 
 	// 7. add_realm_role_composites
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "add_realm_role_composites",
-		Description: "Add composite roles to a realm role",
+		Name:         "add_realm_role_composites",
+		Description:  "Add composite roles to a realm role",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Add realm role composites", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args addRealmRoleCompositesArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("auth failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
 		roles := make([]gocloak.Role, 0, len(args.Roles))
 		for _, rn := range args.Roles {
-			r, err := kc.GC.GetRealmRole(ctx, token, realm, rn)
+			r, err := kc.ResolveRealmRole(ctx, token, realm, rn)
 			if err != nil {
-				return toolError(fmt.Sprintf("failed to resolve role %q: %v", rn, err))
+				return kcError(fmt.Sprintf("failed to resolve role %q", rn), err)
 			}
 			roles = append(roles, *r)
 		}
 
 		if err := kc.GC.AddRealmRoleComposite(ctx, token, realm, args.RoleName, roles); err != nil {
-			return toolError(fmt.Sprintf("failed to add composites to role %q: %v", args.RoleName, err))
+			return kcError(fmt.Sprintf("failed to add composites to role %q", args.RoleName), err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceRealmRoles)
 		return toolSuccess(fmt.Sprintf("Added %d composite role(s) to %q", len(roles), args.RoleName))
 	})
 
 	// 8. remove_realm_role_composites
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "remove_realm_role_composites",
-		Description: "Remove composite roles from a realm role",
+		Name:         "remove_realm_role_composites",
+		Description:  "Remove composite roles from a realm role",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Remove realm role composites", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args removeRealmRoleCompositesArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("auth failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
 		roles := make([]gocloak.Role, 0, len(args.Roles))
 		for _, rn := range args.Roles {
-			r, err := kc.GC.GetRealmRole(ctx, token, realm, rn)
+			r, err := kc.ResolveRealmRole(ctx, token, realm, rn)
 			if err != nil {
-				return toolError(fmt.Sprintf("failed to resolve role %q: %v", rn, err))
+				return kcError(fmt.Sprintf("failed to resolve role %q", rn), err)
 			}
 			roles = append(roles, *r)
 		}
 
 		if err := kc.GC.DeleteRealmRoleComposite(ctx, token, realm, args.RoleName, roles); err != nil {
-			return toolError(fmt.Sprintf("failed to remove composites from role %q: %v", args.RoleName, err))
+			return kcError(fmt.Sprintf("failed to remove composites from role %q", args.RoleName), err)
 		}
+		kc.Invalidate(realm, keycloak.ResourceRealmRoles)
 		return toolSuccess(fmt.Sprintf("Removed %d composite role(s) from %q", len(roles), args.RoleName))
 	})
 
 	// 9. list_client_roles
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "list_client_roles",
-		Description: "List all roles for a specific client",
+		Name:         "list_client_roles",
+		Description:  "List all roles for a specific client",
+		OutputSchema: listSchema[*gocloak.Role](),
+		Annotations:  readOnly("List client roles"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args listClientRolesArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("auth failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
-		roles, err := kc.GC.GetClientRoles(ctx, token, realm, args.ClientID, gocloak.GetRoleParams{})
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
+		roles, err := kc.GC.GetClientRoles(ctx, token, realm, idOfClient, gocloak.GetRoleParams{})
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to list client roles: %v", err))
+			return kcError("failed to list client roles", err)
 		}
-		return toolResult(roles)
+		return listResult(roles)
 	})
 
 	// 10. get_client_role
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_client_role",
-		Description: "Get a client role by name",
+		Name:         "get_client_role",
+		Description:  "Get a client role by name",
+		OutputSchema: outputSchema[gocloak.Role](),
+		Annotations:  readOnly("Get client role"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getClientRoleArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("auth failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
-		role, err := kc.GC.GetClientRole(ctx, token, realm, args.ClientID, args.RoleName)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
+		role, err := kc.GC.GetClientRole(ctx, token, realm, idOfClient, args.RoleName)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get client role %q: %v", args.RoleName, err))
+			return kcError(fmt.Sprintf("failed to get client role %q", args.RoleName), err)
 		}
 		return toolResult(role)
 	})
 
 	// 11. create_client_role
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_client_role",
-		Description: "Create a new role for a specific client",
+		Name:         "create_client_role",
+		Description:  "Create a new role for a specific client",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Create client role", false),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args createClientRoleArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("auth failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
 		role := gocloak.Role{
 			Name:        gocloak.StringP(args.Name),
 			Description: gocloak.StringP(args.Description),
 		}
-		id, err := kc.GC.CreateClientRole(ctx, token, realm, args.ClientID, role)
+		id, err := kc.GC.CreateClientRole(ctx, token, realm, idOfClient, role)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to create client role %q: %v", args.Name, err))
+			return kcError(fmt.Sprintf("failed to create client role %q", args.Name), err)
 		}
-		return toolSuccess(fmt.Sprintf("Client role %q created (id=%s)", args.Name, id))
+		return toolCreated(id, fmt.Sprintf("Client role %q created (id=%s)", args.Name, id))
 	})
 
 	// 12. update_client_role
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "update_client_role",
-		Description: "Update an existing client role. Fetches the role first then applies changes using the role ID.",
+		Name:         "update_client_role",
+		Description:  "Update an existing client role. Fetches the role first then applies changes using the role ID.",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Update client role", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args updateClientRoleArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("auth failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
 
-		existing, err := kc.GC.GetClientRole(ctx, token, realm, args.ClientID, args.RoleName)
+		existing, err := kc.GC.GetClientRole(ctx, token, realm, idOfClient, args.RoleName)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get client role %q: %v", args.RoleName, err))
+			return kcError(fmt.Sprintf("failed to get client role %q", args.RoleName), err)
 		}
 
 		if args.Name != nil {
@@ -363,76 +429,92 @@ func registerRoleTools(s *mcp.Server, kc *keycloak.Client) {
 			existing.Description = args.Description
 		}
 
-		if err := kc.GC.UpdateRole(ctx, token, realm, args.ClientID, *existing); err != nil {
-			return toolError(fmt.Sprintf("failed to update client role %q: %v", args.RoleName, err))
+		if err := kc.GC.UpdateRole(ctx, token, realm, idOfClient, *existing); err != nil {
+			return kcError(fmt.Sprintf("failed to update client role %q", args.RoleName), err)
 		}
 		return toolSuccess(fmt.Sprintf("Client role %q updated", args.RoleName))
 	})
 
 	// 13. delete_client_role
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_client_role",
-		Description: "Delete a client role by name",
+		Name:         "delete_client_role",
+		Description:  "Delete a client role by name",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete client role", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args deleteClientRoleArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("auth failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
-		if err := kc.GC.DeleteClientRole(ctx, token, realm, args.ClientID, args.RoleName); err != nil {
-			return toolError(fmt.Sprintf("failed to delete client role %q: %v", args.RoleName, err))
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
+		if err := kc.GC.DeleteClientRole(ctx, token, realm, idOfClient, args.RoleName); err != nil {
+			return kcError(fmt.Sprintf("failed to delete client role %q", args.RoleName), err)
 		}
 		return toolSuccess(fmt.Sprintf("Client role %q deleted", args.RoleName))
 	})
 
 	// 14. get_users_by_realm_role
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_users_by_realm_role",
-		Description: "Get all users assigned a specific realm role",
+		Name:         "get_users_by_realm_role",
+		Description:  "Get all users assigned a specific realm role",
+		OutputSchema: listSchema[*gocloak.User](),
+		Annotations:  readOnly("Get users by realm role"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getUsersByRealmRoleArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("auth failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 		users, err := kc.GC.GetUsersByRoleName(ctx, token, realm, args.RoleName, gocloak.GetUsersByRoleParams{})
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get users for role %q: %v", args.RoleName, err))
+			return kcError(fmt.Sprintf("failed to get users for role %q", args.RoleName), err)
 		}
-		return toolResult(users)
+		return listResult(users)
 	})
 
 	// 15. get_users_by_client_role
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_users_by_client_role",
-		Description: "Get all users assigned a specific client role",
+		Name:         "get_users_by_client_role",
+		Description:  "Get all users assigned a specific client role",
+		OutputSchema: listSchema[*gocloak.User](),
+		Annotations:  readOnly("Get users by client role"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getUsersByClientRoleArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("auth failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
-		users, err := kc.GC.GetUsersByClientRoleName(ctx, token, realm, args.ClientID, args.RoleName, gocloak.GetUsersByRoleParams{})
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
+		users, err := kc.GC.GetUsersByClientRoleName(ctx, token, realm, idOfClient, args.RoleName, gocloak.GetUsersByRoleParams{})
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get users for client role %q: %v", args.RoleName, err))
+			return kcError(fmt.Sprintf("failed to get users for client role %q", args.RoleName), err)
 		}
-		return toolResult(users)
+		return listResult(users)
 	})
 
 	// 16. get_groups_by_realm_role
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_groups_by_realm_role",
-		Description: "Get all groups assigned a specific realm role",
+		Name:         "get_groups_by_realm_role",
+		Description:  "Get all groups assigned a specific realm role",
+		OutputSchema: listSchema[*gocloak.Group](),
+		Annotations:  readOnly("Get groups by realm role"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getGroupsByRealmRoleArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("auth failed: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 		groups, err := kc.GC.GetGroupsByRole(ctx, token, realm, args.RoleName)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get groups for role %q: %v", args.RoleName, err))
+			return kcError(fmt.Sprintf("failed to get groups for role %q", args.RoleName), err)
 		}
-		return toolResult(groups)
+		return listResult(groups)
 	})
 }
@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"testing"
+)
+
+func TestRealmRoleTools(t *testing.T) {
+	h := newHarness(t)
+
+	h.ok("create_realm_role", map[string]any{"name": "viewer", "description": "Read access"})
+	h.ok("create_realm_role", map[string]any{"name": "editor"})
+	h.fail("create_realm_role", map[string]any{"name": "viewer"}, codeConflict)
+
+	var roles []map[string]any
+	h.okJSON("list_realm_roles", nil, &roles)
+	assertNames(t, "realm roles", roles, "name", "editor", "viewer")
+	var paged pagedResult[map[string]any]
+	h.okJSON("list_realm_roles", map[string]any{"all": true}, &paged)
+	if paged.Count != 2 {
+		t.Fatalf("paged realm roles = %+v", paged)
+	}
+
+	h.ok("update_realm_role", map[string]any{"role_name": "viewer", "description": "Read-only access"})
+	var role map[string]any
+	h.okJSON("get_realm_role", map[string]any{"role_name": "viewer"}, &role)
+	if role["description"] != "Read-only access" {
+		t.Fatalf("update_realm_role not applied: %v", role)
+	}
+	h.fail("get_realm_role", map[string]any{"role_name": "missing"}, codeNotFound)
+
+	h.ok("add_realm_role_composites", map[string]any{"role_name": "editor", "roles": []string{"viewer"}})
+	h.okJSON("get_realm_role_composites", map[string]any{"role_name": "editor"}, &roles)
+	assertNames(t, "composites", roles, "name", "viewer")
+	h.ok("remove_realm_role_composites", map[string]any{"role_name": "editor", "roles": []string{"viewer"}})
+	h.okJSON("get_realm_role_composites", map[string]any{"role_name": "editor"}, &roles)
+	assertNames(t, "composites after removal", roles, "name")
+
+	userID := h.fake.AddUser("acme", "frank", "")
+	h.ok("add_user_realm_roles", map[string]any{"user_id": userID, "roles": []string{"editor"}})
+	var users []map[string]any
+	h.okJSON("get_users_by_realm_role", map[string]any{"role_name": "editor"}, &users)
+	assertNames(t, "users with editor", users, "username", "frank")
+
+	h.fake.AddGroup("acme", "", "writers")
+	h.ok("add_group_realm_roles", map[string]any{"group_id": "writers", "roles": []string{"editor"}})
+	var groups []map[string]any
+	h.okJSON("get_groups_by_realm_role", map[string]any{"role_name": "editor"}, &groups)
+	assertNames(t, "groups with editor", groups, "name", "writers")
+
+	h.ok("delete_realm_role", map[string]any{"role_name": "viewer"})
+	h.fail("get_realm_role", map[string]any{"role_name": "viewer"}, codeNotFound)
+}
+
+func TestClientRoleTools(t *testing.T) {
+	h := newHarness(t)
+	clientID := h.fake.AddClient("acme", "billing")
+
+	h.ok("create_client_role", map[string]any{"client_id": "billing", "name": "invoice-reader"})
+	h.fail("create_client_role", map[string]any{"client_id": clientID, "name": "invoice-reader"}, codeConflict)
+
+	var roles []map[string]any
+	h.okJSON("list_client_roles", map[string]any{"client_id": "billing"}, &roles)
+	assertNames(t, "client roles", roles, "name", "invoice-reader")
+
+	h.ok("update_client_role", map[string]any{"client_id": "billing", "role_name": "invoice-reader", "description": "Reads invoices"})
+	var role map[string]any
+	h.okJSON("get_client_role", map[string]any{"client_id": clientID, "role_name": "invoice-reader"}, &role)
+	if role["description"] != "Reads invoices" || role["clientRole"] != true {
+		t.Fatalf("update_client_role not applied: %v", role)
+	}
+
+	userID := h.fake.AddUser("acme", "grace", "")
+	h.ok("add_user_client_roles", map[string]any{"user_id": userID, "client_id": "billing", "roles": []string{"invoice-reader"}})
+	var users []map[string]any
+	h.okJSON("get_users_by_client_role", map[string]any{"client_id": "billing", "role_name": "invoice-reader"}, &users)
+	assertNames(t, "users with invoice-reader", users, "username", "grace")
+
+	h.ok("delete_client_role", map[string]any{"client_id": "billing", "role_name": "invoice-reader"})
+	h.fail("get_client_role", map[string]any{"client_id": "billing", "role_name": "invoice-reader"}, codeNotFound)
+	h.fail("list_client_roles", map[string]any{"client_id": "missing"}, codeNotFound)
+}
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -9,22 +10,84 @@ import (
 	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
 )
 
-type getServerInfoArgs struct{}
+type getServerInfoArgs struct {
+	Sections []string `json:"sections,omitempty" jsonschema:"Only return these sections: system, memory, providers, themes, features (default: everything except providers and features)"`
+}
+
+type getCacheStatsArgs struct{}
+
+type getCapabilitiesArgs struct{}
+
+// serverInfoSections maps get_server_info section names to top-level keys of
+// Keycloak's serverinfo document.
+var serverInfoSections = map[string][]string{
+	"system":    {"systemInfo"},
+	"memory":    {"memoryInfo"},
+	"providers": {"providers"},
+	"themes":    {"themes"},
+	"features":  {"features", "profileInfo"},
+}
+
+// defaultServerInfoKeys are returned when no section is requested.
+var defaultServerInfoKeys = []string{
+	"systemInfo", "memoryInfo", "passwordPolicies", "protocolMapperTypes", "builtinProtocolMappers", "themes",
+}
 
 func registerServerInfoTools(s *mcp.Server, kc *keycloak.Client) {
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_server_info",
-		Description: "Get Keycloak server info including system, memory, providers, and themes",
+		Name:         "get_server_info",
+		Description:  "Get Keycloak server info including system, memory, providers, and themes. Use sections to fetch only what is needed; providers is large.",
+		OutputSchema: outputSchema[map[string]any](),
+		Annotations:  readOnly("Get server info"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getServerInfoArgs) (*mcp.CallToolResult, any, error) {
+		keys := defaultServerInfoKeys
+		if len(args.Sections) > 0 {
+			keys = nil
+			for _, section := range args.Sections {
+				k, ok := serverInfoSections[section]
+				if !ok {
+					return validationError(fmt.Sprintf("unknown section %q; valid sections are system, memory, providers, themes and features", section))
+				}
+				keys = append(keys, k...)
+			}
+		}
+
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 
-		info, err := kc.GC.GetServerInfo(ctx, token)
+		info, err := kc.ServerInfo(ctx, token)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get server info: %v", err))
+			return kcError("failed to get server info", err)
+		}
+		out := make(map[string]json.RawMessage, len(keys))
+		for _, k := range keys {
+			if v, ok := info[k]; ok {
+				out[k] = v
+			}
+		}
+		return toolResult(out)
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:         "get_cache_stats",
+		Description:  "Get hit/miss counters for the server's Keycloak lookup cache (enabled via KEYCLOAK_CACHE_TTL)",
+		OutputSchema: outputSchema[keycloak.CacheStats](),
+		Annotations:  readOnly("Get cache stats"),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args getCacheStatsArgs) (*mcp.CallToolResult, any, error) {
+		return toolResult(kc.CacheStats())
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:         "get_capabilities",
+		Description:  "Get the detected Keycloak version and which version-specific Admin API features it supports",
+		OutputSchema: outputSchema[keycloak.ServerCapabilities](),
+		Annotations:  readOnly("Get capabilities"),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args getCapabilitiesArgs) (*mcp.CallToolResult, any, error) {
+		if _, err := kc.DetectVersion(ctx); err != nil {
+			return kcError("failed to detect Keycloak version", err)
 		}
-		return toolResult(info)
+		return toolResult(kc.Capabilities())
 	})
 }
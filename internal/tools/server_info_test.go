@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/config"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
+)
+
+func TestServerInfoTools(t *testing.T) {
+	h := newHarness(t)
+
+	var info map[string]any
+	h.okJSON("get_server_info", nil, &info)
+	if info["systemInfo"] == nil {
+		t.Fatalf("server info without systemInfo: %v", info)
+	}
+
+	if info["providers"] != nil || info["features"] != nil {
+		t.Fatalf("default server info includes providers or features: %v", info)
+	}
+	var sections map[string]any
+	h.okJSON("get_server_info", map[string]any{"sections": []string{"providers", "memory"}}, &sections)
+	assertKeys(t, "sections", sections, "memoryInfo", "providers")
+	h.fail("get_server_info", map[string]any{"sections": []string{"plugins"}}, codeValidation)
+
+	var stats keycloak.CacheStats
+	h.okJSON("get_cache_stats", nil, &stats)
+	if stats.Enabled {
+		t.Fatalf("cache enabled without KEYCLOAK_CACHE_TTL: %+v", stats)
+	}
+
+	var caps struct {
+		Version      string          `json:"version"`
+		Capabilities map[string]bool `json:"capabilities"`
+	}
+	h.okJSON("get_capabilities", nil, &caps)
+	if caps.Version != h.fake.Version || !caps.Capabilities["user_profile"] {
+		t.Fatalf("capabilities = %+v", caps)
+	}
+}
+
+func TestLookupCache(t *testing.T) {
+	h := newHarnessWithConfig(t, func(cfg *config.Config) {
+		cfg.CacheTTL = time.Minute
+		cfg.AdminAPIAllowlist = append(cfg.AdminAPIAllowlist, "POST /clients")
+	})
+	h.fake.AddClient("acme", "portal")
+
+	var clients []map[string]any
+	h.okJSON("list_clients", nil, &clients)
+	base := len(clients)
+
+	// Changes made behind the server's back stay invisible until no_cache.
+	h.fake.AddClient("acme", "mobile")
+	h.okJSON("list_clients", nil, &clients)
+	if len(clients) != base {
+		t.Fatalf("cached list_clients = %d clients, want %d", len(clients), base)
+	}
+	var stats keycloak.CacheStats
+	h.okJSON("get_cache_stats", nil, &stats)
+	if !stats.Enabled || stats.Hits != 1 || stats.Misses != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	h.okJSON("list_clients", map[string]any{"no_cache": true}, &clients)
+	if len(clients) != base+1 {
+		t.Fatalf("no_cache list_clients = %d clients, want %d", len(clients), base+1)
+	}
+
+	// Writes through typed tools and admin_api_request invalidate.
+	h.ok("create_client", map[string]any{"client_id": "cli"})
+	h.okJSON("list_clients", nil, &clients)
+	if len(clients) != base+2 {
+		t.Fatalf("list_clients after create_client = %d clients, want %d", len(clients), base+2)
+	}
+	h.ok("admin_api_request", map[string]any{"method": "POST", "path": "/clients", "body": map[string]any{"clientId": "raw"}})
+	h.okJSON("list_clients", nil, &clients)
+	if len(clients) != base+3 {
+		t.Fatalf("list_clients after admin_api_request = %d clients, want %d", len(clients), base+3)
+	}
+}
+
+func TestRealmCacheInvalidation(t *testing.T) {
+	h := newHarnessWithConfig(t, func(cfg *config.Config) { cfg.CacheTTL = time.Minute })
+	idps := func() int {
+		t.Helper()
+		var realm struct {
+			IdentityProviders []map[string]any `json:"identityProviders"`
+		}
+		h.okJSON("get_realm", map[string]any{"realm": "acme"}, &realm)
+		return len(realm.IdentityProviders)
+	}
+
+	// The realm representation embeds the identity providers, so writing one
+	// refetches it.
+	if n := idps(); n != 0 {
+		t.Fatalf("identity providers = %d, want 0", n)
+	}
+	h.ok("create_identity_provider", map[string]any{"alias": "github", "provider_id": "github", "config": map[string]string{"clientId": "x"}})
+	if n := idps(); n != 1 {
+		t.Fatalf("identity providers after create = %d, want 1", n)
+	}
+	h.ok("delete_identity_provider", map[string]any{"alias": "github"})
+	if n := idps(); n != 0 {
+		t.Fatalf("identity providers after delete = %d, want 0", n)
+	}
+}
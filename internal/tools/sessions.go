@@ -15,7 +15,7 @@ import (
 
 type logoutUserAllSessionsArgs struct {
 	Realm  string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID string `json:"user_id"         jsonschema:"User ID to logout from all sessions"`
+	UserID string `json:"user_id"         jsonschema:"User ID, username or email to logout from all sessions"`
 }
 
 type logoutUserSessionArgs struct {
@@ -30,21 +30,60 @@ type getEventsArgs struct {
 	User   string `json:"user,omitempty"   jsonschema:"User filter"`
 	First  *int   `json:"first,omitempty"  jsonschema:"Pagination offset"`
 	Max    *int   `json:"max,omitempty"    jsonschema:"Maximum number of results"`
+	pageArgs
+	viewArgs
 }
 
 type getClientOfflineSessionsArgs struct {
 	Realm    string `json:"realm,omitempty"  jsonschema:"Realm name (uses default if omitted)"`
-	ClientID string `json:"client_id"        jsonschema:"Internal UUID of the client"`
+	ClientID string `json:"client_id"        jsonschema:"Client UUID or clientId"`
 	First    *int   `json:"first,omitempty"  jsonschema:"Pagination offset"`
 	Max      *int   `json:"max,omitempty"    jsonschema:"Maximum number of results"`
+	pageArgs
+	viewArgs
 }
 
 type revokeUserConsentsArgs struct {
 	Realm    string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID   string `json:"user_id"         jsonschema:"User ID"`
+	UserID   string `json:"user_id"         jsonschema:"User ID, username or email"`
 	ClientID string `json:"client_id"       jsonschema:"The clientId string (not UUID)"`
 }
 
+type getUserConsentsArgs struct {
+	Realm  string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
+	UserID string `json:"user_id"         jsonschema:"User ID, username or email"`
+}
+
+type getUserOfflineSessionsArgs struct {
+	Realm    string `json:"realm,omitempty"     jsonschema:"Realm name (uses default if omitted)"`
+	UserID   string `json:"user_id"             jsonschema:"User ID, username or email"`
+	ClientID string `json:"client_id,omitempty" jsonschema:"Client UUID or clientId; every client the user holds offline tokens for if omitted"`
+}
+
+// userConsent is an entry of users/{id}/consents: a client the user granted
+// scopes to or holds offline tokens for. Dates are epoch milliseconds.
+type userConsent struct {
+	ClientID            string         `json:"clientId"`
+	GrantedClientScopes []string       `json:"grantedClientScopes"`
+	CreatedDate         *int64         `json:"createdDate,omitempty"`
+	LastUpdatedDate     *int64         `json:"lastUpdatedDate,omitempty"`
+	AdditionalGrants    []consentGrant `json:"additionalGrants,omitempty" jsonschema:"Grants besides consent; key \"Offline Token\" means the user holds offline tokens for the client"`
+}
+
+type consentGrant struct {
+	Client string `json:"client"`
+	Key    string `json:"key"`
+}
+
+// offlineTokenGrant is the additional grant Keycloak reports for offline tokens.
+const offlineTokenGrant = "Offline Token"
+
+// clientOfflineSessions are a user's offline sessions with one client.
+type clientOfflineSessions struct {
+	ClientID string                               `json:"client_id"`
+	Sessions []*gocloak.UserSessionRepresentation `json:"sessions"`
+}
+
 // ---------------------------------------------------------------------------
 // Registration
 // ---------------------------------------------------------------------------
@@ -53,48 +92,58 @@ func registerSessionTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 1. logout_user_all_sessions
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "logout_user_all_sessions",
-		Description: "Logout a user from all sessions",
+		Name:         "logout_user_all_sessions",
+		Description:  "Logout a user from all sessions",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Log out all user sessions", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args logoutUserAllSessionsArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+		}
 
-		err = kc.GC.LogoutAllSessions(ctx, token, realm, args.UserID)
+		err = kc.GC.LogoutAllSessions(ctx, token, realm, userID)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to logout user from all sessions: %v", err))
+			return kcError("failed to logout user from all sessions", err)
 		}
 		return toolSuccess("User logged out from all sessions")
 	})
 
 	// 2. logout_user_session
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "logout_user_session",
-		Description: "Logout a specific user session",
+		Name:         "logout_user_session",
+		Description:  "Logout a specific user session",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Log out user session", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args logoutUserSessionArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
 		err = kc.GC.LogoutUserSession(ctx, token, realm, args.SessionID)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to logout session: %v", err))
+			return kcError("failed to logout session", err)
 		}
 		return toolSuccess("Session logged out successfully")
 	})
 
 	// 3. get_events
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_events",
-		Description: "Get events for a realm",
+		Name:         "get_events",
+		Description:  "Get events for a realm",
+		OutputSchema: listSchema[*gocloak.EventRepresentation](),
+		Annotations:  readOnly("Get events"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getEventsArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
 
@@ -117,51 +166,186 @@ func registerSessionTools(s *mcp.Server, kc *keycloak.Client) {
 			params.UserID = &args.User
 		}
 
+		if args.paginated() {
+			res, err := paginate(ctx, newPageQuery("get_events", realm, args.Type, args.Client, args.User), args.pageArgs, args.First, resultLimit(args.Max, kc.MaxResults()), func(first, max int) ([]*gocloak.EventRepresentation, error) {
+				p := params
+				f, m := int32(first), int32(max)
+				p.First, p.Max = &f, &m
+				return kc.GC.GetEvents(ctx, token, realm, p)
+			})
+			if err != nil {
+				return pageError("failed to get events", err)
+			}
+			return toolResult(res)
+		}
+
 		events, err := kc.GC.GetEvents(ctx, token, realm, params)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get events: %v", err))
+			return kcError("failed to get events", err)
 		}
-		return toolResult(events)
+		return listResult(events)
 	})
 
 	// 4. get_client_offline_sessions
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_client_offline_sessions",
-		Description: "Get offline sessions for a client",
+		Name:         "get_client_offline_sessions",
+		Description:  "Get offline sessions for a client",
+		OutputSchema: listSchema[*gocloak.UserSessionRepresentation](),
+		Annotations:  readOnly("Get client offline sessions"),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args getClientOfflineSessionsArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+		}
 
 		params := gocloak.GetClientUserSessionsParams{
 			First: args.First,
 			Max:   args.Max,
 		}
 
-		sessions, err := kc.GC.GetClientOfflineSessions(ctx, token, realm, args.ClientID, params)
+		if args.paginated() {
+			res, err := paginate(ctx, newPageQuery("get_client_offline_sessions", realm, idOfClient), args.pageArgs, args.First, resultLimit(args.Max, kc.MaxResults()), func(first, max int) ([]*gocloak.UserSessionRepresentation, error) {
+				p := params
+				p.First, p.Max = &first, &max
+				return kc.GC.GetClientOfflineSessions(ctx, token, realm, idOfClient, p)
+			})
+			if err != nil {
+				return pageError("failed to get client offline sessions", err)
+			}
+			return toolResult(res)
+		}
+
+		sessions, err := kc.GC.GetClientOfflineSessions(ctx, token, realm, idOfClient, params)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get client offline sessions: %v", err))
+			return kcError("failed to get client offline sessions", err)
 		}
-		return toolResult(sessions)
+		return listResult(sessions)
 	})
 
 	// 5. revoke_user_consents
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "revoke_user_consents",
-		Description: "Revoke user consents for a client",
+		Name:         "revoke_user_consents",
+		Description:  "Revoke user consents for a client",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Revoke user consents", true),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args revokeUserConsentsArgs) (*mcp.CallToolResult, any, error) {
 		token, err := kc.Token(ctx)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to get token: %v", err))
+			return tokenError(err)
 		}
 		realm := kc.ResolveRealm(args.Realm)
+		userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+		}
 
-		err = kc.GC.RevokeUserConsents(ctx, token, realm, args.UserID, args.ClientID)
+		err = kc.GC.RevokeUserConsents(ctx, token, realm, userID, args.ClientID)
 		if err != nil {
-			return toolError(fmt.Sprintf("failed to revoke user consents: %v", err))
+			return kcError("failed to revoke user consents", err)
 		}
 		return toolSuccess("User consents revoked successfully")
 	})
+
+	// 6. get_user_consents
+	mcp.AddTool(s, &mcp.Tool{
+		Name:         "get_user_consents",
+		Description:  "List the clients a user granted consent to or holds offline tokens for, with the granted scopes and when consent was given and last updated. Use before revoke_user_consents",
+		OutputSchema: listSchema[userConsent](),
+		Annotations:  readOnly("Get user consents"),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args getUserConsentsArgs) (*mcp.CallToolResult, any, error) {
+		token, err := kc.Token(ctx)
+		if err != nil {
+			return tokenError(err)
+		}
+		realm := kc.ResolveRealm(args.Realm)
+		userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+		}
+
+		consents, err := userConsents(ctx, kc, token, realm, userID)
+		if err != nil {
+			return kcError("failed to get user consents", err)
+		}
+		return listResult(consents)
+	})
+
+	// 7. get_user_offline_sessions
+	mcp.AddTool(s, &mcp.Tool{
+		Name:         "get_user_offline_sessions",
+		Description:  "List a user's offline sessions (long-lived refresh tokens), grouped by client",
+		OutputSchema: listSchema[clientOfflineSessions](),
+		Annotations:  readOnly("Get user offline sessions"),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args getUserOfflineSessionsArgs) (*mcp.CallToolResult, any, error) {
+		token, err := kc.Token(ctx)
+		if err != nil {
+			return tokenError(err)
+		}
+		realm := kc.ResolveRealm(args.Realm)
+		userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+		if err != nil {
+			return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+		}
+
+		// Keycloak only lists offline sessions per client; the consents tell
+		// which clients the user holds offline tokens for.
+		type target struct{ id, clientID string }
+		var targets []target
+		if args.ClientID != "" {
+			idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+			}
+			client, err := kc.GC.GetClient(ctx, token, realm, idOfClient)
+			if err != nil {
+				return kcError("failed to get client", err)
+			}
+			targets = append(targets, target{idOfClient, gocloak.PString(client.ClientID)})
+		} else {
+			consents, err := userConsents(ctx, kc, token, realm, userID)
+			if err != nil {
+				return kcError("failed to get user consents", err)
+			}
+			for _, c := range consents {
+				for _, g := range c.AdditionalGrants {
+					if g.Key == offlineTokenGrant {
+						targets = append(targets, target{g.Client, c.ClientID})
+					}
+				}
+			}
+		}
+
+		out := make([]clientOfflineSessions, 0, len(targets))
+		for _, t := range targets {
+			sessions, err := kc.GC.GetUserOfflineSessionsForClient(ctx, token, realm, userID, t.id)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to get offline sessions for client %q", t.clientID), err)
+			}
+			if len(sessions) == 0 && args.ClientID == "" {
+				continue
+			}
+			out = append(out, clientOfflineSessions{ClientID: t.clientID, Sessions: sessions})
+		}
+		return listResult(out)
+	})
+}
+
+// userConsents fetches users/{id}/consents, which gocloak doesn't expose.
+func userConsents(ctx context.Context, kc *keycloak.Client, token, realm, userID string) ([]userConsent, error) {
+	var consents []userConsent
+	resp, err := kc.GC.GetRequestWithBearerAuth(ctx, token).
+		SetResult(&consents).
+		Get(kc.AdminURL(realm, "/users/"+userID+"/consents"))
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, apiError(resp.StatusCode(), resp.Status(), resp.Body())
+	}
+	return consents, nil
 }
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"testing"
+)
+
+func TestSessionTools(t *testing.T) {
+	h := newHarness(t)
+	userID := h.fake.AddUser("acme", "heidi", "heidi@example.com")
+	h.fake.AddClient("acme", "portal")
+
+	first := h.fake.AddUserSession("acme", userID)
+	h.fake.AddUserSession("acme", userID)
+	var sessions []map[string]any
+	h.ok("logout_user_session", map[string]any{"session_id": first})
+	h.fail("logout_user_session", map[string]any{"session_id": first}, codeNotFound)
+	h.okJSON("get_user_sessions", map[string]any{"user_id": userID}, &sessions)
+	if len(sessions) != 1 || sessions[0]["id"] == first {
+		t.Fatalf("sessions after logout_user_session = %v", sessions)
+	}
+
+	h.ok("logout_user_all_sessions", map[string]any{"user_id": "heidi"})
+	h.fail("logout_user_all_sessions", map[string]any{"user_id": "nobody"}, codeNotFound)
+	h.okJSON("get_user_sessions", map[string]any{"user_id": userID}, &sessions)
+	if len(sessions) != 0 {
+		t.Fatalf("sessions after logout_user_all_sessions = %v", sessions)
+	}
+
+	h.okJSON("get_client_offline_sessions", map[string]any{"client_id": "portal"}, &sessions)
+	var paged pagedResult[map[string]any]
+	h.okJSON("get_client_offline_sessions", map[string]any{"client_id": "portal", "all": true}, &paged)
+	if paged.Count != 0 || paged.Truncated {
+		t.Fatalf("paged offline sessions = %+v", paged)
+	}
+
+	h.ok("revoke_user_consents", map[string]any{"user_id": userID, "client_id": "portal"})
+}
+
+func TestUserConsentTools(t *testing.T) {
+	h := newHarness(t)
+	userID := h.fake.AddUser("acme", "ivan", "ivan@example.com")
+	portal := h.fake.AddClient("acme", "portal")
+	mobile := h.fake.AddClient("acme", "mobile")
+	h.fake.AddClient("acme", "unused")
+	h.fake.AddConsent("acme", userID, portal, "profile", "email")
+	sessionID := h.fake.AddOfflineSession("acme", userID, mobile)
+
+	var consents []userConsent
+	h.okJSON("get_user_consents", map[string]any{"user_id": "ivan"}, &consents)
+	if len(consents) != 2 {
+		t.Fatalf("consents = %+v", consents)
+	}
+	mob, por := consents[0], consents[1]
+	if por.ClientID != "portal" || len(por.GrantedClientScopes) != 2 || por.CreatedDate == nil || len(por.AdditionalGrants) != 0 {
+		t.Fatalf("portal consent = %+v", por)
+	}
+	if mob.ClientID != "mobile" || len(mob.AdditionalGrants) != 1 || mob.AdditionalGrants[0].Key != offlineTokenGrant {
+		t.Fatalf("mobile consent = %+v", mob)
+	}
+
+	var offline []clientOfflineSessions
+	h.okJSON("get_user_offline_sessions", map[string]any{"user_id": "ivan"}, &offline)
+	if len(offline) != 1 || offline[0].ClientID != "mobile" || len(offline[0].Sessions) != 1 ||
+		*offline[0].Sessions[0].ID != sessionID {
+		t.Fatalf("offline sessions = %+v", offline)
+	}
+	offline = nil
+	h.okJSON("get_user_offline_sessions", map[string]any{"user_id": "ivan", "client_id": "portal"}, &offline)
+	if len(offline) != 1 || offline[0].ClientID != "portal" || len(offline[0].Sessions) != 0 {
+		t.Fatalf("portal offline sessions = %+v", offline)
+	}
+	h.fail("get_user_offline_sessions", map[string]any{"user_id": "ivan", "client_id": "missing"}, codeNotFound)
+
+	// Revoking consent also revokes the client's offline tokens.
+	h.ok("revoke_user_consents", map[string]any{"user_id": "ivan", "client_id": "mobile"})
+	consents = nil
+	h.okJSON("get_user_consents", map[string]any{"user_id": "ivan"}, &consents)
+	if len(consents) != 1 || consents[0].ClientID != "portal" {
+		t.Fatalf("consents after revoke = %+v", consents)
+	}
+	offline = nil
+	h.okJSON("get_user_offline_sessions", map[string]any{"user_id": "ivan"}, &offline)
+	if len(offline) != 0 {
+		t.Fatalf("offline sessions after revoke = %+v", offline)
+	}
+}
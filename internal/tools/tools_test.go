@@ -0,0 +1,431 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/auth"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/config"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloaktest"
+)
+
+// calledTools records every tool driven by a test so that TestMain can fail
+// when a registered tool has no test.
+var calledTools sync.Map
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	code := m.Run()
+	if code == 0 && flag.Lookup("test.run").Value.String() == "" {
+		if missing := untestedTools(); len(missing) > 0 {
+			fmt.Fprintf(os.Stderr, "tools without tests: %s\n", strings.Join(missing, ", "))
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+func untestedTools() []string {
+	fake := keycloaktest.NewServer()
+	defer fake.Close()
+	cs := connect(context.Background(), fake.Config(), nil, nil)
+	defer cs.Close()
+	res, err := cs.ListTools(context.Background(), nil)
+	if err != nil {
+		return []string{"(list tools failed: " + err.Error() + ")"}
+	}
+	var missing []string
+	for _, t := range res.Tools {
+		if _, ok := calledTools.Load(t.Name); !ok {
+			missing = append(missing, t.Name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// connect registers the tools on a new server and returns a client session
+// to it. A non-nil transport replaces gocloak's HTTP transport; opts
+// configures the client.
+func connect(ctx context.Context, cfg *config.Config, transport http.RoundTripper, opts *mcp.ClientOptions) *mcp.ClientSession {
+	kc := keycloak.NewClient(cfg, auth.NewTokenManager(cfg))
+	if transport != nil {
+		kc.GC.RestyClient().SetTransport(transport)
+	}
+	s := mcp.NewServer(&mcp.Implementation{Name: "keycloak-mcp", Version: "test"}, &mcp.ServerOptions{CompletionHandler: CompletionHandler(kc)})
+	RegisterAll(s, kc, cfg, nil)
+
+	ct, st := mcp.NewInMemoryTransports()
+	if _, err := s.Connect(ctx, st, nil); err != nil {
+		panic(err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, opts).Connect(ctx, ct, nil)
+	if err != nil {
+		panic(err)
+	}
+	return cs
+}
+
+// harness drives tools against a fake Keycloak through an in-memory MCP
+// client session.
+type harness struct {
+	t    *testing.T
+	fake *keycloaktest.Server
+	cs   *mcp.ClientSession
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	return newHarnessWithConfig(t, nil)
+}
+
+func newHarnessWithConfig(t *testing.T, mutate func(*config.Config)) *harness {
+	t.Helper()
+	fake := keycloaktest.NewServer()
+	t.Cleanup(fake.Close)
+	cfg := fake.Config()
+	if mutate != nil {
+		mutate(cfg)
+	}
+	cs := connect(context.Background(), cfg, nil, nil)
+	t.Cleanup(func() { cs.Close() })
+	return &harness{t: t, fake: fake, cs: cs}
+}
+
+// call invokes a tool and fails the test on protocol errors.
+func (h *harness) call(name string, args map[string]any) *mcp.CallToolResult {
+	h.t.Helper()
+	calledTools.Store(name, true)
+	res, err := h.cs.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		h.t.Fatalf("%s: %v", name, err)
+	}
+	return res
+}
+
+// ok invokes a tool, fails the test if it returned an error and returns the
+// result text.
+func (h *harness) ok(name string, args map[string]any) string {
+	h.t.Helper()
+	res := h.call(name, args)
+	if res.IsError {
+		h.t.Fatalf("%s(%v) failed: %s", name, args, resultText(res))
+	}
+	return resultText(res)
+}
+
+// okJSON invokes a tool and decodes its JSON result into v.
+func (h *harness) okJSON(name string, args map[string]any, v any) {
+	h.t.Helper()
+	text := h.ok(name, args)
+	if err := json.Unmarshal([]byte(text), v); err != nil {
+		h.t.Fatalf("%s: decoding %q: %v", name, text, err)
+	}
+}
+
+// fail invokes a tool, asserts that it failed with code and returns the
+// decoded error payload.
+func (h *harness) fail(name string, args map[string]any, code string) toolErr {
+	h.t.Helper()
+	res := h.call(name, args)
+	if !res.IsError {
+		h.t.Fatalf("%s(%v) succeeded, want %s error: %s", name, args, code, resultText(res))
+	}
+	var e toolErr
+	if err := json.Unmarshal([]byte(resultText(res)), &e); err != nil {
+		h.t.Fatalf("%s: error payload is not JSON: %s", name, resultText(res))
+	}
+	if e.Code != code {
+		h.t.Fatalf("%s(%v) failed with code %q, want %q: %s", name, args, e.Code, code, e.Message)
+	}
+	return e
+}
+
+// idFrom extracts the trailing ID from messages such as "User created with ID: <id>".
+func idFrom(t *testing.T, msg string) string {
+	t.Helper()
+	i := strings.LastIndexAny(msg, " =")
+	id := strings.TrimRight(msg[i+1:], ")")
+	if id == "" {
+		t.Fatalf("no ID in %q", msg)
+	}
+	return id
+}
+
+// names returns the value of key for each object.
+func names(objs []map[string]any, key string) []string {
+	out := make([]string, len(objs))
+	for i, o := range objs {
+		out[i], _ = o[key].(string)
+	}
+	return out
+}
+
+func assertNames(t *testing.T, what string, got []map[string]any, key string, want ...string) {
+	t.Helper()
+	if g := strings.Join(names(got, key), ","); g != strings.Join(want, ",") {
+		t.Fatalf("%s = [%s], want [%s]", what, g, strings.Join(want, ","))
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Cross-cutting error paths
+// ---------------------------------------------------------------------------
+
+func TestBadCredentialsAreUnauthorized(t *testing.T) {
+	h := newHarnessWithConfig(t, func(cfg *config.Config) { cfg.AdminPassword = "wrong" })
+	h.fail("list_users", nil, codeUnauthorized)
+}
+
+func TestUnreachableKeycloakIsUpstreamUnavailable(t *testing.T) {
+	h := newHarness(t)
+	h.fake.Close()
+	h.fail("list_realms", nil, codeUpstreamUnavailable)
+}
+
+func TestReadOnlyModeHidesAndRefusesWrites(t *testing.T) {
+	h := newHarnessWithConfig(t, func(cfg *config.Config) { cfg.ReadOnly = true })
+	res, err := h.cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range res.Tools {
+		if !readOnlyTool(tool.Name) && tool.Name != "admin_api_request" {
+			t.Errorf("read-only mode lists %s", tool.Name)
+		}
+	}
+	h.fail("create_user", map[string]any{"username": "alice"}, codeForbidden)
+	h.fail("admin_api_request", map[string]any{"method": "DELETE", "path": "/users"}, codeForbidden)
+}
+
+// unmodelledTools are tools in domains the fake does not keep state for.
+// They are driven with placeholder arguments only.
+var unmodelledTools = []string{
+	// attack_detection.go
+	"get_brute_force_status", "clear_brute_force_status",
+	// auth_flows.go
+	"get_auth_flow", "create_auth_flow", "delete_auth_flow", "get_auth_flow_executions",
+	"update_auth_flow_execution", "get_required_action", "update_required_action", "delete_required_action",
+	// authorization.go
+	"get_resource_server", "list_resources", "get_resource", "create_resource", "update_resource", "delete_resource",
+	"list_auth_scopes", "create_auth_scope", "delete_auth_scope", "list_policies", "get_policy", "create_policy",
+	"delete_policy", "list_permissions", "create_permission",
+	// client_scopes.go
+	"list_client_scopes", "get_client_scope", "create_client_scope", "update_client_scope", "delete_client_scope",
+	"list_client_scope_protocol_mappers", "create_client_scope_protocol_mapper",
+	"update_client_scope_protocol_mapper", "delete_client_scope_protocol_mapper", "get_default_client_scopes",
+	// components.go
+	"list_components", "get_component", "create_component", "update_component", "delete_component",
+	// identity_providers.go
+	"list_identity_provider_mappers", "create_identity_provider_mapper", "delete_identity_provider_mapper",
+	// sessions.go
+	"get_events",
+}
+
+// TestUnmodelledToolsFailCleanly drives every tool in unmodelledTools with
+// placeholder arguments. Each must answer with a structured tool error rather
+// than a protocol error or a panic.
+func TestUnmodelledToolsFailCleanly(t *testing.T) {
+	h := newHarness(t)
+	res, err := h.cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tools := map[string]*mcp.Tool{}
+	for _, tool := range res.Tools {
+		tools[tool.Name] = tool
+	}
+	for _, name := range unmodelledTools {
+		t.Run(name, func(t *testing.T) {
+			tool, ok := tools[name]
+			if !ok {
+				t.Fatalf("%s is not registered", name)
+			}
+			h := &harness{t: t, fake: h.fake, cs: h.cs}
+			res := h.call(name, placeholderArgs(tool))
+			if !res.IsError {
+				t.Fatalf("succeeded against an unmodelled endpoint: %s", resultText(res))
+			}
+			var e toolErr
+			if err := json.Unmarshal([]byte(resultText(res)), &e); err != nil || e.Code == "" {
+				t.Fatalf("unstructured error: %s", resultText(res))
+			}
+		})
+	}
+}
+
+// placeholderArgs fills every required argument with a dummy value of the
+// right JSON type.
+func placeholderArgs(tool *mcp.Tool) map[string]any {
+	args := map[string]any{}
+	schema, ok := tool.InputSchema.(map[string]any)
+	if !ok {
+		b, _ := json.Marshal(tool.InputSchema)
+		_ = json.Unmarshal(b, &schema)
+	}
+	props, _ := schema["properties"].(map[string]any)
+	required, _ := schema["required"].([]any)
+	for _, r := range required {
+		name := r.(string)
+		prop, _ := props[name].(map[string]any)
+		switch typ := prop["type"]; typ {
+		case "integer", "number":
+			args[name] = 1
+		case "boolean":
+			args[name] = false
+		case "array":
+			args[name] = []any{}
+		case "object":
+			args[name] = map[string]any{}
+		default:
+			args[name] = "placeholder"
+		}
+	}
+	return args
+}
+
+// TestToolAnnotations fails for any tool registered without annotations, and
+// keeps readOnlyHint in line with the name-based check read-only mode uses.
+func TestToolAnnotations(t *testing.T) {
+	h := newHarness(t)
+	tools, err := h.cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range tools.Tools {
+		a := tool.Annotations
+		switch {
+		case a == nil:
+			t.Errorf("%s has no annotations", tool.Name)
+		case a.Title == "":
+			t.Errorf("%s has no title", tool.Name)
+		case a.DestructiveHint == nil || a.OpenWorldHint == nil:
+			t.Errorf("%s leaves destructiveHint or openWorldHint unset", tool.Name)
+		case a.ReadOnlyHint != readOnlyTool(tool.Name):
+			t.Errorf("%s: readOnlyHint = %v, but read-only mode treats it as read-only = %v", tool.Name, a.ReadOnlyHint, readOnlyTool(tool.Name))
+		case a.ReadOnlyHint && *a.DestructiveHint:
+			t.Errorf("%s is both read-only and destructive", tool.Name)
+		}
+	}
+}
+
+func TestStructuredOutput(t *testing.T) {
+	h := newHarnessWithConfig(t, func(cfg *config.Config) { cfg.RevealSecrets = true })
+	tools, err := h.cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range tools.Tools {
+		if tool.OutputSchema == nil {
+			t.Errorf("%s declares no output schema", tool.Name)
+		}
+	}
+
+	structured := func(name string, args map[string]any) map[string]any {
+		t.Helper()
+		r := h.call(name, args)
+		if r.IsError {
+			t.Fatalf("%s failed: %s", name, resultText(r))
+		}
+		var out map[string]any
+		b, _ := json.Marshal(r.StructuredContent)
+		if err := json.Unmarshal(b, &out); err != nil || out == nil {
+			t.Fatalf("%s: no structured content: %s", name, b)
+		}
+		return out
+	}
+
+	created := structured("create_user", map[string]any{"username": "judy"})
+	if created["id"] == "" || created["message"] == "" {
+		t.Fatalf("create_user structured = %v", created)
+	}
+	if user := structured("get_user", map[string]any{"user_id": "judy"}); user["id"] != created["id"] {
+		t.Fatalf("get_user structured = %v", user)
+	}
+	list := structured("list_users", nil)
+	if items, _ := list["items"].([]any); len(items) != 1 || list["count"] != float64(1) {
+		t.Fatalf("list_users structured = %v", list)
+	}
+	if secret := structured("get_client_secret", map[string]any{"id": h.fake.AddClient("acme", "api")}); secret["value"] == "" {
+		t.Fatalf("get_client_secret structured = %v", secret)
+	}
+
+	// The text rendering is kept for clients that ignore structured content.
+	var users []map[string]any
+	h.okJSON("list_users", nil, &users)
+	assertNames(t, "text list_users", users, "username", "judy")
+
+	// Errors carry the error payload instead of the tool's output.
+	res := h.call("get_user", map[string]any{"user_id": "nobody"})
+	if e, _ := res.StructuredContent.(map[string]any); !res.IsError || e["code"] != codeNotFound {
+		t.Fatalf("error structured content = %v", res.StructuredContent)
+	}
+}
+
+func assertKeys(t *testing.T, what string, obj map[string]any, want ...string) {
+	t.Helper()
+	got := make([]string, 0, len(obj))
+	for k := range obj {
+		got = append(got, k)
+	}
+	sort.Strings(got)
+	if g := strings.Join(got, ","); g != strings.Join(want, ",") {
+		t.Fatalf("%s keys = [%s], want [%s]", what, g, strings.Join(want, ","))
+	}
+}
+
+func TestFieldsAndViews(t *testing.T) {
+	h := newHarness(t)
+	id := idFrom(t, h.ok("create_user", map[string]any{"username": "kim", "email": "kim@example.com", "first_name": "Kim"}))
+
+	// Null and empty fields are omitted.
+	var user map[string]any
+	h.okJSON("get_user", map[string]any{"user_id": id}, &user)
+	for k, v := range user {
+		if v == nil || v == "" {
+			t.Errorf("get_user returned empty %s", k)
+		}
+	}
+
+	var projected, summary map[string]any
+	h.okJSON("get_user", map[string]any{"user_id": id, "fields": []string{"id", "username"}}, &projected)
+	assertKeys(t, "fields", projected, "id", "username")
+	h.okJSON("get_user", map[string]any{"user_id": id, "view": "summary"}, &summary)
+	if summary["createdTimestamp"] != nil || summary["username"] != "kim" || summary["firstName"] != "Kim" {
+		t.Fatalf("summary view = %v", summary)
+	}
+
+	var users []map[string]any
+	h.okJSON("list_users", map[string]any{"fields": []string{"email"}}, &users)
+	if len(users) != 1 {
+		t.Fatalf("list_users = %v", users)
+	}
+	assertKeys(t, "list fields", users[0], "email")
+
+	// Projection applies to the items of a paginated result, and to the
+	// structured content as well as the text.
+	res := h.call("list_users", map[string]any{"all": true, "fields": []string{"username"}})
+	var page pagedResult[map[string]any]
+	if err := json.Unmarshal([]byte(resultText(res)), &page); err != nil || page.Count != 1 {
+		t.Fatalf("paged projection = %s", resultText(res))
+	}
+	assertKeys(t, "paged fields", page.Items[0], "username")
+	b, _ := json.Marshal(res.StructuredContent)
+	if err := json.Unmarshal(b, &page); err != nil || page.Count != 1 {
+		t.Fatalf("structured projection = %s", b)
+	}
+	assertKeys(t, "structured fields", page.Items[0], "username")
+
+	h.fail("get_user", map[string]any{"user_id": id, "view": "brief"}, codeValidation)
+}
@@ -0,0 +1,279 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog/log"
+)
+
+// Modes of set_user_attributes.
+const (
+	attrReplace = "replace"
+	attrMerge   = "merge"
+)
+
+type getUserAttributesArgs struct {
+	Realm  string   `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
+	UserID string   `json:"user_id"         jsonschema:"User ID, username or email"`
+	Names  []string `json:"names,omitempty" jsonschema:"Only return these attributes"`
+}
+
+type setUserAttributesArgs struct {
+	Realm      string              `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
+	UserID     string              `json:"user_id"         jsonschema:"User ID, username or email"`
+	Attributes map[string][]string `json:"attributes"      jsonschema:"Attributes to set, each with its values, e.g. {\"tenant_id\": [\"t-42\"]}. Attributes not named are kept"`
+	Mode       string              `json:"mode,omitempty"  jsonschema:"replace (default) overwrites the values of each named attribute; merge adds the values it lacks"`
+}
+
+type removeUserAttributesArgs struct {
+	Realm  string   `json:"realm,omitempty"  jsonschema:"Realm name (uses default if omitted)"`
+	UserID string   `json:"user_id"          jsonschema:"User ID, username or email"`
+	Names  []string `json:"names"            jsonschema:"Attributes to remove"`
+	Values []string `json:"values,omitempty" jsonschema:"Only remove these values, keeping the others; needs exactly one name"`
+}
+
+type searchUsersByAttributeArgs struct {
+	Realm      string            `json:"realm,omitempty"      jsonschema:"Realm name (uses default if omitted)"`
+	Attributes map[string]string `json:"attributes"           jsonschema:"Attribute values users must all have, e.g. {\"tenant_id\": \"t-42\"}. Values cannot contain spaces"`
+	Exact      *bool             `json:"exact,omitempty"      jsonschema:"Match values exactly (true) or as substrings (false); Keycloak decides if omitted"`
+	First      *int              `json:"first,omitempty"      jsonschema:"Pagination offset"`
+	Max        *int              `json:"max,omitempty"        jsonschema:"Maximum number of results"`
+	pageArgs
+	viewArgs
+}
+
+// userAttributes is the result of the attribute tools.
+type userAttributes struct {
+	UserID     string              `json:"user_id"`
+	Username   string              `json:"username"`
+	Attributes map[string][]string `json:"attributes"`
+	Dropped    []string            `json:"dropped,omitempty" jsonschema:"Attributes that were written but that Keycloak did not store"`
+	Warning    string              `json:"warning,omitempty" jsonschema:"Why attributes were dropped and how to keep them"`
+}
+
+func registerUserAttributeTools(s *mcp.Server, kc *keycloak.Client) {
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:         "get_user_attributes",
+		Description:  "Get a user's custom attributes, such as tenant IDs or entitlements",
+		OutputSchema: outputSchema[userAttributes](),
+		Annotations:  readOnly("Get user attributes"),
+	},
+		func(ctx context.Context, req *mcp.CallToolRequest, args getUserAttributesArgs) (*mcp.CallToolResult, any, error) {
+			token, err := kc.Token(ctx)
+			if err != nil {
+				return tokenError(err)
+			}
+			realm := kc.ResolveRealm(args.Realm)
+			user, err := userForAttributes(ctx, kc, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to get user %q", args.UserID), err)
+			}
+
+			attrs := attributesOf(user)
+			if len(args.Names) > 0 {
+				picked := map[string][]string{}
+				for _, name := range args.Names {
+					if v, ok := attrs[name]; ok {
+						picked[name] = v
+					}
+				}
+				attrs = picked
+			}
+			return toolResult(userAttributes{UserID: gocloak.PString(user.ID), Username: gocloak.PString(user.Username), Attributes: attrs})
+		},
+	)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:         "set_user_attributes",
+		Description:  "Set custom attributes on a user, replacing the values of each named attribute or merging new values into them. Other attributes are kept",
+		OutputSchema: outputSchema[userAttributes](),
+		Annotations:  destructive("Set user attributes", true),
+	},
+		func(ctx context.Context, req *mcp.CallToolRequest, args setUserAttributesArgs) (*mcp.CallToolResult, any, error) {
+			if len(args.Attributes) == 0 {
+				return validationError("attributes must name at least one attribute")
+			}
+			mode := args.Mode
+			if mode == "" {
+				mode = attrReplace
+			}
+			if mode != attrReplace && mode != attrMerge {
+				return validationError(fmt.Sprintf("mode must be %s or %s, not %q", attrReplace, attrMerge, args.Mode))
+			}
+			token, err := kc.Token(ctx)
+			if err != nil {
+				return tokenError(err)
+			}
+			realm := kc.ResolveRealm(args.Realm)
+			user, err := userForAttributes(ctx, kc, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to get user %q", args.UserID), err)
+			}
+
+			attrs := attributesOf(user)
+			for name, values := range args.Attributes {
+				if mode == attrReplace {
+					attrs[name] = values
+					continue
+				}
+				for _, v := range values {
+					if !slices.Contains(attrs[name], v) {
+						attrs[name] = append(attrs[name], v)
+					}
+				}
+			}
+			return saveAttributes(ctx, kc, token, realm, user, attrs)
+		},
+	)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:         "remove_user_attributes",
+		Description:  "Remove custom attributes from a user, or only some of one attribute's values",
+		OutputSchema: outputSchema[userAttributes](),
+		Annotations:  destructive("Remove user attributes", true),
+	},
+		func(ctx context.Context, req *mcp.CallToolRequest, args removeUserAttributesArgs) (*mcp.CallToolResult, any, error) {
+			if len(args.Names) == 0 {
+				return validationError("names must list at least one attribute")
+			}
+			if len(args.Values) > 0 && len(args.Names) != 1 {
+				return validationError("values needs exactly one attribute in names")
+			}
+			token, err := kc.Token(ctx)
+			if err != nil {
+				return tokenError(err)
+			}
+			realm := kc.ResolveRealm(args.Realm)
+			user, err := userForAttributes(ctx, kc, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to get user %q", args.UserID), err)
+			}
+
+			attrs := attributesOf(user)
+			if len(args.Values) > 0 {
+				name := args.Names[0]
+				attrs[name] = slices.DeleteFunc(attrs[name], func(v string) bool { return slices.Contains(args.Values, v) })
+				if len(attrs[name]) == 0 {
+					delete(attrs, name)
+				}
+			} else {
+				for _, name := range args.Names {
+					delete(attrs, name)
+				}
+			}
+			return saveAttributes(ctx, kc, token, realm, user, attrs)
+		},
+	)
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:         "search_users_by_attribute",
+		Description:  "Find users by custom attribute values, e.g. every user of a tenant",
+		OutputSchema: listSchema[*gocloak.User](),
+		Annotations:  readOnly("Search users by attribute"),
+	},
+		func(ctx context.Context, req *mcp.CallToolRequest, args searchUsersByAttributeArgs) (*mcp.CallToolResult, any, error) {
+			query, err := attributeQuery(args.Attributes)
+			if err != nil {
+				return validationError(err.Error())
+			}
+			token, err := kc.Token(ctx)
+			if err != nil {
+				return tokenError(err)
+			}
+			realm := kc.ResolveRealm(args.Realm)
+
+			params := gocloak.GetUsersParams{
+				Q:     gocloak.StringP(query),
+				Exact: args.Exact,
+				First: args.First,
+				Max:   args.Max,
+			}
+			if args.paginated() {
+				return listUsersPaged(ctx, kc, token, realm, "search_users_by_attribute", args.pageArgs, params, args.Max)
+			}
+
+			users, err := kc.GC.GetUsers(ctx, token, realm, params)
+			if err != nil {
+				return kcError("failed to search users", err)
+			}
+			return listResult(users)
+		},
+	)
+}
+
+// userForAttributes fetches the user whose attributes a tool reads or changes.
+func userForAttributes(ctx context.Context, kc *keycloak.Client, token, realm, ref string) (*gocloak.User, error) {
+	userID, err := kc.ResolveUserID(ctx, token, realm, ref)
+	if err != nil {
+		return nil, err
+	}
+	return kc.GC.GetUserByID(ctx, token, realm, userID)
+}
+
+func attributesOf(user *gocloak.User) map[string][]string {
+	attrs := map[string][]string{}
+	if user.Attributes != nil {
+		for k, v := range *user.Attributes {
+			attrs[k] = v
+		}
+	}
+	return attrs
+}
+
+// saveAttributes writes attrs back as the user's complete attribute set and
+// returns what Keycloak stored. Keycloak 24+ silently drops attributes the
+// user profile doesn't declare unless unmanaged attributes are enabled, so
+// the user is read back and missing names are reported.
+func saveAttributes(ctx context.Context, kc *keycloak.Client, token, realm string, user *gocloak.User, attrs map[string][]string) (*mcp.CallToolResult, any, error) {
+	user.Attributes = &attrs
+	if err := kc.GC.UpdateUser(ctx, token, realm, *user); err != nil {
+		return kcError("failed to update user attributes", err)
+	}
+	stored, err := kc.GC.GetUserByID(ctx, token, realm, gocloak.PString(user.ID))
+	if err != nil {
+		return kcError("failed to read back user attributes", err)
+	}
+
+	out := userAttributes{UserID: gocloak.PString(stored.ID), Username: gocloak.PString(stored.Username), Attributes: attributesOf(stored)}
+	for name := range attrs {
+		if _, ok := out.Attributes[name]; !ok {
+			out.Dropped = append(out.Dropped, name)
+		}
+	}
+	if len(out.Dropped) > 0 {
+		sort.Strings(out.Dropped)
+		out.Warning = "Keycloak did not store these attributes"
+		if kc.Supports(keycloak.CapUnmanagedAttributes) {
+			out.Warning += ": the realm's user profile does not declare them and unmanaged attributes are disabled. " +
+				"Declare them with create_user_profile_attribute, or enable unmanaged attributes in the user profile"
+		}
+		log.Ctx(ctx).Warn().Str("realm", realm).Str("user_id", out.UserID).Strs("attributes", out.Dropped).
+			Msg("Keycloak dropped user attributes")
+	}
+	return toolResult(out)
+}
+
+// attributeQuery builds Keycloak's q parameter, key:value terms separated by
+// spaces, which is why neither keys nor values may contain one.
+func attributeQuery(attrs map[string]string) (string, error) {
+	if len(attrs) == 0 {
+		return "", fmt.Errorf("attributes must name at least one attribute")
+	}
+	terms := make([]string, 0, len(attrs))
+	for k, v := range attrs {
+		if k == "" || strings.ContainsAny(k, " :") || strings.Contains(v, " ") {
+			return "", fmt.Errorf("attribute %q: keys cannot be empty or contain spaces or colons, and values cannot contain spaces", k)
+		}
+		terms = append(terms, k+":"+v)
+	}
+	sort.Strings(terms)
+	return strings.Join(terms, " "), nil
+}
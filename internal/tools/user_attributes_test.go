@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUserAttributes(t *testing.T) {
+	h := newHarness(t)
+	h.fake.AddUser("acme", "alice", "alice@example.com")
+	h.fake.AddUser("acme", "bob", "bob@example.com")
+
+	var got userAttributes
+	h.okJSON("set_user_attributes", map[string]any{"user_id": "alice", "attributes": map[string]any{
+		"tenant_id": []string{"t-42"}, "entitlements": []string{"reports"},
+	}}, &got)
+	h.okJSON("set_user_attributes", map[string]any{"user_id": "alice", "mode": "merge", "attributes": map[string]any{
+		"entitlements": []string{"reports", "billing"},
+	}}, &got)
+	if len(got.Attributes["entitlements"]) != 2 || got.Attributes["tenant_id"][0] != "t-42" {
+		t.Fatalf("after merge = %v", got.Attributes)
+	}
+	h.okJSON("set_user_attributes", map[string]any{"user_id": "bob", "attributes": map[string]any{"tenant_id": []string{"t-7"}}}, &got)
+
+	var users []map[string]any
+	h.okJSON("search_users_by_attribute", map[string]any{"attributes": map[string]any{"tenant_id": "t-42"}}, &users)
+	assertNames(t, "tenant t-42", users, "username", "alice")
+	h.okJSON("search_users_by_attribute", map[string]any{"attributes": map[string]any{"tenant_id": "t-42", "entitlements": "billing"}}, &users)
+	assertNames(t, "t-42 with billing", users, "username", "alice")
+	h.fail("search_users_by_attribute", map[string]any{"attributes": map[string]any{"tenant_id": "t 42"}}, codeValidation)
+
+	got = userAttributes{}
+	h.okJSON("remove_user_attributes", map[string]any{"user_id": "alice", "names": []string{"entitlements"}, "values": []string{"reports"}}, &got)
+	if v := got.Attributes["entitlements"]; len(v) != 1 || v[0] != "billing" {
+		t.Fatalf("entitlements = %v, want [billing]", v)
+	}
+	h.okJSON("remove_user_attributes", map[string]any{"user_id": "alice", "names": []string{"entitlements"}}, &got)
+	got = userAttributes{} // Unmarshal keeps the keys of a map it decodes into
+	h.okJSON("get_user_attributes", map[string]any{"user_id": "alice"}, &got)
+	if _, ok := got.Attributes["entitlements"]; ok || len(got.Attributes) != 1 {
+		t.Fatalf("attributes = %v, want only tenant_id", got.Attributes)
+	}
+	got = userAttributes{}
+	h.okJSON("get_user_attributes", map[string]any{"user_id": "alice", "names": []string{"missing"}}, &got)
+	if len(got.Attributes) != 0 {
+		t.Fatalf("attributes = %v, want none", got.Attributes)
+	}
+
+	h.fail("set_user_attributes", map[string]any{"user_id": "alice", "mode": "append", "attributes": map[string]any{"a": []string{"b"}}}, codeValidation)
+	h.fail("remove_user_attributes", map[string]any{"user_id": "alice", "names": []string{"a", "b"}, "values": []string{"c"}}, codeValidation)
+}
+
+func TestUserAttributesDroppedByKeycloak(t *testing.T) {
+	h := newHarness(t)
+	h.fake.AddUser("acme", "alice", "alice@example.com")
+	h.fake.SetUnmanagedAttributePolicy("acme", "")
+	h.ok("create_user_profile_attribute", map[string]any{"name": "tenant_id"})
+
+	var got userAttributes
+	h.okJSON("set_user_attributes", map[string]any{"user_id": "alice", "attributes": map[string]any{
+		"tenant_id": []string{"t-42"}, "plan": []string{"gold"},
+	}}, &got)
+	if len(got.Dropped) != 1 || got.Dropped[0] != "plan" || !strings.Contains(got.Warning, "create_user_profile_attribute") {
+		t.Fatalf("dropped = %v, warning = %q", got.Dropped, got.Warning)
+	}
+	if _, ok := got.Attributes["plan"]; ok || got.Attributes["tenant_id"][0] != "t-42" {
+		t.Fatalf("attributes = %v, want only what Keycloak stored", got.Attributes)
+	}
+}
@@ -3,6 +3,8 @@ package tools
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/Nerzal/gocloak/v13"
 	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
@@ -18,11 +20,14 @@ type listUsersArgs struct {
 	First  *int   `json:"first,omitempty"  jsonschema:"Pagination offset"`
 	Max    *int   `json:"max,omitempty"    jsonschema:"Maximum number of results"`
 	Search string `json:"search,omitempty" jsonschema:"Search string for users"`
+	pageArgs
+	viewArgs
 }
 
 type getUserArgs struct {
 	Realm  string `json:"realm,omitempty"   jsonschema:"Realm name (uses default if omitted)"`
-	UserID string `json:"user_id"           jsonschema:"User ID"`
+	UserID string `json:"user_id"           jsonschema:"User ID, username or email"`
+	viewArgs
 }
 
 type searchUsersArgs struct {
@@ -34,31 +39,38 @@ type searchUsersArgs struct {
 	Enabled   *bool  `json:"enabled,omitempty"    jsonschema:"Filter by enabled status"`
 	First     *int   `json:"first,omitempty"      jsonschema:"Pagination offset"`
 	Max       *int   `json:"max,omitempty"        jsonschema:"Maximum number of results"`
+	pageArgs
+	viewArgs
 }
 
 type createUserArgs struct {
-	Realm             string `json:"realm,omitempty"              jsonschema:"Realm name (uses default if omitted)"`
-	Username          string `json:"username"                     jsonschema:"Username for the new user"`
-	Email             string `json:"email,omitempty"              jsonschema:"Email address"`
-	FirstName         string `json:"first_name,omitempty"        jsonschema:"First name"`
-	LastName          string `json:"last_name,omitempty"         jsonschema:"Last name"`
-	Enabled           *bool  `json:"enabled,omitempty"            jsonschema:"Whether the user is enabled (default true)"`
-	Password          string `json:"password,omitempty"           jsonschema:"Initial password"`
-	TemporaryPassword *bool  `json:"temporary_password,omitempty" jsonschema:"Whether the password is temporary"`
+	Realm             string   `json:"realm,omitempty"              jsonschema:"Realm name (uses default if omitted)"`
+	Username          string   `json:"username"                     jsonschema:"Username for the new user"`
+	Email             string   `json:"email,omitempty"              jsonschema:"Email address"`
+	FirstName         string   `json:"first_name,omitempty"         jsonschema:"First name"`
+	LastName          string   `json:"last_name,omitempty"          jsonschema:"Last name"`
+	Enabled           *bool    `json:"enabled,omitempty"            jsonschema:"Whether the user is enabled (default true)"`
+	Password          string   `json:"password,omitempty"           jsonschema:"Initial password"`
+	TemporaryPassword *bool    `json:"temporary_password,omitempty" jsonschema:"Whether the password is temporary"`
+	EmailVerified     *bool    `json:"email_verified,omitempty"     jsonschema:"Whether the email address is verified"`
+	RequiredActions   []string `json:"required_actions,omitempty"   jsonschema:"Required actions the user must complete at first login, e.g. UPDATE_PASSWORD; see list_required_actions"`
 }
 
 type updateUserArgs struct {
-	Realm     string  `json:"realm,omitempty"      jsonschema:"Realm name (uses default if omitted)"`
-	UserID    string  `json:"user_id"              jsonschema:"User ID"`
-	Email     *string `json:"email,omitempty"      jsonschema:"New email address"`
-	FirstName *string `json:"first_name,omitempty" jsonschema:"New first name"`
-	LastName  *string `json:"last_name,omitempty"  jsonschema:"New last name"`
-	Enabled   *bool   `json:"enabled,omitempty"    jsonschema:"Whether the user is enabled"`
+	Realm           string   `json:"realm,omitempty"            jsonschema:"Realm name (uses default if omitted)"`
+	UserID          string   `json:"user_id"                    jsonschema:"User ID, username or email"`
+	Email           *string  `json:"email,omitempty"            jsonschema:"New email address"`
+	FirstName       *string  `json:"first_name,omitempty"       jsonschema:"New first name"`
+	LastName        *string  `json:"last_name,omitempty"        jsonschema:"New last name"`
+	Enabled         *bool    `json:"enabled,omitempty"          jsonschema:"Whether the user is enabled"`
+	EmailVerified   *bool    `json:"email_verified,omitempty"   jsonschema:"Whether the email address is verified"`
+	RequiredActions []string `json:"required_actions,omitempty" jsonschema:"Required actions for the next login, replacing the current ones; [] clears them. See list_required_actions"`
+	TOTP            *bool    `json:"totp,omitempty"             jsonschema:"true makes the user configure TOTP at next login (CONFIGURE_TOTP); false removes their OTP credentials"`
 }
 
 type deleteUserArgs struct {
 	Realm  string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID string `json:"user_id"         jsonschema:"User ID"`
+	UserID string `json:"user_id"         jsonschema:"User ID, username or email"`
 }
 
 type countUsersArgs struct {
@@ -68,64 +80,68 @@ type countUsersArgs struct {
 
 type setUserPasswordArgs struct {
 	Realm     string `json:"realm,omitempty"     jsonschema:"Realm name (uses default if omitted)"`
-	UserID    string `json:"user_id"             jsonschema:"User ID"`
+	UserID    string `json:"user_id"             jsonschema:"User ID, username or email"`
 	Password  string `json:"password"            jsonschema:"New password"`
 	Temporary *bool  `json:"temporary,omitempty" jsonschema:"Whether the password is temporary"`
 }
 
 type getUserCredentialsArgs struct {
 	Realm  string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID string `json:"user_id"         jsonschema:"User ID"`
+	UserID string `json:"user_id"         jsonschema:"User ID, username or email"`
+	viewArgs
 }
 
 type deleteUserCredentialArgs struct {
 	Realm        string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID       string `json:"user_id"         jsonschema:"User ID"`
+	UserID       string `json:"user_id"         jsonschema:"User ID, username or email"`
 	CredentialID string `json:"credential_id"   jsonschema:"Credential ID to delete"`
 }
 
 type sendVerifyEmailArgs struct {
 	Realm  string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID string `json:"user_id"         jsonschema:"User ID"`
+	UserID string `json:"user_id"         jsonschema:"User ID, username or email"`
 }
 
 type executeActionsEmailArgs struct {
 	Realm    string   `json:"realm,omitempty"    jsonschema:"Realm name (uses default if omitted)"`
-	UserID   string   `json:"user_id"            jsonschema:"User ID"`
-	Actions  []string `json:"actions"            jsonschema:"List of actions to execute"`
+	UserID   string   `json:"user_id"            jsonschema:"User ID, username or email"`
+	Actions  []string `json:"actions"            jsonschema:"Required actions to execute, e.g. UPDATE_PASSWORD; see list_required_actions"`
 	Lifespan *int     `json:"lifespan,omitempty" jsonschema:"Lifespan of the action token in seconds"`
 }
 
 type getUserGroupsArgs struct {
 	Realm  string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID string `json:"user_id"         jsonschema:"User ID"`
+	UserID string `json:"user_id"         jsonschema:"User ID, username or email"`
+	viewArgs
 }
 
 type addUserToGroupArgs struct {
 	Realm   string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID  string `json:"user_id"         jsonschema:"User ID"`
-	GroupID string `json:"group_id"        jsonschema:"Group ID"`
+	UserID  string `json:"user_id"         jsonschema:"User ID, username or email"`
+	GroupID string `json:"group_id"        jsonschema:"Group ID, path (e.g. /eng/platform) or unique name"`
 }
 
 type removeUserFromGroupArgs struct {
 	Realm   string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID  string `json:"user_id"         jsonschema:"User ID"`
-	GroupID string `json:"group_id"        jsonschema:"Group ID"`
+	UserID  string `json:"user_id"         jsonschema:"User ID, username or email"`
+	GroupID string `json:"group_id"        jsonschema:"Group ID, path (e.g. /eng/platform) or unique name"`
 }
 
 type getUserSessionsArgs struct {
 	Realm  string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID string `json:"user_id"         jsonschema:"User ID"`
+	UserID string `json:"user_id"         jsonschema:"User ID, username or email"`
+	viewArgs
 }
 
 type getUserFederatedIdentitiesArgs struct {
 	Realm  string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID string `json:"user_id"         jsonschema:"User ID"`
+	UserID string `json:"user_id"         jsonschema:"User ID, username or email"`
+	viewArgs
 }
 
 type createUserFederatedIdentityArgs struct {
 	Realm             string `json:"realm,omitempty"      jsonschema:"Realm name (uses default if omitted)"`
-	UserID            string `json:"user_id"              jsonschema:"User ID"`
+	UserID            string `json:"user_id"              jsonschema:"User ID, username or email"`
 	ProviderID        string `json:"provider_id"          jsonschema:"Identity provider alias"`
 	FederatedUserID   string `json:"federated_user_id"    jsonschema:"User ID at the identity provider"`
 	FederatedUsername string `json:"federated_username"   jsonschema:"Username at the identity provider"`
@@ -133,38 +149,40 @@ type createUserFederatedIdentityArgs struct {
 
 type deleteUserFederatedIdentityArgs struct {
 	Realm      string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID     string `json:"user_id"         jsonschema:"User ID"`
+	UserID     string `json:"user_id"         jsonschema:"User ID, username or email"`
 	ProviderID string `json:"provider_id"     jsonschema:"Identity provider alias"`
 }
 
 type getUserRealmRolesArgs struct {
 	Realm  string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID string `json:"user_id"         jsonschema:"User ID"`
+	UserID string `json:"user_id"         jsonschema:"User ID, username or email"`
+	viewArgs
 }
 
 type addUserRealmRolesArgs struct {
 	Realm  string   `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID string   `json:"user_id"         jsonschema:"User ID"`
-	Roles  []string `json:"roles"           jsonschema:"List of realm role names to add"`
+	UserID string   `json:"user_id"         jsonschema:"User ID, username or email"`
+	Roles  []string `json:"roles"           jsonschema:"List of realm role names or IDs to add"`
 }
 
 type removeUserRealmRolesArgs struct {
 	Realm  string   `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID string   `json:"user_id"         jsonschema:"User ID"`
-	Roles  []string `json:"roles"           jsonschema:"List of realm role names to remove"`
+	UserID string   `json:"user_id"         jsonschema:"User ID, username or email"`
+	Roles  []string `json:"roles"           jsonschema:"List of realm role names or IDs to remove"`
 }
 
 type getUserClientRolesArgs struct {
 	Realm    string `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID   string `json:"user_id"         jsonschema:"User ID"`
-	ClientID string `json:"client_id"       jsonschema:"Internal client UUID"`
+	UserID   string `json:"user_id"         jsonschema:"User ID, username or email"`
+	ClientID string `json:"client_id"       jsonschema:"Client UUID or clientId"`
+	viewArgs
 }
 
 type addUserClientRolesArgs struct {
 	Realm    string   `json:"realm,omitempty" jsonschema:"Realm name (uses default if omitted)"`
-	UserID   string   `json:"user_id"         jsonschema:"User ID"`
-	ClientID string   `json:"client_id"       jsonschema:"Internal client UUID"`
-	Roles    []string `json:"roles"           jsonschema:"List of client role names to add"`
+	UserID   string   `json:"user_id"         jsonschema:"User ID, username or email"`
+	ClientID string   `json:"client_id"       jsonschema:"Client UUID or clientId"`
+	Roles    []string `json:"roles"           jsonschema:"List of client role names or IDs to add"`
 }
 
 // ---------------------------------------------------------------------------
@@ -175,13 +193,15 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 1. list_users
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "list_users",
-		Description: "List users in a realm",
+		Name:         "list_users",
+		Description:  "List users in a realm",
+		OutputSchema: listSchema[*gocloak.User](),
+		Annotations:  readOnly("List users"),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args listUsersArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
 
@@ -193,29 +213,39 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 				params.Search = gocloak.StringP(args.Search)
 			}
 
+			if args.paginated() {
+				return listUsersPaged(ctx, kc, token, realm, "list_users", args.pageArgs, params, args.Max)
+			}
+
 			users, err := kc.GC.GetUsers(ctx, token, realm, params)
 			if err != nil {
-				return toolError(fmt.Sprintf("failed to list users: %v", err))
+				return kcError("failed to list users", err)
 			}
-			return toolResult(users)
+			return listResult(users)
 		},
 	)
 
 	// 2. get_user
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_user",
-		Description: "Get a user by ID",
+		Name:         "get_user",
+		Description:  "Get a user by ID, username or email",
+		OutputSchema: outputSchema[gocloak.User](),
+		Annotations:  readOnly("Get user"),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args getUserArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
-			user, err := kc.GC.GetUserByID(ctx, token, realm, args.UserID)
+			user, err := kc.GC.GetUserByID(ctx, token, realm, userID)
 			if err != nil {
-				return toolError(fmt.Sprintf("failed to get user: %v", err))
+				return kcError("failed to get user", err)
 			}
 			return toolResult(user)
 		},
@@ -223,13 +253,15 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 3. search_users
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "search_users",
-		Description: "Search users with detailed parameters",
+		Name:         "search_users",
+		Description:  "Search users with detailed parameters",
+		OutputSchema: listSchema[*gocloak.User](),
+		Annotations:  readOnly("Search users"),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args searchUsersArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
 
@@ -251,25 +283,34 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 				params.LastName = gocloak.StringP(args.LastName)
 			}
 
+			if args.paginated() {
+				return listUsersPaged(ctx, kc, token, realm, "search_users", args.pageArgs, params, args.Max)
+			}
+
 			users, err := kc.GC.GetUsers(ctx, token, realm, params)
 			if err != nil {
-				return toolError(fmt.Sprintf("failed to search users: %v", err))
+				return kcError("failed to search users", err)
 			}
-			return toolResult(users)
+			return listResult(users)
 		},
 	)
 
 	// 4. create_user
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_user",
-		Description: "Create a new user in a realm",
+		Name:         "create_user",
+		Description:  "Create a new user in a realm",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Create user", false),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args createUserArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			if err := checkRequiredActions(ctx, kc, token, realm, args.RequiredActions); err != nil {
+				return kcError("failed to create user", err)
+			}
 
 			enabled := true
 			if args.Enabled != nil {
@@ -289,10 +330,14 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 			if args.LastName != "" {
 				user.LastName = gocloak.StringP(args.LastName)
 			}
+			user.EmailVerified = args.EmailVerified
+			if len(args.RequiredActions) > 0 {
+				user.RequiredActions = &args.RequiredActions
+			}
 
 			userID, err := kc.GC.CreateUser(ctx, token, realm, user)
 			if err != nil {
-				return toolError(fmt.Sprintf("failed to create user: %v", err))
+				return kcError("failed to create user", err)
 			}
 
 			// Optionally set password
@@ -302,29 +347,35 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 					temporary = *args.TemporaryPassword
 				}
 				if err := kc.GC.SetPassword(ctx, token, userID, realm, args.Password, temporary); err != nil {
-					return toolError(fmt.Sprintf("user created (ID: %s) but failed to set password: %v", userID, err))
+					return kcError(fmt.Sprintf("user created (ID: %s) but failed to set password", userID), err)
 				}
 			}
 
-			return toolSuccess(fmt.Sprintf("User created with ID: %s", userID))
+			return toolCreated(userID, fmt.Sprintf("User created with ID: %s", userID))
 		},
 	)
 
 	// 5. update_user
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "update_user",
-		Description: "Update an existing user",
+		Name:         "update_user",
+		Description:  "Update an existing user",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Update user", true),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args updateUserArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
-			user, err := kc.GC.GetUserByID(ctx, token, realm, args.UserID)
+			user, err := kc.GC.GetUserByID(ctx, token, realm, userID)
 			if err != nil {
-				return toolError(fmt.Sprintf("failed to get user: %v", err))
+				return kcError("failed to get user", err)
 			}
 
 			if args.Email != nil {
@@ -339,9 +390,49 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 			if args.Enabled != nil {
 				user.Enabled = args.Enabled
 			}
+			if args.EmailVerified != nil {
+				user.EmailVerified = args.EmailVerified
+			}
+
+			// Only the actions the user does not hold yet are checked, so that
+			// a user holding an action since disabled can still be updated.
+			var current, actions []string
+			if user.RequiredActions != nil {
+				current = *user.RequiredActions
+			}
+			actions = current
+			if args.RequiredActions != nil {
+				actions = args.RequiredActions
+			}
+			if args.TOTP != nil {
+				actions = slices.DeleteFunc(slices.Clone(actions), func(a string) bool { return a == configureTOTP })
+				if *args.TOTP {
+					actions = append(actions, configureTOTP)
+				}
+			}
+			var added []string
+			for _, a := range actions {
+				if !slices.Contains(current, a) {
+					added = append(added, a)
+				}
+			}
+			if err := checkRequiredActions(ctx, kc, token, realm, added); err != nil {
+				return kcError("failed to update user", err)
+			}
+			if args.RequiredActions != nil || args.TOTP != nil {
+				user.RequiredActions = &actions
+			}
 
 			if err := kc.GC.UpdateUser(ctx, token, realm, *user); err != nil {
-				return toolError(fmt.Sprintf("failed to update user: %v", err))
+				return kcError("failed to update user", err)
+			}
+
+			if args.TOTP != nil && !*args.TOTP {
+				removed, err := removeOTPCredentials(ctx, kc, token, realm, userID)
+				if err != nil {
+					return kcError("user updated but failed to remove OTP credentials", err)
+				}
+				return toolSuccess(fmt.Sprintf("User updated successfully; removed %d OTP credential(s)", removed))
 			}
 			return toolSuccess("User updated successfully")
 		},
@@ -349,18 +440,24 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 6. delete_user
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_user",
-		Description: "Delete a user from a realm",
+		Name:         "delete_user",
+		Description:  "Delete a user from a realm",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete user", true),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args deleteUserArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
-			if err := kc.GC.DeleteUser(ctx, token, realm, args.UserID); err != nil {
-				return toolError(fmt.Sprintf("failed to delete user: %v", err))
+			if err := kc.GC.DeleteUser(ctx, token, realm, userID); err != nil {
+				return kcError("failed to delete user", err)
 			}
 			return toolSuccess("User deleted successfully")
 		},
@@ -368,13 +465,15 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 7. count_users
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "count_users",
-		Description: "Count users in a realm",
+		Name:         "count_users",
+		Description:  "Count users in a realm",
+		OutputSchema: outputSchema[map[string]int](),
+		Annotations:  readOnly("Count users"),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args countUsersArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
 
@@ -385,7 +484,7 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 
 			count, err := kc.GC.GetUserCount(ctx, token, realm, params)
 			if err != nil {
-				return toolError(fmt.Sprintf("failed to count users: %v", err))
+				return kcError("failed to count users", err)
 			}
 			return toolResult(map[string]int{"count": count})
 		},
@@ -393,23 +492,29 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 8. set_user_password
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "set_user_password",
-		Description: "Set a user's password",
+		Name:         "set_user_password",
+		Description:  "Set a user's password",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Set user password", true),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args setUserPasswordArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
 			temporary := false
 			if args.Temporary != nil {
 				temporary = *args.Temporary
 			}
 
-			if err := kc.GC.SetPassword(ctx, token, args.UserID, realm, args.Password, temporary); err != nil {
-				return toolError(fmt.Sprintf("failed to set password: %v", err))
+			if err := kc.GC.SetPassword(ctx, token, userID, realm, args.Password, temporary); err != nil {
+				return kcError("failed to set password", err)
 			}
 			return toolSuccess("Password set successfully")
 		},
@@ -417,38 +522,50 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 9. get_user_credentials
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_user_credentials",
-		Description: "Get credentials for a user",
+		Name:         "get_user_credentials",
+		Description:  "Get credentials for a user",
+		OutputSchema: listSchema[*gocloak.CredentialRepresentation](),
+		Annotations:  readOnly("Get user credentials"),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args getUserCredentialsArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
-			creds, err := kc.GC.GetCredentials(ctx, token, realm, args.UserID)
+			creds, err := kc.GC.GetCredentials(ctx, token, realm, userID)
 			if err != nil {
-				return toolError(fmt.Sprintf("failed to get credentials: %v", err))
+				return kcError("failed to get credentials", err)
 			}
-			return toolResult(creds)
+			return listResult(creds)
 		},
 	)
 
 	// 10. delete_user_credential
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_user_credential",
-		Description: "Delete a specific credential for a user",
+		Name:         "delete_user_credential",
+		Description:  "Delete a specific credential for a user",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Delete user credential", true),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args deleteUserCredentialArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
-			if err := kc.GC.DeleteCredentials(ctx, token, realm, args.UserID, args.CredentialID); err != nil {
-				return toolError(fmt.Sprintf("failed to delete credential: %v", err))
+			if err := kc.GC.DeleteCredentials(ctx, token, realm, userID, args.CredentialID); err != nil {
+				return kcError("failed to delete credential", err)
 			}
 			return toolSuccess("Credential deleted successfully")
 		},
@@ -456,19 +573,25 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 11. send_verify_email
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "send_verify_email",
-		Description: "Send a verification email to a user",
+		Name:         "send_verify_email",
+		Description:  "Send a verification email to a user",
+		OutputSchema: messageSchema,
+		Annotations:  emails("Send verification email"),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args sendVerifyEmailArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
 			params := gocloak.SendVerificationMailParams{}
-			if err := kc.GC.SendVerifyEmail(ctx, token, args.UserID, realm, params); err != nil {
-				return toolError(fmt.Sprintf("failed to send verify email: %v", err))
+			if err := kc.GC.SendVerifyEmail(ctx, token, userID, realm, params); err != nil {
+				return kcError("failed to send verify email", err)
 			}
 			return toolSuccess("Verification email sent")
 		},
@@ -476,18 +599,28 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 12. execute_actions_email
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "execute_actions_email",
-		Description: "Send an actions email to a user",
+		Name:         "execute_actions_email",
+		Description:  "Send an actions email to a user",
+		OutputSchema: messageSchema,
+		Annotations:  emails("Email required actions to user"),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args executeActionsEmailArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
+
+			if err := checkRequiredActions(ctx, kc, token, realm, args.Actions); err != nil {
+				return kcError("failed to send actions email", err)
+			}
 
 			params := gocloak.ExecuteActionsEmail{
-				UserID:  gocloak.StringP(args.UserID),
+				UserID:  gocloak.StringP(userID),
 				Actions: &args.Actions,
 			}
 			if args.Lifespan != nil {
@@ -495,7 +628,7 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 			}
 
 			if err := kc.GC.ExecuteActionsEmail(ctx, token, realm, params); err != nil {
-				return toolError(fmt.Sprintf("failed to send actions email: %v", err))
+				return kcError("failed to send actions email", err)
 			}
 			return toolSuccess("Actions email sent")
 		},
@@ -503,38 +636,54 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 13. get_user_groups
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_user_groups",
-		Description: "Get groups for a user",
+		Name:         "get_user_groups",
+		Description:  "Get groups for a user",
+		OutputSchema: listSchema[*gocloak.Group](),
+		Annotations:  readOnly("Get user groups"),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args getUserGroupsArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
-			groups, err := kc.GC.GetUserGroups(ctx, token, realm, args.UserID, gocloak.GetGroupsParams{})
+			groups, err := kc.GC.GetUserGroups(ctx, token, realm, userID, gocloak.GetGroupsParams{})
 			if err != nil {
-				return toolError(fmt.Sprintf("failed to get user groups: %v", err))
+				return kcError("failed to get user groups", err)
 			}
-			return toolResult(groups)
+			return listResult(groups)
 		},
 	)
 
 	// 14. add_user_to_group
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "add_user_to_group",
-		Description: "Add a user to a group",
+		Name:         "add_user_to_group",
+		Description:  "Add a user to a group",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Add user to group", true),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args addUserToGroupArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			groupID, err := kc.ResolveGroupID(ctx, token, realm, args.GroupID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve group %q", args.GroupID), err)
+			}
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
-			if err := kc.GC.AddUserToGroup(ctx, token, realm, args.UserID, args.GroupID); err != nil {
-				return toolError(fmt.Sprintf("failed to add user to group: %v", err))
+			if err := kc.GC.AddUserToGroup(ctx, token, realm, userID, groupID); err != nil {
+				return kcError("failed to add user to group", err)
 			}
 			return toolSuccess("User added to group")
 		},
@@ -542,18 +691,28 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 15. remove_user_from_group
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "remove_user_from_group",
-		Description: "Remove a user from a group",
+		Name:         "remove_user_from_group",
+		Description:  "Remove a user from a group",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Remove user from group", true),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args removeUserFromGroupArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			groupID, err := kc.ResolveGroupID(ctx, token, realm, args.GroupID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve group %q", args.GroupID), err)
+			}
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
-			if err := kc.GC.DeleteUserFromGroup(ctx, token, realm, args.UserID, args.GroupID); err != nil {
-				return toolError(fmt.Sprintf("failed to remove user from group: %v", err))
+			if err := kc.GC.DeleteUserFromGroup(ctx, token, realm, userID, groupID); err != nil {
+				return kcError("failed to remove user from group", err)
 			}
 			return toolSuccess("User removed from group")
 		},
@@ -561,63 +720,81 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 16. get_user_sessions
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_user_sessions",
-		Description: "Get active sessions for a user",
+		Name:         "get_user_sessions",
+		Description:  "Get active sessions for a user",
+		OutputSchema: listSchema[*gocloak.UserSessionRepresentation](),
+		Annotations:  readOnly("Get user sessions"),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args getUserSessionsArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
-			sessions, err := kc.GC.GetUserSessions(ctx, token, realm, args.UserID)
+			sessions, err := kc.GC.GetUserSessions(ctx, token, realm, userID)
 			if err != nil {
-				return toolError(fmt.Sprintf("failed to get user sessions: %v", err))
+				return kcError("failed to get user sessions", err)
 			}
-			return toolResult(sessions)
+			return listResult(sessions)
 		},
 	)
 
 	// 17. get_user_federated_identities
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_user_federated_identities",
-		Description: "Get federated identities for a user",
+		Name:         "get_user_federated_identities",
+		Description:  "Get federated identities for a user",
+		OutputSchema: listSchema[*gocloak.FederatedIdentityRepresentation](),
+		Annotations:  readOnly("Get user federated identities"),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args getUserFederatedIdentitiesArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
-			identities, err := kc.GC.GetUserFederatedIdentities(ctx, token, realm, args.UserID)
+			identities, err := kc.GC.GetUserFederatedIdentities(ctx, token, realm, userID)
 			if err != nil {
-				return toolError(fmt.Sprintf("failed to get federated identities: %v", err))
+				return kcError("failed to get federated identities", err)
 			}
-			return toolResult(identities)
+			return listResult(identities)
 		},
 	)
 
 	// 18. create_user_federated_identity
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_user_federated_identity",
-		Description: "Create a federated identity link for a user",
+		Name:         "create_user_federated_identity",
+		Description:  "Create a federated identity link for a user",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Link federated identity to user", false),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args createUserFederatedIdentityArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
 			fedIdentity := gocloak.FederatedIdentityRepresentation{
 				UserID:   gocloak.StringP(args.FederatedUserID),
 				UserName: gocloak.StringP(args.FederatedUsername),
 			}
 
-			if err := kc.GC.CreateUserFederatedIdentity(ctx, token, realm, args.UserID, args.ProviderID, fedIdentity); err != nil {
-				return toolError(fmt.Sprintf("failed to create federated identity: %v", err))
+			if err := kc.GC.CreateUserFederatedIdentity(ctx, token, realm, userID, args.ProviderID, fedIdentity); err != nil {
+				return kcError("failed to create federated identity", err)
 			}
 			return toolSuccess("Federated identity created")
 		},
@@ -625,18 +802,24 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 19. delete_user_federated_identity
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_user_federated_identity",
-		Description: "Delete a federated identity link for a user",
+		Name:         "delete_user_federated_identity",
+		Description:  "Delete a federated identity link for a user",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Unlink federated identity from user", true),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args deleteUserFederatedIdentityArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
-			if err := kc.GC.DeleteUserFederatedIdentity(ctx, token, realm, args.UserID, args.ProviderID); err != nil {
-				return toolError(fmt.Sprintf("failed to delete federated identity: %v", err))
+			if err := kc.GC.DeleteUserFederatedIdentity(ctx, token, realm, userID, args.ProviderID); err != nil {
+				return kcError("failed to delete federated identity", err)
 			}
 			return toolSuccess("Federated identity deleted")
 		},
@@ -644,47 +827,59 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 20. get_user_realm_roles
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_user_realm_roles",
-		Description: "Get realm-level roles assigned to a user",
+		Name:         "get_user_realm_roles",
+		Description:  "Get realm-level roles assigned to a user",
+		OutputSchema: listSchema[*gocloak.Role](),
+		Annotations:  readOnly("Get user realm roles"),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args getUserRealmRolesArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
-			roles, err := kc.GC.GetRealmRolesByUserID(ctx, token, realm, args.UserID)
+			roles, err := kc.GC.GetRealmRolesByUserID(ctx, token, realm, userID)
 			if err != nil {
-				return toolError(fmt.Sprintf("failed to get user realm roles: %v", err))
+				return kcError("failed to get user realm roles", err)
 			}
-			return toolResult(roles)
+			return listResult(roles)
 		},
 	)
 
 	// 21. add_user_realm_roles
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "add_user_realm_roles",
-		Description: "Add realm-level roles to a user",
+		Name:         "add_user_realm_roles",
+		Description:  "Add realm-level roles to a user",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Add user realm roles", true),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args addUserRealmRolesArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
 			var roles []gocloak.Role
 			for _, roleName := range args.Roles {
-				role, err := kc.GC.GetRealmRole(ctx, token, realm, roleName)
+				role, err := kc.ResolveRealmRole(ctx, token, realm, roleName)
 				if err != nil {
-					return toolError(fmt.Sprintf("failed to get realm role %q: %v", roleName, err))
+					return kcError(fmt.Sprintf("failed to get realm role %q", roleName), err)
 				}
 				roles = append(roles, *role)
 			}
 
-			if err := kc.GC.AddRealmRoleToUser(ctx, token, realm, args.UserID, roles); err != nil {
-				return toolError(fmt.Sprintf("failed to add realm roles to user: %v", err))
+			if err := kc.GC.AddRealmRoleToUser(ctx, token, realm, userID, roles); err != nil {
+				return kcError("failed to add realm roles to user", err)
 			}
 			return toolSuccess("Realm roles added to user")
 		},
@@ -692,27 +887,33 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 22. remove_user_realm_roles
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "remove_user_realm_roles",
-		Description: "Remove realm-level roles from a user",
+		Name:         "remove_user_realm_roles",
+		Description:  "Remove realm-level roles from a user",
+		OutputSchema: messageSchema,
+		Annotations:  destructive("Remove user realm roles", true),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args removeUserRealmRolesArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
 			var roles []gocloak.Role
 			for _, roleName := range args.Roles {
-				role, err := kc.GC.GetRealmRole(ctx, token, realm, roleName)
+				role, err := kc.ResolveRealmRole(ctx, token, realm, roleName)
 				if err != nil {
-					return toolError(fmt.Sprintf("failed to get realm role %q: %v", roleName, err))
+					return kcError(fmt.Sprintf("failed to get realm role %q", roleName), err)
 				}
 				roles = append(roles, *role)
 			}
 
-			if err := kc.GC.DeleteRealmRoleFromUser(ctx, token, realm, args.UserID, roles); err != nil {
-				return toolError(fmt.Sprintf("failed to remove realm roles from user: %v", err))
+			if err := kc.GC.DeleteRealmRoleFromUser(ctx, token, realm, userID, roles); err != nil {
+				return kcError("failed to remove realm roles from user", err)
 			}
 			return toolSuccess("Realm roles removed from user")
 		},
@@ -720,49 +921,139 @@ func registerUserTools(s *mcp.Server, kc *keycloak.Client) {
 
 	// 23. get_user_client_roles
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "get_user_client_roles",
-		Description: "Get client-level roles assigned to a user",
+		Name:         "get_user_client_roles",
+		Description:  "Get client-level roles assigned to a user",
+		OutputSchema: listSchema[*gocloak.Role](),
+		Annotations:  readOnly("Get user client roles"),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args getUserClientRolesArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+			}
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
-			roles, err := kc.GC.GetClientRolesByUserID(ctx, token, realm, args.ClientID, args.UserID)
+			roles, err := kc.GC.GetClientRolesByUserID(ctx, token, realm, idOfClient, userID)
 			if err != nil {
-				return toolError(fmt.Sprintf("failed to get user client roles: %v", err))
+				return kcError("failed to get user client roles", err)
 			}
-			return toolResult(roles)
+			return listResult(roles)
 		},
 	)
 
 	// 24. add_user_client_roles
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "add_user_client_roles",
-		Description: "Add client-level roles to a user",
+		Name:         "add_user_client_roles",
+		Description:  "Add client-level roles to a user",
+		OutputSchema: messageSchema,
+		Annotations:  additive("Add user client roles", true),
 	},
 		func(ctx context.Context, req *mcp.CallToolRequest, args addUserClientRolesArgs) (*mcp.CallToolResult, any, error) {
 			token, err := kc.Token(ctx)
 			if err != nil {
-				return toolError(fmt.Sprintf("token error: %v", err))
+				return tokenError(err)
 			}
 			realm := kc.ResolveRealm(args.Realm)
+			idOfClient, err := kc.ResolveClientID(ctx, token, realm, args.ClientID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve client %q", args.ClientID), err)
+			}
+			userID, err := kc.ResolveUserID(ctx, token, realm, args.UserID)
+			if err != nil {
+				return kcError(fmt.Sprintf("failed to resolve user %q", args.UserID), err)
+			}
 
 			var roles []gocloak.Role
 			for _, roleName := range args.Roles {
-				role, err := kc.GC.GetClientRole(ctx, token, realm, args.ClientID, roleName)
+				role, err := kc.ResolveClientRole(ctx, token, realm, idOfClient, roleName)
 				if err != nil {
-					return toolError(fmt.Sprintf("failed to get client role %q: %v", roleName, err))
+					return kcError(fmt.Sprintf("failed to get client role %q", roleName), err)
 				}
 				roles = append(roles, *role)
 			}
 
-			if err := kc.GC.AddClientRoleToUser(ctx, token, realm, args.ClientID, args.UserID, roles); err != nil {
-				return toolError(fmt.Sprintf("failed to add client roles to user: %v", err))
+			if err := kc.GC.AddClientRoleToUser(ctx, token, realm, idOfClient, userID, roles); err != nil {
+				return kcError("failed to add client roles to user", err)
 			}
 			return toolSuccess("Client roles added to user")
 		},
 	)
 }
+
+// listUsersPaged follows user pages for list_users and search_users and
+// reports the total number of matching users.
+func listUsersPaged(ctx context.Context, kc *keycloak.Client, token, realm, tool string, page pageArgs, params gocloak.GetUsersParams, max *int) (*mcp.CallToolResult, any, error) {
+	filters := params
+	filters.First, filters.Max = nil, nil
+	res, err := paginate(ctx, newPageQuery(tool, realm, filters), page, params.First, resultLimit(max, kc.MaxResults()), func(first, max int) ([]*gocloak.User, error) {
+		p := params
+		p.First, p.Max = &first, &max
+		return kc.GC.GetUsers(ctx, token, realm, p)
+	})
+	if err != nil {
+		return pageError("failed to list users", err)
+	}
+	if total, err := kc.GC.GetUserCount(ctx, token, realm, filters); err == nil {
+		res.Total = &total
+	}
+	return toolResult(res)
+}
+
+// configureTOTP is the required action that makes a user set up an OTP app.
+const configureTOTP = "CONFIGURE_TOTP"
+
+// checkRequiredActions returns a validationErr unless every action is an
+// enabled required action of the realm, as listed by list_required_actions.
+func checkRequiredActions(ctx context.Context, kc *keycloak.Client, token, realm string, actions []string) error {
+	if len(actions) == 0 {
+		return nil
+	}
+	providers, err := kc.GC.GetRequiredActions(ctx, token, realm)
+	if err != nil {
+		return fmt.Errorf("failed to list required actions: %w", err)
+	}
+	enabled := map[string]bool{}
+	for _, p := range providers {
+		if gocloak.PBool(p.Enabled) {
+			enabled[gocloak.PString(p.Alias)] = true
+		}
+	}
+	var unknown []string
+	for _, a := range actions {
+		if !enabled[a] {
+			unknown = append(unknown, a)
+		}
+	}
+	if len(unknown) > 0 {
+		return validationErr(fmt.Sprintf("required actions not enabled in realm %s: %s; see list_required_actions", realm, strings.Join(unknown, ", ")))
+	}
+	return nil
+}
+
+// removeOTPCredentials deletes the user's OTP credentials and returns how many
+// there were.
+func removeOTPCredentials(ctx context.Context, kc *keycloak.Client, token, realm, userID string) (int, error) {
+	creds, err := kc.GC.GetCredentials(ctx, token, realm, userID)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, c := range creds {
+		if gocloak.PString(c.Type) != "otp" {
+			continue
+		}
+		if err := kc.GC.DeleteCredentials(ctx, token, realm, userID, gocloak.PString(c.ID)); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
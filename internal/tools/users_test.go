@@ -0,0 +1,236 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/config"
+)
+
+func TestUserLifecycle(t *testing.T) {
+	h := newHarness(t)
+
+	id := idFrom(t, h.ok("create_user", map[string]any{
+		"username": "alice", "email": "alice@example.com", "first_name": "Alice", "password": "s3cret",
+	}))
+	h.ok("create_user", map[string]any{"username": "bob", "email": "bob@example.com"})
+	h.fail("create_user", map[string]any{"username": "alice"}, codeConflict)
+
+	// Users can be addressed by ID, username or email.
+	for _, ref := range []string{id, "alice", "alice@example.com"} {
+		var u map[string]any
+		h.okJSON("get_user", map[string]any{"user_id": ref}, &u)
+		if u["id"] != id {
+			t.Fatalf("get_user(%q) returned %v", ref, u["id"])
+		}
+	}
+	h.fail("get_user", map[string]any{"user_id": "nobody"}, codeNotFound)
+
+	var users []map[string]any
+	h.okJSON("list_users", nil, &users)
+	assertNames(t, "users", users, "username", "alice", "bob")
+	h.okJSON("list_users", map[string]any{"search": "bob"}, &users)
+	assertNames(t, "search bob", users, "username", "bob")
+	h.okJSON("search_users", map[string]any{"email": "alice@"}, &users)
+	assertNames(t, "search_users", users, "username", "alice")
+
+	var count map[string]int
+	h.okJSON("count_users", nil, &count)
+	if count["count"] != 2 {
+		t.Fatalf("count_users = %v", count)
+	}
+
+	h.ok("update_user", map[string]any{"user_id": "alice", "last_name": "Liddell", "enabled": false})
+	var u map[string]any
+	h.okJSON("get_user", map[string]any{"user_id": id}, &u)
+	if u["lastName"] != "Liddell" || u["enabled"] != false || u["firstName"] != "Alice" {
+		t.Fatalf("update_user not applied: %v", u)
+	}
+
+	var creds []map[string]any
+	h.okJSON("get_user_credentials", map[string]any{"user_id": id}, &creds)
+	if len(creds) != 1 || creds[0]["type"] != "password" {
+		t.Fatalf("credentials after create with password = %v", creds)
+	}
+	h.ok("set_user_password", map[string]any{"user_id": id, "password": "n3w", "temporary": true})
+	h.okJSON("get_user_credentials", map[string]any{"user_id": id}, &creds)
+	h.ok("delete_user_credential", map[string]any{"user_id": id, "credential_id": creds[0]["id"]})
+	h.okJSON("get_user_credentials", map[string]any{"user_id": id}, &creds)
+	if len(creds) != 0 {
+		t.Fatalf("credential not deleted: %v", creds)
+	}
+	h.fail("delete_user_credential", map[string]any{"user_id": id, "credential_id": "missing"}, codeNotFound)
+
+	h.ok("send_verify_email", map[string]any{"user_id": id})
+	h.ok("execute_actions_email", map[string]any{"user_id": id, "actions": []string{"UPDATE_PASSWORD"}})
+
+	var sessions, identities []map[string]any
+	h.okJSON("get_user_sessions", map[string]any{"user_id": id}, &sessions)
+	h.ok("create_user_federated_identity", map[string]any{
+		"user_id": id, "provider_id": "github", "federated_user_id": "42", "federated_username": "alice-gh",
+	})
+	h.okJSON("get_user_federated_identities", map[string]any{"user_id": id}, &identities)
+	h.ok("delete_user_federated_identity", map[string]any{"user_id": id, "provider_id": "github"})
+
+	h.ok("delete_user", map[string]any{"user_id": "alice"})
+	h.fail("get_user", map[string]any{"user_id": id}, codeNotFound)
+}
+
+func TestUserGroupsAndRoles(t *testing.T) {
+	h := newHarness(t)
+	userID := h.fake.AddUser("acme", "carol", "carol@example.com")
+	eng := h.fake.AddGroup("acme", "", "eng")
+	h.fake.AddGroup("acme", eng, "platform")
+	h.fake.AddRealmRole("acme", "auditor")
+	clientID := h.fake.AddClient("acme", "billing")
+
+	h.ok("add_user_to_group", map[string]any{"user_id": "carol", "group_id": "/eng/platform"})
+	var groups []map[string]any
+	h.okJSON("get_user_groups", map[string]any{"user_id": userID}, &groups)
+	assertNames(t, "user groups", groups, "path", "/eng/platform")
+	h.ok("remove_user_from_group", map[string]any{"user_id": userID, "group_id": "platform"})
+	h.okJSON("get_user_groups", map[string]any{"user_id": userID}, &groups)
+	assertNames(t, "user groups after removal", groups, "path")
+
+	h.ok("add_user_realm_roles", map[string]any{"user_id": "carol", "roles": []string{"auditor"}})
+	var roles []map[string]any
+	h.okJSON("get_user_realm_roles", map[string]any{"user_id": userID}, &roles)
+	assertNames(t, "realm roles", roles, "name", "auditor")
+	h.fail("add_user_realm_roles", map[string]any{"user_id": userID, "roles": []string{"missing"}}, codeNotFound)
+	h.ok("remove_user_realm_roles", map[string]any{"user_id": userID, "roles": []string{"auditor"}})
+	h.okJSON("get_user_realm_roles", map[string]any{"user_id": userID}, &roles)
+	assertNames(t, "realm roles after removal", roles, "name")
+
+	h.ok("create_client_role", map[string]any{"client_id": "billing", "name": "invoice-reader"})
+	h.ok("add_user_client_roles", map[string]any{"user_id": userID, "client_id": "billing", "roles": []string{"invoice-reader"}})
+	h.okJSON("get_user_client_roles", map[string]any{"user_id": userID, "client_id": clientID}, &roles)
+	assertNames(t, "client roles", roles, "name", "invoice-reader")
+}
+
+func TestAmbiguousUserReturnsCandidates(t *testing.T) {
+	h := newHarness(t)
+	// Same address on two accounts: only possible when duplicate emails are allowed.
+	h.fake.AddUser("acme", "dave", "shared@example.com")
+	h.fake.AddUser("acme", "dave2", "shared@example.com")
+
+	e := h.fail("get_user", map[string]any{"user_id": "shared@example.com"}, codeValidation)
+	if len(e.Candidates) != 2 {
+		t.Fatalf("candidates = %v", e.Candidates)
+	}
+}
+
+func TestListUsersPagination(t *testing.T) {
+	h := newHarness(t)
+	for i := 0; i < 250; i++ {
+		h.fake.AddUser("acme", fmt.Sprintf("user%03d", i), "")
+	}
+
+	var single []map[string]any
+	h.okJSON("list_users", map[string]any{"max": 10}, &single)
+	if len(single) != 10 {
+		t.Fatalf("single page = %d users", len(single))
+	}
+
+	var all pagedResult[map[string]any]
+	h.okJSON("list_users", map[string]any{"all": true}, &all)
+	if all.Count != 250 || all.Truncated || all.Total == nil || *all.Total != 250 {
+		t.Fatalf("all: count=%d truncated=%v total=%v", all.Count, all.Truncated, all.Total)
+	}
+
+	var exact pagedResult[map[string]any]
+	h.okJSON("list_users", map[string]any{"all": true, "max": 250}, &exact)
+	if exact.Count != 250 || exact.Truncated || exact.NextCursor != "" {
+		t.Fatalf("exact: count=%d truncated=%v cursor=%q", exact.Count, exact.Truncated, exact.NextCursor)
+	}
+
+	var first, rest pagedResult[map[string]any]
+	h.okJSON("list_users", map[string]any{"all": true, "max": 120}, &first)
+	if first.Count != 120 || !first.Truncated || first.NextCursor == "" {
+		t.Fatalf("capped: count=%d truncated=%v cursor=%q", first.Count, first.Truncated, first.NextCursor)
+	}
+	h.okJSON("list_users", map[string]any{"cursor": first.NextCursor}, &rest)
+	if rest.Count != 130 || rest.Truncated {
+		t.Fatalf("continuation: count=%d truncated=%v", rest.Count, rest.Truncated)
+	}
+	if first.Items[119]["username"] == rest.Items[0]["username"] {
+		t.Fatal("continuation repeated the last item")
+	}
+
+	h.fail("list_users", map[string]any{"cursor": "bogus"}, codeValidation)
+	h.fail("search_users", map[string]any{"cursor": first.NextCursor}, codeValidation)
+	// A cursor only continues the query it came from.
+	e := h.fail("list_users", map[string]any{"cursor": first.NextCursor, "search": "user1"}, codeValidation)
+	if !strings.Contains(e.Message, "different realm or filter") {
+		t.Fatalf("message = %q", e.Message)
+	}
+	h.fail("list_users", map[string]any{"cursor": first.NextCursor, "realm": "master"}, codeValidation)
+}
+
+func TestUserRequiredActions(t *testing.T) {
+	h := newHarness(t)
+	h.ok("create_user", map[string]any{
+		"username": "alice", "email": "alice@example.com", "email_verified": true, "required_actions": []string{"UPDATE_PROFILE"},
+	})
+	h.fail("create_user", map[string]any{"username": "bob", "required_actions": []string{"TERMS_AND_CONDITIONS"}}, codeValidation)
+	h.fail("get_user", map[string]any{"user_id": "bob"}, codeNotFound)
+
+	var u struct {
+		ID              string   `json:"id"`
+		EmailVerified   bool     `json:"emailVerified"`
+		RequiredActions []string `json:"requiredActions"`
+	}
+	get := func() {
+		t.Helper()
+		u.RequiredActions = nil
+		h.okJSON("get_user", map[string]any{"user_id": "alice"}, &u)
+	}
+	get()
+	if !u.EmailVerified || len(u.RequiredActions) != 1 {
+		t.Fatalf("created user = %+v", u)
+	}
+
+	// totp adds CONFIGURE_TOTP without touching the other actions.
+	h.ok("update_user", map[string]any{"user_id": "alice", "totp": true, "email_verified": false})
+	get()
+	if u.EmailVerified || len(u.RequiredActions) != 2 || u.RequiredActions[1] != configureTOTP {
+		t.Fatalf("after totp = %+v", u)
+	}
+
+	h.fail("update_user", map[string]any{"user_id": "alice", "required_actions": []string{"UPDATE_PASSWORD", "BOGUS"}}, codeValidation)
+	h.fail("execute_actions_email", map[string]any{"user_id": "alice", "actions": []string{"BOGUS"}}, codeValidation)
+
+	h.fake.AddCredential("acme", u.ID, "otp")
+	h.ok("set_user_password", map[string]any{"user_id": "alice", "password": "s3cret"})
+	if msg := h.ok("update_user", map[string]any{"user_id": "alice", "totp": false}); !strings.Contains(msg, "removed 1 OTP") {
+		t.Fatalf("totp=false: %s", msg)
+	}
+	var creds []map[string]any
+	h.okJSON("get_user_credentials", map[string]any{"user_id": "alice"}, &creds)
+	assertNames(t, "credentials", creds, "type", "password")
+	get()
+	if len(u.RequiredActions) != 1 || u.RequiredActions[0] != "UPDATE_PROFILE" {
+		t.Fatalf("after totp=false = %v", u.RequiredActions)
+	}
+
+	h.ok("update_user", map[string]any{"user_id": "alice", "required_actions": []string{}})
+	get()
+	if len(u.RequiredActions) != 0 {
+		t.Fatalf("after clearing = %v", u.RequiredActions)
+	}
+}
+
+func TestUpdateUserKeepsDisabledAction(t *testing.T) {
+	h := newHarnessWithConfig(t, func(cfg *config.Config) {
+		cfg.AdminAPIAllowlist = append(cfg.AdminAPIAllowlist, "PUT /users/*")
+	})
+	id := h.fake.AddUser("acme", "alice", "")
+	// TERMS_AND_CONDITIONS is disabled in the fake realm, as if it was turned
+	// off after alice was given it.
+	h.ok("admin_api_request", map[string]any{"method": "PUT", "path": "/users/" + id, "body": map[string]any{
+		"requiredActions": []string{"TERMS_AND_CONDITIONS"},
+	}})
+
+	h.ok("update_user", map[string]any{"user_id": "alice", "required_actions": []string{"TERMS_AND_CONDITIONS", "UPDATE_PASSWORD"}})
+	h.fail("update_user", map[string]any{"user_id": "alice", "required_actions": []string{"TERMS_AND_CONDITIONS", "BOGUS"}}, codeValidation)
+}
@@ -0,0 +1,160 @@
+package userimport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Input formats accepted by Parse.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// attributePrefix marks CSV columns holding a user attribute: the column
+// "attr.department" sets the attribute "department".
+const attributePrefix = "attr."
+
+// listSeparator separates the values of multi-valued CSV cells.
+const listSeparator = ";"
+
+// FormatOf guesses the format of a file from its extension, defaulting to CSV.
+func FormatOf(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+	return FormatCSV
+}
+
+// Parse reads rows in format. JSON input is an array of Row objects. CSV
+// input has a header row naming columns after the Row JSON fields, plus
+// attr.<name> columns for attributes. In CSV, groups, realm_roles,
+// required_actions and attribute cells hold values separated by ";", and
+// client_roles cells hold clientId:role pairs.
+func Parse(r io.Reader, format string) ([]Row, error) {
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		var rows []Row
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&rows); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return rows, nil
+	case FormatCSV, "":
+		return parseCSV(r)
+	default:
+		return nil, fmt.Errorf("unknown format %q: use %s or %s", format, FormatCSV, FormatJSON)
+	}
+}
+
+func parseCSV(r io.Reader) ([]Row, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+	for i, col := range header {
+		col = strings.TrimSpace(col)
+		header[i] = col
+		if !strings.HasPrefix(col, attributePrefix) && !csvColumns[col] {
+			return nil, fmt.Errorf("unknown CSV column %q", col)
+		}
+	}
+
+	var rows []Row
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		var row Row
+		for i, cell := range record {
+			if err := row.setColumn(header[i], strings.TrimSpace(cell)); err != nil {
+				return nil, fmt.Errorf("line %d: %s: %w", line, header[i], err)
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+var csvColumns = map[string]bool{
+	"username": true, "email": true, "first_name": true, "last_name": true, "enabled": true,
+	"email_verified": true, "groups": true, "realm_roles": true, "client_roles": true,
+	"required_actions": true, "temporary_password": true,
+}
+
+func (row *Row) setColumn(col, cell string) error {
+	if cell == "" {
+		return nil
+	}
+	var err error
+	switch col {
+	case "username":
+		row.Username = cell
+	case "email":
+		row.Email = cell
+	case "first_name":
+		row.FirstName = cell
+	case "last_name":
+		row.LastName = cell
+	case "enabled":
+		row.Enabled, err = parseBool(cell)
+	case "email_verified":
+		row.EmailVerified, err = parseBool(cell)
+	case "groups":
+		row.Groups = splitList(cell)
+	case "realm_roles":
+		row.RealmRoles = splitList(cell)
+	case "required_actions":
+		row.RequiredActions = splitList(cell)
+	case "temporary_password":
+		row.TemporaryPassword = cell
+	case "client_roles":
+		row.ClientRoles = map[string][]string{}
+		for _, pair := range splitList(cell) {
+			// clientIds may contain colons (SAML entity IDs); role names rarely do.
+			i := strings.LastIndex(pair, ":")
+			if i <= 0 || i == len(pair)-1 {
+				return fmt.Errorf("%q is not clientId:role", pair)
+			}
+			row.ClientRoles[pair[:i]] = append(row.ClientRoles[pair[:i]], pair[i+1:])
+		}
+	default:
+		if row.Attributes == nil {
+			row.Attributes = map[string][]string{}
+		}
+		row.Attributes[strings.TrimPrefix(col, attributePrefix)] = splitList(cell)
+	}
+	return err
+}
+
+func parseBool(s string) (*bool, error) {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not true or false", s)
+	}
+	return &b, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, listSeparator) {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,45 @@
+package userimport
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	in := "username, enabled, client_roles, attr.team\n" +
+		"bob, false, urn:sso:app:viewer;billing:admin, red;blue\n"
+	rows, err := Parse(strings.NewReader(in), FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Row{
+		Username:    "bob",
+		Enabled:     rows[0].Enabled,
+		ClientRoles: map[string][]string{"urn:sso:app": {"viewer"}, "billing": {"admin"}},
+		Attributes:  map[string][]string{"team": {"red", "blue"}},
+	}
+	if len(rows) != 1 || !reflect.DeepEqual(rows[0], want) || *rows[0].Enabled {
+		t.Fatalf("rows = %+v", rows)
+	}
+
+	for _, bad := range []string{
+		"username,shoe_size\nbob,44\n",
+		"username,enabled\nbob,sometimes\n",
+		"username,client_roles\nbob,billing\n",
+	} {
+		if _, err := Parse(strings.NewReader(bad), FormatCSV); err == nil {
+			t.Errorf("Parse(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	rows, err := Parse(strings.NewReader(`[{"username":"bob","groups":["/eng"]}]`), FormatOf("users.json"))
+	if err != nil || len(rows) != 1 || rows[0].Groups[0] != "/eng" {
+		t.Fatalf("rows = %+v, %v", rows, err)
+	}
+	if _, err := Parse(strings.NewReader(`[{"user":"bob"}]`), FormatJSON); err == nil {
+		t.Error("unknown JSON field accepted")
+	}
+}
@@ -0,0 +1,376 @@
+// Package userimport creates users in bulk from CSV or JSON rows. It backs
+// the import_users tool and the import-users command.
+//
+// An import first validates every row: required fields, duplicates within the
+// input, and that the groups, roles, clients and required actions it names
+// exist. If any row is invalid nothing is created. Valid imports then run
+// with bounded concurrency; users whose username already exists are skipped,
+// so an interrupted import can simply be run again.
+package userimport
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/keycloak"
+	"github.com/mnemoshare/mnemoshare-keycloak-mcp/internal/workers"
+)
+
+const (
+	// DefaultConcurrency is the number of rows imported at once by default.
+	DefaultConcurrency = workers.DefaultConcurrency
+	// MaxConcurrency caps Options.Concurrency.
+	MaxConcurrency = workers.MaxConcurrency
+)
+
+// Row statuses reported in RowResult.Status.
+const (
+	StatusCreated   = "created"   // the user was created with everything the row asks for
+	StatusSkipped   = "skipped"   // a user with the username already exists; it was left unchanged
+	StatusFailed    = "failed"    // Keycloak rejected the user or one of its groups, roles or password
+	StatusInvalid   = "invalid"   // validation failed; nothing was imported
+	StatusValid     = "valid"     // dry run: the row would be imported
+	StatusCancelled = "cancelled" // the import stopped before reaching the row
+)
+
+// Row is one user to import.
+type Row struct {
+	Username          string              `json:"username"                     jsonschema:"Username (required)"`
+	Email             string              `json:"email,omitempty"              jsonschema:"Email address"`
+	FirstName         string              `json:"first_name,omitempty"         jsonschema:"First name"`
+	LastName          string              `json:"last_name,omitempty"          jsonschema:"Last name"`
+	Enabled           *bool               `json:"enabled,omitempty"            jsonschema:"Whether the user is enabled (default true)"`
+	EmailVerified     *bool               `json:"email_verified,omitempty"     jsonschema:"Whether the email address is verified"`
+	Attributes        map[string][]string `json:"attributes,omitempty"         jsonschema:"User attributes"`
+	Groups            []string            `json:"groups,omitempty"             jsonschema:"Group paths, e.g. /eng/platform"`
+	RealmRoles        []string            `json:"realm_roles,omitempty"        jsonschema:"Realm role names"`
+	ClientRoles       map[string][]string `json:"client_roles,omitempty"       jsonschema:"Client role names by clientId"`
+	RequiredActions   []string            `json:"required_actions,omitempty"   jsonschema:"Required action aliases, e.g. VERIFY_EMAIL"`
+	TemporaryPassword string              `json:"temporary_password,omitempty" jsonschema:"Initial password the user must change at first login"`
+}
+
+// Options controls an import.
+type Options struct {
+	Realm       string                // resolved with keycloak.Client.ResolveRealm
+	DryRun      bool                  // validate only
+	Concurrency int                   // rows imported at once; DefaultConcurrency if 0
+	Progress    func(done, total int) // called after each imported row
+}
+
+// Report is the outcome of an import, with one result per input row.
+type Report struct {
+	Realm     string      `json:"realm"`
+	DryRun    bool        `json:"dry_run,omitempty"`
+	Total     int         `json:"total"`
+	Created   int         `json:"created"`
+	Skipped   int         `json:"skipped"`
+	Failed    int         `json:"failed"`
+	Invalid   int         `json:"invalid"`
+	Cancelled int         `json:"cancelled"`
+	Rows      []RowResult `json:"rows"`
+}
+
+// RowResult is the outcome of one row.
+type RowResult struct {
+	Row      int    `json:"row"` // 1-based position in the input, not counting a CSV header
+	Username string `json:"username"`
+	Status   string `json:"status"`
+	UserID   string `json:"user_id,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// OK reports whether every row was created, skipped or, in a dry run, valid.
+func (r *Report) OK() bool {
+	return r.Failed == 0 && r.Invalid == 0 && r.Cancelled == 0
+}
+
+// Import validates rows and, unless opts.DryRun is set or a row is invalid,
+// creates the users. The error is only set when the import could not start;
+// problems with single rows are reported in the Report.
+func Import(ctx context.Context, kc *keycloak.Client, rows []Row, opts Options) (*Report, error) {
+	token, err := kc.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	im := &importer{
+		kc:          kc,
+		token:       token,
+		realm:       kc.ResolveRealm(opts.Realm),
+		groups:      map[string]string{},
+		realmRoles:  map[string]gocloak.Role{},
+		clients:     map[string]string{},
+		clientRoles: map[string]gocloak.Role{},
+	}
+	if err := im.loadRequiredActions(ctx); err != nil {
+		return nil, err
+	}
+
+	report := &Report{Realm: im.realm, DryRun: opts.DryRun, Total: len(rows), Rows: make([]RowResult, len(rows))}
+	for i, row := range rows {
+		report.Rows[i] = RowResult{Row: i + 1, Username: row.Username, Status: StatusValid}
+	}
+	for i, problems := range im.validate(ctx, rows) {
+		if len(problems) > 0 {
+			report.Rows[i].Status = StatusInvalid
+			report.Rows[i].Error = strings.Join(problems, "; ")
+			report.Invalid++
+		}
+	}
+	if report.Invalid > 0 || opts.DryRun {
+		return report, nil
+	}
+
+	var progress func(done int)
+	if opts.Progress != nil {
+		progress = func(done int) { opts.Progress(done, len(rows)) }
+	}
+	skipped := workers.Run(ctx, len(rows), opts.Concurrency, func(i int) {
+		report.Rows[i] = im.importRow(ctx, i, rows[i])
+	}, progress)
+	for _, i := range skipped {
+		report.Rows[i].Status = StatusCancelled
+	}
+
+	for _, r := range report.Rows {
+		switch r.Status {
+		case StatusCreated:
+			report.Created++
+		case StatusSkipped:
+			report.Skipped++
+		case StatusFailed:
+			report.Failed++
+		case StatusCancelled:
+			report.Cancelled++
+		}
+	}
+	if report.Failed > 0 {
+		log.Ctx(ctx).Warn().Str("realm", im.realm).Int("failed", report.Failed).Int("created", report.Created).
+			Msg("user import finished with failed rows")
+	}
+	return report, nil
+}
+
+// importer holds what validation resolved, so that rows naming the same
+// group or role look it up once.
+type importer struct {
+	kc              *keycloak.Client
+	token           string
+	realm           string
+	requiredActions map[string]bool
+	groups          map[string]string       // path -> group ID
+	realmRoles      map[string]gocloak.Role // name -> role
+	clients         map[string]string       // clientId -> client UUID
+	clientRoles     map[string]gocloak.Role // clientId + "/" + name -> role
+}
+
+func (im *importer) loadRequiredActions(ctx context.Context) error {
+	actions, err := im.kc.GC.GetRequiredActions(ctx, im.token, im.realm)
+	if err != nil {
+		return fmt.Errorf("failed to list required actions: %w", err)
+	}
+	im.requiredActions = map[string]bool{}
+	for _, a := range actions {
+		if gocloak.PBool(a.Enabled) {
+			im.requiredActions[gocloak.PString(a.Alias)] = true
+		}
+	}
+	return nil
+}
+
+// validate returns the problems of every row, indexed like rows.
+func (im *importer) validate(ctx context.Context, rows []Row) [][]string {
+	problems := make([][]string, len(rows))
+	usernames := map[string]int{}
+	emails := map[string]int{}
+	for i, row := range rows {
+		p := &problems[i]
+		username := strings.ToLower(strings.TrimSpace(row.Username))
+		switch {
+		case username == "":
+			*p = append(*p, "username is required")
+		case usernames[username] > 0:
+			*p = append(*p, fmt.Sprintf("username %q repeats row %d", row.Username, usernames[username]))
+		default:
+			usernames[username] = i + 1
+		}
+		if email := strings.ToLower(strings.TrimSpace(row.Email)); email != "" {
+			switch {
+			case !strings.Contains(email, "@"):
+				*p = append(*p, fmt.Sprintf("email %q is not an email address", row.Email))
+			case emails[email] > 0:
+				*p = append(*p, fmt.Sprintf("email %q repeats row %d", row.Email, emails[email]))
+			default:
+				emails[email] = i + 1
+			}
+		}
+		for _, a := range row.RequiredActions {
+			if !im.requiredActions[a] {
+				*p = append(*p, fmt.Sprintf("required action %q is not enabled in realm %s", a, im.realm))
+			}
+		}
+		for _, path := range row.Groups {
+			if err := im.resolveGroup(ctx, path); err != nil {
+				*p = append(*p, fmt.Sprintf("group %q: %v", path, err))
+			}
+		}
+		for _, name := range row.RealmRoles {
+			if err := im.resolveRealmRole(ctx, name); err != nil {
+				*p = append(*p, fmt.Sprintf("realm role %q: %v", name, err))
+			}
+		}
+		for clientID, names := range row.ClientRoles {
+			for _, name := range names {
+				if err := im.resolveClientRole(ctx, clientID, name); err != nil {
+					*p = append(*p, fmt.Sprintf("client role %q of %q: %v", name, clientID, err))
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// groupPath returns path with exactly one leading slash.
+func groupPath(path string) string {
+	return "/" + strings.TrimPrefix(strings.TrimSpace(path), "/")
+}
+
+func (im *importer) resolveGroup(ctx context.Context, path string) error {
+	path = groupPath(path)
+	if _, ok := im.groups[path]; ok {
+		return nil
+	}
+	id, err := im.kc.ResolveGroupID(ctx, im.token, im.realm, path)
+	if err != nil {
+		return err
+	}
+	im.groups[path] = id
+	return nil
+}
+
+func (im *importer) resolveRealmRole(ctx context.Context, name string) error {
+	if _, ok := im.realmRoles[name]; ok {
+		return nil
+	}
+	role, err := im.kc.ResolveRealmRole(ctx, im.token, im.realm, name)
+	if err != nil {
+		return err
+	}
+	im.realmRoles[name] = *role
+	return nil
+}
+
+func (im *importer) resolveClientRole(ctx context.Context, clientID, name string) error {
+	key := clientID + "/" + name
+	if _, ok := im.clientRoles[key]; ok {
+		return nil
+	}
+	id, ok := im.clients[clientID]
+	if !ok {
+		var err error
+		if id, err = im.kc.ResolveClientID(ctx, im.token, im.realm, clientID); err != nil {
+			return err
+		}
+		im.clients[clientID] = id
+	}
+	role, err := im.kc.ResolveClientRole(ctx, im.token, im.realm, id, name)
+	if err != nil {
+		return err
+	}
+	im.clientRoles[key] = *role
+	return nil
+}
+
+// importRow creates the user of a validated row, or skips it if the username
+// is taken. A user whose groups, roles or password then fail stays created
+// and is reported as failed with its ID.
+func (im *importer) importRow(ctx context.Context, i int, row Row) RowResult {
+	res := RowResult{Row: i + 1, Username: row.Username}
+	fail := func(format string, args ...any) RowResult {
+		res.Status = StatusFailed
+		if res.UserID == "" && ctx.Err() != nil {
+			res.Status = StatusCancelled
+		}
+		res.Error = fmt.Sprintf(format, args...)
+		return res
+	}
+
+	// Long imports outlive an access token; Token refreshes it when needed.
+	token, err := im.kc.Token(ctx)
+	if err != nil {
+		return fail("failed to obtain admin token: %v", err)
+	}
+	existing, err := im.kc.GC.GetUsers(ctx, token, im.realm, gocloak.GetUsersParams{
+		Username: gocloak.StringP(row.Username),
+		Exact:    gocloak.BoolP(true),
+	})
+	if err != nil {
+		return fail("failed to look up the username: %v", err)
+	}
+	for _, u := range existing {
+		if strings.EqualFold(gocloak.PString(u.Username), row.Username) {
+			res.Status = StatusSkipped
+			res.UserID = gocloak.PString(u.ID)
+			return res
+		}
+	}
+
+	user := gocloak.User{
+		Username:      gocloak.StringP(row.Username),
+		Enabled:       gocloak.BoolP(row.Enabled == nil || *row.Enabled),
+		EmailVerified: row.EmailVerified,
+	}
+	if row.Email != "" {
+		user.Email = gocloak.StringP(row.Email)
+	}
+	if row.FirstName != "" {
+		user.FirstName = gocloak.StringP(row.FirstName)
+	}
+	if row.LastName != "" {
+		user.LastName = gocloak.StringP(row.LastName)
+	}
+	if len(row.Attributes) > 0 {
+		user.Attributes = &row.Attributes
+	}
+	if len(row.RequiredActions) > 0 {
+		user.RequiredActions = &row.RequiredActions
+	}
+	res.UserID, err = im.kc.GC.CreateUser(ctx, token, im.realm, user)
+	if err != nil {
+		return fail("failed to create user: %v", err)
+	}
+
+	if row.TemporaryPassword != "" {
+		if err := im.kc.GC.SetPassword(ctx, token, res.UserID, im.realm, row.TemporaryPassword, true); err != nil {
+			return fail("user created, but failed to set the temporary password: %v", err)
+		}
+	}
+	for _, path := range row.Groups {
+		if err := im.kc.GC.AddUserToGroup(ctx, token, im.realm, res.UserID, im.groups[groupPath(path)]); err != nil {
+			return fail("user created, but failed to add it to group %s: %v", path, err)
+		}
+	}
+	if len(row.RealmRoles) > 0 {
+		roles := make([]gocloak.Role, 0, len(row.RealmRoles))
+		for _, name := range row.RealmRoles {
+			roles = append(roles, im.realmRoles[name])
+		}
+		if err := im.kc.GC.AddRealmRoleToUser(ctx, token, im.realm, res.UserID, roles); err != nil {
+			return fail("user created, but failed to add realm roles: %v", err)
+		}
+	}
+	for clientID, names := range row.ClientRoles {
+		roles := make([]gocloak.Role, 0, len(names))
+		for _, name := range names {
+			roles = append(roles, im.clientRoles[clientID+"/"+name])
+		}
+		if err := im.kc.GC.AddClientRoleToUser(ctx, token, im.realm, im.clients[clientID], res.UserID, roles); err != nil {
+			return fail("user created, but failed to add roles of client %s: %v", clientID, err)
+		}
+	}
+	res.Status = StatusCreated
+	return res
+}
@@ -0,0 +1,62 @@
+// Package workers runs per-item work with bounded concurrency. It backs the
+// operations that touch many users at once, such as user imports and bulk
+// updates.
+package workers
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	// DefaultConcurrency is the number of items worked on at once by default.
+	DefaultConcurrency = 4
+	// MaxConcurrency caps the concurrency passed to Run.
+	MaxConcurrency = 16
+)
+
+// Run calls work(i) for every i in [0, n) with at most concurrency calls
+// running at once, and waits for them to finish. A concurrency of 0 or less
+// means DefaultConcurrency; more than MaxConcurrency is capped. If progress
+// is set it is called after each call with the number finished so far; the
+// calls to progress never overlap.
+//
+// Once ctx ends no further calls start. Run returns the items that were never
+// started, in order.
+func Run(ctx context.Context, n, concurrency int, work func(i int), progress func(done int)) (skipped []int) {
+	switch {
+	case concurrency <= 0:
+		concurrency = DefaultConcurrency
+	case concurrency > MaxConcurrency:
+		concurrency = MaxConcurrency
+	}
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done int
+		sem  = make(chan struct{}, concurrency)
+	)
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			skipped = append(skipped, i)
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer func() { <-sem; wg.Done() }()
+			work(i)
+			if progress != nil {
+				mu.Lock()
+				done++
+				progress(done)
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return skipped
+}
@@ -0,0 +1,54 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunBoundsConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	var progress []int
+	seen := make([]bool, 20)
+
+	skipped := Run(context.Background(), len(seen), 3, func(i int) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		seen[i] = true
+		running.Add(-1)
+	}, func(done int) { progress = append(progress, done) })
+
+	if len(skipped) != 0 || peak.Load() > 3 {
+		t.Fatalf("skipped %v, peak concurrency %d", skipped, peak.Load())
+	}
+	for i, ok := range seen {
+		if !ok {
+			t.Fatalf("item %d never ran", i)
+		}
+	}
+	if len(progress) != len(seen) || progress[len(progress)-1] != len(seen) {
+		t.Fatalf("progress = %v", progress)
+	}
+}
+
+func TestRunStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	skipped := Run(ctx, 10, 1, func(i int) {
+		if i == 3 {
+			cancel()
+		}
+	}, nil)
+	if fmt.Sprint(skipped) != "[4 5 6 7 8 9]" {
+		t.Fatalf("skipped = %v", skipped)
+	}
+}